@@ -0,0 +1,171 @@
+// Package client Go client ของ API สำหรับ script / integration test
+// type และ method อยู่ใน client_gen.go ซึ่ง generate จากเอกสาร OpenAPI ของ server:
+//
+//	go generate ./client
+//
+//	c := client.New("http://localhost:8080", os.Getenv("MTM_TOKEN"))
+//	projects, err := c.ListProjects(ctx)
+//
+// error จาก server คืนเป็น *client.Error ที่มี Code คงที่ (เช่น TASK_NOT_FOUND)
+// header เพิ่มเติมส่งผ่าน RequestOption เช่น กันเขียนทับงานของคนอื่นด้วย version ที่อ่านมา:
+//
+//	_, err := c.UpdateTask(ctx, id, body, client.IfMatch(task.Version))
+//
+// POST / PATCH ที่อาจต้อง retry ให้ส่ง IdempotencyKey เดิมทุกครั้ง server จะทำงานแค่ครั้งเดียว
+package client
+
+//go:generate go run ../cmd/gen-client -o client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Client เรียก API ด้วย bearer token (access token จาก Login หรือ personal access token)
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New สร้าง client (token ว่างได้ สำหรับ endpoint ที่ไม่ต้อง login)
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, HTTPClient: http.DefaultClient}
+}
+
+// RequestOption ปรับ request ก่อนส่ง (ใส่ header เพิ่ม)
+type RequestOption func(*http.Request)
+
+// IfMatch ส่ง If-Match ของ version ที่อ่านมา ถ้ามีคนแก้ไปก่อนจะได้ *Error ที่ Code เป็น VERSION_CONFLICT
+func IfMatch(version int64) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("If-Match", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
+}
+
+// IdempotencyKey ส่ง Idempotency-Key (ใช้ key เดิมเมื่อ retry request เดิม)
+func IdempotencyKey(key string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("Idempotency-Key", key)
+	}
+}
+
+// Error error ที่ server ตอบกลับ (application/problem+json)
+type Error struct {
+	StatusCode int
+	Problem
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("api: %d %s: %s", e.StatusCode, e.Code, e.Detail)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}, opts []RequestOption) error {
+	var r io.Reader
+	contentType := ""
+	// body ที่เป็น pointer nil (body ไม่บังคับ) = ไม่ส่ง body
+	if body != nil && !isNilPointer(body) {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+		contentType = "application/json"
+	}
+	resp, err := c.send(ctx, method, path, query, r, contentType, opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) upload(ctx context.Context, path, filename string, file io.Reader, out interface{}, opts []RequestOption) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, http.MethodPost, path, nil, &buf, mw.FormDataContentType(), opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) download(ctx context.Context, method, path string, query url.Values, opts []RequestOption) ([]byte, error) {
+	resp, err := c.send(ctx, method, path, query, nil, "", opts)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// send ส่ง request แล้วแปลง status ที่ไม่ใช่ 2xx เป็น *Error
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string, opts []RequestOption) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	_ = json.Unmarshal(data, &apiErr.Problem)
+	return nil, apiErr
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
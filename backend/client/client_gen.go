@@ -0,0 +1,1259 @@
+// Code generated by gen-client from the OpenAPI document. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AddDependencyRequest mirrors components.schemas.AddDependencyRequest.
+type AddDependencyRequest struct {
+	BlockerID string `json:"blockerId"`
+}
+
+// AdminPasswordResetResponse mirrors components.schemas.AdminPasswordResetResponse.
+type AdminPasswordResetResponse struct {
+	Message    string `json:"message"`
+	ResetToken string `json:"resetToken"`
+}
+
+// AdminStatsResponse mirrors components.schemas.AdminStatsResponse.
+type AdminStatsResponse struct {
+	Users struct {
+		Total    int64 `json:"total"`
+		Admins   int64 `json:"admins"`
+		Disabled int64 `json:"disabled"`
+	} `json:"users"`
+	Projects struct {
+		Total int64 `json:"total"`
+	} `json:"projects"`
+	Tasks struct {
+		Total     int64 `json:"total"`
+		Open      int64 `json:"open"`
+		Completed int64 `json:"completed"`
+	} `json:"tasks"`
+}
+
+// AdminUpdateRoleRequest mirrors components.schemas.AdminUpdateRoleRequest.
+type AdminUpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// AdminUserListResponse mirrors components.schemas.AdminUserListResponse.
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+}
+
+// AdminUserResponse mirrors components.schemas.AdminUserResponse.
+type AdminUserResponse struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Email             string     `json:"email"`
+	Role              string     `json:"role"`
+	Disabled          bool       `json:"disabled"`
+	MustResetPassword bool       `json:"mustResetPassword"`
+	TwoFactorEnabled  bool       `json:"twoFactorEnabled"`
+	SSO               bool       `json:"sso"`
+	CreatedAt         *time.Time `json:"createdAt,omitempty"`
+}
+
+// AnalyticsColumn mirrors components.schemas.AnalyticsColumn.
+type AnalyticsColumn struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	WipLimit *int64 `json:"wipLimit"`
+}
+
+// Attachment mirrors components.schemas.Attachment.
+type Attachment struct {
+	ID           string    `json:"id"`
+	TaskID       string    `json:"taskId"`
+	ProjectID    string    `json:"projectId"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	UploadedByID string    `json:"uploadedById"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// AttachmentLinkResponse mirrors components.schemas.AttachmentLinkResponse.
+type AttachmentLinkResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// BoardColumn mirrors components.schemas.BoardColumn.
+type BoardColumn struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position int64  `json:"position"`
+	Category string `json:"category"`
+	Version  int64  `json:"version"`
+}
+
+// BoardSummary mirrors components.schemas.BoardSummary.
+type BoardSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BoardTask mirrors components.schemas.BoardTask.
+type BoardTask struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"dueDate"`
+	ColumnID    string     `json:"columnId"`
+	BlockedBy   []string   `json:"blockedBy"`
+	StartedAt   *time.Time `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	Version     int64      `json:"version"`
+}
+
+// BulkTaskOperation mirrors components.schemas.BulkTaskOperation.
+type BulkTaskOperation struct {
+	Op                  string   `json:"op"`
+	TaskID              string   `json:"taskId"`
+	Version             *int64   `json:"version,omitempty"`
+	ToColumnID          string   `json:"toColumnId,omitempty"`
+	EnforceDependencies bool     `json:"enforceDependencies,omitempty"`
+	Title               string   `json:"title,omitempty"`
+	Description         string   `json:"description,omitempty"`
+	Priority            string   `json:"priority,omitempty"`
+	DueDate             string   `json:"dueDate,omitempty"`
+	Add                 []string `json:"add,omitempty"`
+	Remove              []string `json:"remove,omitempty"`
+}
+
+// BulkTaskRequest mirrors components.schemas.BulkTaskRequest.
+type BulkTaskRequest struct {
+	Atomic     bool                `json:"atomic,omitempty"`
+	Operations []BulkTaskOperation `json:"operations"`
+}
+
+// BulkTaskResponse mirrors components.schemas.BulkTaskResponse.
+type BulkTaskResponse struct {
+	Atomic  bool             `json:"atomic"`
+	Applied int64            `json:"applied"`
+	Failed  int64            `json:"failed"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// BulkTaskResult mirrors components.schemas.BulkTaskResult.
+type BulkTaskResult struct {
+	Index     int64    `json:"index"`
+	Op        string   `json:"op"`
+	TaskID    string   `json:"taskId"`
+	Ok        bool     `json:"ok"`
+	Status    int64    `json:"status"`
+	Code      string   `json:"code,omitempty"`
+	Detail    string   `json:"detail,omitempty"`
+	Version   int64    `json:"version,omitempty"`
+	BlockedBy []string `json:"blockedBy,omitempty"`
+}
+
+// ChangePasswordRequest mirrors components.schemas.ChangePasswordRequest.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// Column mirrors components.schemas.Column.
+type Column struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position int64  `json:"position"`
+	WipLimit *int64 `json:"wipLimit,omitempty"`
+	Category string `json:"category"`
+	BoardID  string `json:"boardId"`
+	Version  int64  `json:"version"`
+}
+
+// Comment mirrors components.schemas.Comment.
+type Comment struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"taskId"`
+	AuthorID  string    `json:"authorId"`
+	Body      string    `json:"body"`
+	Mentions  []string  `json:"mentions"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ConfirmTwoFactorRequest mirrors components.schemas.ConfirmTwoFactorRequest.
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code"`
+}
+
+// ConfirmTwoFactorResponse mirrors components.schemas.ConfirmTwoFactorResponse.
+type ConfirmTwoFactorResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// CreateColumnRequest mirrors components.schemas.CreateColumnRequest.
+type CreateColumnRequest struct {
+	BoardID   string `json:"boardId,omitempty"`
+	ProjectID string `json:"projectId,omitempty"`
+	Name      string `json:"name"`
+	Category  string `json:"category,omitempty"`
+}
+
+// CreateCommentRequest mirrors components.schemas.CreateCommentRequest.
+type CreateCommentRequest struct {
+	Body     string   `json:"body"`
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// CreateProjectRequest mirrors components.schemas.CreateProjectRequest.
+type CreateProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`
+}
+
+// CreateTaskRequest mirrors components.schemas.CreateTaskRequest.
+type CreateTaskRequest struct {
+	ColumnID    string `json:"columnId"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	DueDate     string `json:"dueDate,omitempty"`
+}
+
+// CreateTaskResponse mirrors components.schemas.CreateTaskResponse.
+type CreateTaskResponse struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// CreateTokenRequest mirrors components.schemas.CreateTokenRequest.
+type CreateTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// 1-365 (default 30 when omitted or 0); tokens always expire
+	ExpiresInDays int64 `json:"expiresInDays,omitempty"`
+}
+
+// CreateTokenResponse mirrors components.schemas.CreateTokenResponse.
+type CreateTokenResponse struct {
+	Token               string              `json:"token"`
+	PersonalAccessToken PersonalAccessToken `json:"personalAccessToken"`
+}
+
+// CreateWebhookRequest mirrors components.schemas.CreateWebhookRequest.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events"`
+}
+
+// CreateWebhookResponse mirrors components.schemas.CreateWebhookResponse.
+type CreateWebhookResponse struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"`
+}
+
+// CumulativeFlowDay mirrors components.schemas.CumulativeFlowDay.
+type CumulativeFlowDay struct {
+	Date   string           `json:"date"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// DeleteAccountRequest mirrors components.schemas.DeleteAccountRequest.
+type DeleteAccountRequest struct {
+	Transfers map[string]string `json:"transfers,omitempty"`
+}
+
+// DisableTwoFactorRequest mirrors components.schemas.DisableTwoFactorRequest.
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+}
+
+// DurationStats mirrors components.schemas.DurationStats.
+type DurationStats struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50"`
+	P85   float64 `json:"p85"`
+	P95   float64 `json:"p95"`
+}
+
+// EnrollTwoFactorResponse mirrors components.schemas.EnrollTwoFactorResponse.
+type EnrollTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// FieldError mirrors components.schemas.FieldError.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ForgotPasswordRequest mirrors components.schemas.ForgotPasswordRequest.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPasswordResponse mirrors components.schemas.ForgotPasswordResponse.
+type ForgotPasswordResponse struct {
+	Message    string `json:"message"`
+	ResetToken string `json:"resetToken,omitempty"`
+}
+
+// InviteMemberRequest mirrors components.schemas.InviteMemberRequest.
+type InviteMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+}
+
+// JWKSet mirrors components.schemas.JWKSet.
+type JWKSet struct {
+	Keys []map[string]string `json:"keys"`
+}
+
+// LoginRequest mirrors components.schemas.LoginRequest.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse mirrors components.schemas.LoginResponse.
+type LoginResponse struct {
+	AccessToken       string       `json:"accessToken,omitempty"`
+	User              *UserSummary `json:"user,omitempty"`
+	TwoFactorRequired bool         `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string       `json:"challengeToken,omitempty"`
+}
+
+// LoginTwoFactorRequest mirrors components.schemas.LoginTwoFactorRequest.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recoveryCode,omitempty"`
+}
+
+// MarkAllReadResponse mirrors components.schemas.MarkAllReadResponse.
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// MeResponse mirrors components.schemas.MeResponse.
+type MeResponse struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	Role             string `json:"role"`
+	TwoFactorEnabled bool   `json:"twoFactorEnabled"`
+}
+
+// MessageResponse mirrors components.schemas.MessageResponse.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// MoveTaskRequest mirrors components.schemas.MoveTaskRequest.
+type MoveTaskRequest struct {
+	TaskID              string `json:"taskId"`
+	ToColumnID          string `json:"toColumnId"`
+	EnforceDependencies bool   `json:"enforceDependencies,omitempty"`
+}
+
+// Notification mirrors components.schemas.Notification.
+type Notification struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	ProjectID *string   `json:"projectId,omitempty"`
+	TaskID    *string   `json:"taskId,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NotificationChannels mirrors components.schemas.NotificationChannels.
+type NotificationChannels struct {
+	InApp bool `json:"inApp"`
+	Email bool `json:"email"`
+}
+
+// OIDCProvidersResponse mirrors components.schemas.OIDCProvidersResponse.
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// OKResponse mirrors components.schemas.OKResponse.
+type OKResponse struct {
+	Ok bool `json:"ok"`
+}
+
+// PersonalAccessToken mirrors components.schemas.PersonalAccessToken.
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Problem mirrors components.schemas.Problem.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int64        `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Error     string       `json:"error"`
+}
+
+// ProjectAnalyticsResponse mirrors components.schemas.ProjectAnalyticsResponse.
+type ProjectAnalyticsResponse struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Columns        []AnalyticsColumn   `json:"columns"`
+	CumulativeFlow []CumulativeFlowDay `json:"cumulativeFlow"`
+	CycleTime      DurationStats       `json:"cycleTime"`
+	LeadTime       DurationStats       `json:"leadTime"`
+	Throughput     []ThroughputWeek    `json:"throughput"`
+	WipBreaches    []WipBreach         `json:"wipBreaches"`
+}
+
+// ProjectDetailResponse mirrors components.schemas.ProjectDetailResponse.
+type ProjectDetailResponse struct {
+	Project ProjectSummary `json:"project"`
+	Board   *BoardSummary  `json:"board"`
+	Columns []BoardColumn  `json:"columns"`
+	Tasks   []BoardTask    `json:"tasks"`
+}
+
+// ProjectMember mirrors components.schemas.ProjectMember.
+type ProjectMember struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+}
+
+// ProjectResponse mirrors components.schemas.ProjectResponse.
+type ProjectResponse struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	Color         string     `json:"color,omitempty"`
+	TaskCount     int64      `json:"taskCount"`
+	OpenTaskCount int64      `json:"openTaskCount"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+	Version       int64      `json:"version"`
+}
+
+// ProjectSummary mirrors components.schemas.ProjectSummary.
+type ProjectSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     int64  `json:"version"`
+}
+
+// RegisterRequest mirrors components.schemas.RegisterRequest.
+type RegisterRequest struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ResetPasswordRequest mirrors components.schemas.ResetPasswordRequest.
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// TaskDependency mirrors components.schemas.TaskDependency.
+type TaskDependency struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"projectId"`
+	BlockerID   string    `json:"blockerId"`
+	BlockedID   string    `json:"blockedId"`
+	CreatedByID string    `json:"createdById"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ThroughputWeek mirrors components.schemas.ThroughputWeek.
+type ThroughputWeek struct {
+	WeekStart string `json:"weekStart"`
+	Completed int64  `json:"completed"`
+}
+
+// TransferProjectRequest mirrors components.schemas.TransferProjectRequest.
+type TransferProjectRequest struct {
+	NewOwnerID string `json:"newOwnerId"`
+}
+
+// TransferProjectResponse mirrors components.schemas.TransferProjectResponse.
+type TransferProjectResponse struct {
+	Message string `json:"message"`
+	OwnerID string `json:"ownerId"`
+}
+
+// UnreadCountResponse mirrors components.schemas.UnreadCountResponse.
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// UpdateColumnRequest mirrors components.schemas.UpdateColumnRequest.
+type UpdateColumnRequest struct {
+	Name     string `json:"name,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+// UpdateProfileRequest mirrors components.schemas.UpdateProfileRequest.
+type UpdateProfileRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateProjectRequest mirrors components.schemas.UpdateProjectRequest.
+type UpdateProjectRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateTaskRequest mirrors components.schemas.UpdateTaskRequest.
+type UpdateTaskRequest struct {
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	DueDate     string    `json:"dueDate,omitempty"`
+	Assignees   *[]string `json:"assignees,omitempty"`
+}
+
+// UpdateWebhookRequest mirrors components.schemas.UpdateWebhookRequest.
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// UserSummary mirrors components.schemas.UserSummary.
+type UserSummary struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// VersionResponse mirrors components.schemas.VersionResponse.
+type VersionResponse struct {
+	Message string `json:"message"`
+	Version int64  `json:"version"`
+}
+
+// Webhook mirrors components.schemas.Webhook.
+type Webhook struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"projectId"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	CreatedByID string    `json:"createdById"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery mirrors components.schemas.WebhookDelivery.
+type WebhookDelivery struct {
+	ID             string     `json:"id"`
+	WebhookID      string     `json:"webhookId"`
+	ProjectID      string     `json:"projectId"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int64      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastStatusCode int64      `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	RedeliveryOf   *string    `json:"redeliveryOf,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WipBreach mirrors components.schemas.WipBreach.
+type WipBreach struct {
+	ColumnID   string `json:"columnId"`
+	Name       string `json:"name"`
+	WipLimit   int64  `json:"wipLimit"`
+	BreachDays int64  `json:"breachDays"`
+}
+
+// AddTaskDependency POST /api/v1/tasks/{id}/dependencies
+//
+// Mark the task as blocked by another task
+func (c *Client) AddTaskDependency(ctx context.Context, id string, body AddDependencyRequest, opts ...RequestOption) (TaskDependency, error) {
+	var out TaskDependency
+	err := c.do(ctx, "POST", "/api/v1/tasks/"+url.PathEscape(id)+"/dependencies", nil, body, &out, opts)
+	return out, err
+}
+
+// AdminDisableUser POST /api/v1/admin/users/{id}/disable
+//
+// Disable an account and revoke its tokens
+func (c *Client) AdminDisableUser(ctx context.Context, id string, opts ...RequestOption) (AdminUserResponse, error) {
+	var out AdminUserResponse
+	err := c.do(ctx, "POST", "/api/v1/admin/users/"+url.PathEscape(id)+"/disable", nil, nil, &out, opts)
+	return out, err
+}
+
+// AdminEnableUser POST /api/v1/admin/users/{id}/enable
+//
+// Enable an account
+func (c *Client) AdminEnableUser(ctx context.Context, id string, opts ...RequestOption) (AdminUserResponse, error) {
+	var out AdminUserResponse
+	err := c.do(ctx, "POST", "/api/v1/admin/users/"+url.PathEscape(id)+"/enable", nil, nil, &out, opts)
+	return out, err
+}
+
+// AdminForcePasswordReset POST /api/v1/admin/users/{id}/force-password-reset
+//
+// Require a password reset on next login
+func (c *Client) AdminForcePasswordReset(ctx context.Context, id string, opts ...RequestOption) (AdminPasswordResetResponse, error) {
+	var out AdminPasswordResetResponse
+	err := c.do(ctx, "POST", "/api/v1/admin/users/"+url.PathEscape(id)+"/force-password-reset", nil, nil, &out, opts)
+	return out, err
+}
+
+// AdminListUsersParams are the query parameters of AdminListUsers; zero values are not sent.
+type AdminListUsersParams struct {
+	// matches name or email
+	Q        string
+	Role     string
+	Disabled *bool
+	// 1-200 (default 50)
+	Limit int
+	Skip  int
+}
+
+func (p AdminListUsersParams) values() url.Values {
+	v := url.Values{}
+	if p.Q != "" {
+		v.Set("q", p.Q)
+	}
+	if p.Role != "" {
+		v.Set("role", p.Role)
+	}
+	if p.Disabled != nil {
+		v.Set("disabled", strconv.FormatBool(*p.Disabled))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Skip != 0 {
+		v.Set("skip", strconv.Itoa(p.Skip))
+	}
+	return v
+}
+
+// AdminListUsers GET /api/v1/admin/users
+//
+// Search users
+func (c *Client) AdminListUsers(ctx context.Context, params AdminListUsersParams, opts ...RequestOption) (AdminUserListResponse, error) {
+	var out AdminUserListResponse
+	err := c.do(ctx, "GET", "/api/v1/admin/users", params.values(), nil, &out, opts)
+	return out, err
+}
+
+// AdminStats GET /api/v1/admin/stats
+//
+// User, project and task counts
+func (c *Client) AdminStats(ctx context.Context, opts ...RequestOption) (AdminStatsResponse, error) {
+	var out AdminStatsResponse
+	err := c.do(ctx, "GET", "/api/v1/admin/stats", nil, nil, &out, opts)
+	return out, err
+}
+
+// AdminTransferProject POST /api/v1/admin/projects/{id}/transfer
+//
+// Transfer a project to any user
+func (c *Client) AdminTransferProject(ctx context.Context, id string, body TransferProjectRequest, opts ...RequestOption) (TransferProjectResponse, error) {
+	var out TransferProjectResponse
+	err := c.do(ctx, "POST", "/api/v1/admin/projects/"+url.PathEscape(id)+"/transfer", nil, body, &out, opts)
+	return out, err
+}
+
+// AdminUpdateUserRole PATCH /api/v1/admin/users/{id}/role
+//
+// Change a user's role
+func (c *Client) AdminUpdateUserRole(ctx context.Context, id string, body AdminUpdateRoleRequest, opts ...RequestOption) (AdminUserResponse, error) {
+	var out AdminUserResponse
+	err := c.do(ctx, "PATCH", "/api/v1/admin/users/"+url.PathEscape(id)+"/role", nil, body, &out, opts)
+	return out, err
+}
+
+// BulkTasks POST /api/v1/tasks/bulk
+//
+// Move, update, assign, label or delete many tasks (atomic or best-effort)
+func (c *Client) BulkTasks(ctx context.Context, body BulkTaskRequest, opts ...RequestOption) (BulkTaskResponse, error) {
+	var out BulkTaskResponse
+	err := c.do(ctx, "POST", "/api/v1/tasks/bulk", nil, body, &out, opts)
+	return out, err
+}
+
+// ChangePassword POST /api/v1/auth/change-password
+//
+// Change the current user's password
+func (c *Client) ChangePassword(ctx context.Context, body ChangePasswordRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/change-password", nil, body, &out, opts)
+	return out, err
+}
+
+// ConfirmTwoFactor POST /api/v1/auth/2fa/confirm
+//
+// Confirm two-factor enrollment and get recovery codes
+func (c *Client) ConfirmTwoFactor(ctx context.Context, body ConfirmTwoFactorRequest, opts ...RequestOption) (ConfirmTwoFactorResponse, error) {
+	var out ConfirmTwoFactorResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/2fa/confirm", nil, body, &out, opts)
+	return out, err
+}
+
+// CreateColumn POST /api/v1/columns
+//
+// Add a column to a board
+func (c *Client) CreateColumn(ctx context.Context, body CreateColumnRequest, opts ...RequestOption) (Column, error) {
+	var out Column
+	err := c.do(ctx, "POST", "/api/v1/columns", nil, body, &out, opts)
+	return out, err
+}
+
+// CreateComment POST /api/v1/tasks/{id}/comments
+//
+// Comment on a task
+func (c *Client) CreateComment(ctx context.Context, id string, body CreateCommentRequest, opts ...RequestOption) (Comment, error) {
+	var out Comment
+	err := c.do(ctx, "POST", "/api/v1/tasks/"+url.PathEscape(id)+"/comments", nil, body, &out, opts)
+	return out, err
+}
+
+// CreatePersonalAccessToken POST /api/v1/me/tokens
+//
+// Create a token (the token is only returned here)
+func (c *Client) CreatePersonalAccessToken(ctx context.Context, body CreateTokenRequest, opts ...RequestOption) (CreateTokenResponse, error) {
+	var out CreateTokenResponse
+	err := c.do(ctx, "POST", "/api/v1/me/tokens", nil, body, &out, opts)
+	return out, err
+}
+
+// CreateProject POST /api/v1/projects
+//
+// Create a project
+func (c *Client) CreateProject(ctx context.Context, body CreateProjectRequest, opts ...RequestOption) (ProjectResponse, error) {
+	var out ProjectResponse
+	err := c.do(ctx, "POST", "/api/v1/projects", nil, body, &out, opts)
+	return out, err
+}
+
+// CreateTask POST /api/v1/tasks
+//
+// Create a task
+func (c *Client) CreateTask(ctx context.Context, body CreateTaskRequest, opts ...RequestOption) (CreateTaskResponse, error) {
+	var out CreateTaskResponse
+	err := c.do(ctx, "POST", "/api/v1/tasks", nil, body, &out, opts)
+	return out, err
+}
+
+// CreateWebhook POST /api/v1/projects/{id}/webhooks
+//
+// Create a webhook (the secret is only returned here)
+func (c *Client) CreateWebhook(ctx context.Context, id string, body CreateWebhookRequest, opts ...RequestOption) (CreateWebhookResponse, error) {
+	var out CreateWebhookResponse
+	err := c.do(ctx, "POST", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks", nil, body, &out, opts)
+	return out, err
+}
+
+// DeleteAccountParams are the query parameters of DeleteAccount; zero values are not sent.
+type DeleteAccountParams struct {
+	// delete (default) or anonymize
+	Mode string
+}
+
+func (p DeleteAccountParams) values() url.Values {
+	v := url.Values{}
+	if p.Mode != "" {
+		v.Set("mode", p.Mode)
+	}
+	return v
+}
+
+// DeleteAccount DELETE /api/v1/users/{id}
+//
+// Delete or anonymize the current user's account
+func (c *Client) DeleteAccount(ctx context.Context, id string, params DeleteAccountParams, body *DeleteAccountRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/users/"+url.PathEscape(id), params.values(), body, &out, opts)
+	return out, err
+}
+
+// DeleteAttachment DELETE /api/v1/attachments/{id}
+//
+// Delete a file
+func (c *Client) DeleteAttachment(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/attachments/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// DeleteColumn DELETE /api/v1/columns/{id}
+//
+// Delete a column and its tasks
+func (c *Client) DeleteColumn(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/columns/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// DeleteProject DELETE /api/v1/projects/{id}
+//
+// Delete a project and everything in it
+func (c *Client) DeleteProject(ctx context.Context, id string, opts ...RequestOption) (OKResponse, error) {
+	var out OKResponse
+	err := c.do(ctx, "DELETE", "/api/v1/projects/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// DeleteTask DELETE /api/v1/tasks/{id}
+//
+// Delete a task
+func (c *Client) DeleteTask(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/tasks/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// DeleteWebhook DELETE /api/v1/projects/{id}/webhooks/{hookId}
+//
+// Delete a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id string, hookID string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks/"+url.PathEscape(hookID), nil, nil, &out, opts)
+	return out, err
+}
+
+// DisableTwoFactor POST /api/v1/auth/2fa/disable
+//
+// Turn off two-factor authentication
+func (c *Client) DisableTwoFactor(ctx context.Context, body DisableTwoFactorRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/2fa/disable", nil, body, &out, opts)
+	return out, err
+}
+
+// DownloadAttachmentParams are the query parameters of DownloadAttachment; zero values are not sent.
+type DownloadAttachmentParams struct {
+	Token string
+}
+
+func (p DownloadAttachmentParams) values() url.Values {
+	v := url.Values{}
+	if p.Token != "" {
+		v.Set("token", p.Token)
+	}
+	return v
+}
+
+// DownloadAttachment GET /api/v1/attachments/download
+//
+// Download a file with a link token
+func (c *Client) DownloadAttachment(ctx context.Context, params DownloadAttachmentParams, opts ...RequestOption) ([]byte, error) {
+	return c.download(ctx, "GET", "/api/v1/attachments/download", params.values(), opts)
+}
+
+// EnrollTwoFactor POST /api/v1/auth/2fa/enroll
+//
+// Start two-factor enrollment
+func (c *Client) EnrollTwoFactor(ctx context.Context, opts ...RequestOption) (EnrollTwoFactorResponse, error) {
+	var out EnrollTwoFactorResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/2fa/enroll", nil, nil, &out, opts)
+	return out, err
+}
+
+// ExportMyData GET /api/v1/me/export
+//
+// Download all personal data as a ZIP
+func (c *Client) ExportMyData(ctx context.Context, opts ...RequestOption) ([]byte, error) {
+	return c.download(ctx, "GET", "/api/v1/me/export", nil, opts)
+}
+
+// ForgotPassword POST /api/v1/auth/forgot-password
+//
+// Request a password reset link
+func (c *Client) ForgotPassword(ctx context.Context, body ForgotPasswordRequest, opts ...RequestOption) (ForgotPasswordResponse, error) {
+	var out ForgotPasswordResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/forgot-password", nil, body, &out, opts)
+	return out, err
+}
+
+// GetAttachmentLink GET /api/v1/attachments/{id}/link
+//
+// Short-lived download link
+func (c *Client) GetAttachmentLink(ctx context.Context, id string, opts ...RequestOption) (AttachmentLinkResponse, error) {
+	var out AttachmentLinkResponse
+	err := c.do(ctx, "GET", "/api/v1/attachments/"+url.PathEscape(id)+"/link", nil, nil, &out, opts)
+	return out, err
+}
+
+// GetJWKS GET /.well-known/jwks.json
+//
+// Public keys for verifying access tokens
+func (c *Client) GetJWKS(ctx context.Context, opts ...RequestOption) (JWKSet, error) {
+	var out JWKSet
+	err := c.do(ctx, "GET", "/.well-known/jwks.json", nil, nil, &out, opts)
+	return out, err
+}
+
+// GetNotificationPreferences GET /api/v1/me/notification-preferences
+//
+// Channels for each notification type
+func (c *Client) GetNotificationPreferences(ctx context.Context, opts ...RequestOption) (map[string]NotificationChannels, error) {
+	var out map[string]NotificationChannels
+	err := c.do(ctx, "GET", "/api/v1/me/notification-preferences", nil, nil, &out, opts)
+	return out, err
+}
+
+// GetProjectAnalyticsParams are the query parameters of GetProjectAnalytics; zero values are not sent.
+type GetProjectAnalyticsParams struct {
+	// YYYY-MM-DD (default: 30 days ago)
+	From string
+	// YYYY-MM-DD (default: today)
+	To string
+}
+
+func (p GetProjectAnalyticsParams) values() url.Values {
+	v := url.Values{}
+	if p.From != "" {
+		v.Set("from", p.From)
+	}
+	if p.To != "" {
+		v.Set("to", p.To)
+	}
+	return v
+}
+
+// GetProjectAnalytics GET /api/v1/projects/{id}/analytics
+//
+// Cumulative flow, cycle time, throughput and WIP breaches
+func (c *Client) GetProjectAnalytics(ctx context.Context, id string, params GetProjectAnalyticsParams, opts ...RequestOption) (ProjectAnalyticsResponse, error) {
+	var out ProjectAnalyticsResponse
+	err := c.do(ctx, "GET", "/api/v1/projects/"+url.PathEscape(id)+"/analytics", params.values(), nil, &out, opts)
+	return out, err
+}
+
+// GetProjectDetail GET /api/v1/projects/{id}
+//
+// Project with its board, columns and tasks
+func (c *Client) GetProjectDetail(ctx context.Context, id string, opts ...RequestOption) (ProjectDetailResponse, error) {
+	var out ProjectDetailResponse
+	err := c.do(ctx, "GET", "/api/v1/projects/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// InviteProjectMember POST /api/v1/projects/{id}/members
+//
+// Add a user to a project by email
+func (c *Client) InviteProjectMember(ctx context.Context, id string, body InviteMemberRequest, opts ...RequestOption) (ProjectMember, error) {
+	var out ProjectMember
+	err := c.do(ctx, "POST", "/api/v1/projects/"+url.PathEscape(id)+"/members", nil, body, &out, opts)
+	return out, err
+}
+
+// LeaveProject POST /api/v1/projects/{id}/leave
+//
+// Leave a project
+func (c *Client) LeaveProject(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/projects/"+url.PathEscape(id)+"/leave", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListAttachments GET /api/v1/tasks/{id}/attachments
+//
+// Files attached to a task
+func (c *Client) ListAttachments(ctx context.Context, id string, opts ...RequestOption) ([]Attachment, error) {
+	var out []Attachment
+	err := c.do(ctx, "GET", "/api/v1/tasks/"+url.PathEscape(id)+"/attachments", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListComments GET /api/v1/tasks/{id}/comments
+//
+// Comments on a task
+func (c *Client) ListComments(ctx context.Context, id string, opts ...RequestOption) ([]Comment, error) {
+	var out []Comment
+	err := c.do(ctx, "GET", "/api/v1/tasks/"+url.PathEscape(id)+"/comments", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListNotificationsParams are the query parameters of ListNotifications; zero values are not sent.
+type ListNotificationsParams struct {
+	// only unread notifications
+	Unread *bool
+	// 1-200 (default 50)
+	Limit int
+}
+
+func (p ListNotificationsParams) values() url.Values {
+	v := url.Values{}
+	if p.Unread != nil {
+		v.Set("unread", strconv.FormatBool(*p.Unread))
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return v
+}
+
+// ListNotifications GET /api/v1/notifications
+//
+// In-app notifications, newest first
+func (c *Client) ListNotifications(ctx context.Context, params ListNotificationsParams, opts ...RequestOption) ([]Notification, error) {
+	var out []Notification
+	err := c.do(ctx, "GET", "/api/v1/notifications", params.values(), nil, &out, opts)
+	return out, err
+}
+
+// ListOIDCProviders GET /api/v1/auth/oidc/providers
+//
+// Configured single sign-on providers
+func (c *Client) ListOIDCProviders(ctx context.Context, opts ...RequestOption) (OIDCProvidersResponse, error) {
+	var out OIDCProvidersResponse
+	err := c.do(ctx, "GET", "/api/v1/auth/oidc/providers", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListPersonalAccessTokens GET /api/v1/me/tokens
+//
+// Personal access tokens of the current user
+func (c *Client) ListPersonalAccessTokens(ctx context.Context, opts ...RequestOption) ([]PersonalAccessToken, error) {
+	var out []PersonalAccessToken
+	err := c.do(ctx, "GET", "/api/v1/me/tokens", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListProjects GET /api/v1/projects
+//
+// Projects the user owns or belongs to
+func (c *Client) ListProjects(ctx context.Context, opts ...RequestOption) ([]ProjectResponse, error) {
+	var out []ProjectResponse
+	err := c.do(ctx, "GET", "/api/v1/projects", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListWebhookDeliveries GET /api/v1/projects/{id}/webhooks/{hookId}/deliveries
+//
+// Recent deliveries of a webhook
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id string, hookID string, opts ...RequestOption) ([]WebhookDelivery, error) {
+	var out []WebhookDelivery
+	err := c.do(ctx, "GET", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks/"+url.PathEscape(hookID)+"/deliveries", nil, nil, &out, opts)
+	return out, err
+}
+
+// ListWebhooks GET /api/v1/projects/{id}/webhooks
+//
+// Project webhooks
+func (c *Client) ListWebhooks(ctx context.Context, id string, opts ...RequestOption) ([]Webhook, error) {
+	var out []Webhook
+	err := c.do(ctx, "GET", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks", nil, nil, &out, opts)
+	return out, err
+}
+
+// Login POST /api/v1/auth/login
+//
+// Log in with email and password (may require a second factor)
+func (c *Client) Login(ctx context.Context, body LoginRequest, opts ...RequestOption) (LoginResponse, error) {
+	var out LoginResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/login", nil, body, &out, opts)
+	return out, err
+}
+
+// LoginTwoFactor POST /api/v1/auth/login/2fa
+//
+// Finish a login with a TOTP or recovery code
+func (c *Client) LoginTwoFactor(ctx context.Context, body LoginTwoFactorRequest, opts ...RequestOption) (LoginResponse, error) {
+	var out LoginResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/login/2fa", nil, body, &out, opts)
+	return out, err
+}
+
+// MarkAllNotificationsRead POST /api/v1/notifications/read-all
+//
+// Mark every notification as read
+func (c *Client) MarkAllNotificationsRead(ctx context.Context, opts ...RequestOption) (MarkAllReadResponse, error) {
+	var out MarkAllReadResponse
+	err := c.do(ctx, "POST", "/api/v1/notifications/read-all", nil, nil, &out, opts)
+	return out, err
+}
+
+// MarkNotificationRead PATCH /api/v1/notifications/{id}/read
+//
+// Mark a notification as read
+func (c *Client) MarkNotificationRead(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "PATCH", "/api/v1/notifications/"+url.PathEscape(id)+"/read", nil, nil, &out, opts)
+	return out, err
+}
+
+// Me GET /api/v1/me
+//
+// Current user
+func (c *Client) Me(ctx context.Context, opts ...RequestOption) (MeResponse, error) {
+	var out MeResponse
+	err := c.do(ctx, "GET", "/api/v1/me", nil, nil, &out, opts)
+	return out, err
+}
+
+// MoveTask PATCH /api/v1/tasks/move
+//
+// Move a task to another column
+func (c *Client) MoveTask(ctx context.Context, body MoveTaskRequest, opts ...RequestOption) (OKResponse, error) {
+	var out OKResponse
+	err := c.do(ctx, "PATCH", "/api/v1/tasks/move", nil, body, &out, opts)
+	return out, err
+}
+
+// RedeliverWebhook POST /api/v1/projects/{id}/webhooks/{hookId}/deliveries/{deliveryId}/redeliver
+//
+// Queue a delivery again
+func (c *Client) RedeliverWebhook(ctx context.Context, id string, hookID string, deliveryID string, opts ...RequestOption) (WebhookDelivery, error) {
+	var out WebhookDelivery
+	err := c.do(ctx, "POST", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks/"+url.PathEscape(hookID)+"/deliveries/"+url.PathEscape(deliveryID)+"/redeliver", nil, nil, &out, opts)
+	return out, err
+}
+
+// Register POST /api/v1/auth/register
+//
+// Create an account
+func (c *Client) Register(ctx context.Context, body RegisterRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/register", nil, body, &out, opts)
+	return out, err
+}
+
+// RemoveTaskDependency DELETE /api/v1/tasks/{id}/dependencies/{blockerId}
+//
+// Remove a dependency
+func (c *Client) RemoveTaskDependency(ctx context.Context, id string, blockerID string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/tasks/"+url.PathEscape(id)+"/dependencies/"+url.PathEscape(blockerID), nil, nil, &out, opts)
+	return out, err
+}
+
+// ResetPassword POST /api/v1/auth/reset-password
+//
+// Set a new password with a reset token
+func (c *Client) ResetPassword(ctx context.Context, body ResetPasswordRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/api/v1/auth/reset-password", nil, body, &out, opts)
+	return out, err
+}
+
+// RevokePersonalAccessToken DELETE /api/v1/me/tokens/{id}
+//
+// Revoke a token
+func (c *Client) RevokePersonalAccessToken(ctx context.Context, id string, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "DELETE", "/api/v1/me/tokens/"+url.PathEscape(id), nil, nil, &out, opts)
+	return out, err
+}
+
+// TransferProject POST /api/v1/projects/{id}/transfer
+//
+// Transfer ownership to another member
+func (c *Client) TransferProject(ctx context.Context, id string, body TransferProjectRequest, opts ...RequestOption) (TransferProjectResponse, error) {
+	var out TransferProjectResponse
+	err := c.do(ctx, "POST", "/api/v1/projects/"+url.PathEscape(id)+"/transfer", nil, body, &out, opts)
+	return out, err
+}
+
+// UnreadNotificationCount GET /api/v1/notifications/unread-count
+//
+// Number of unread notifications
+func (c *Client) UnreadNotificationCount(ctx context.Context, opts ...RequestOption) (UnreadCountResponse, error) {
+	var out UnreadCountResponse
+	err := c.do(ctx, "GET", "/api/v1/notifications/unread-count", nil, nil, &out, opts)
+	return out, err
+}
+
+// UpdateColumn PATCH /api/v1/columns/{id}
+//
+// Rename a column or change its category
+func (c *Client) UpdateColumn(ctx context.Context, id string, body UpdateColumnRequest, opts ...RequestOption) (VersionResponse, error) {
+	var out VersionResponse
+	err := c.do(ctx, "PATCH", "/api/v1/columns/"+url.PathEscape(id), nil, body, &out, opts)
+	return out, err
+}
+
+// UpdateNotificationPreferences PUT /api/v1/me/notification-preferences
+//
+// Change channels for some notification types
+func (c *Client) UpdateNotificationPreferences(ctx context.Context, body map[string]NotificationChannels, opts ...RequestOption) (map[string]NotificationChannels, error) {
+	var out map[string]NotificationChannels
+	err := c.do(ctx, "PUT", "/api/v1/me/notification-preferences", nil, body, &out, opts)
+	return out, err
+}
+
+// UpdateProfile PATCH /api/v1/users/{id}
+//
+// Update the current user's profile
+func (c *Client) UpdateProfile(ctx context.Context, id string, body UpdateProfileRequest, opts ...RequestOption) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "PATCH", "/api/v1/users/"+url.PathEscape(id), nil, body, &out, opts)
+	return out, err
+}
+
+// UpdateProject PATCH /api/v1/projects/{id}
+//
+// Rename a project
+func (c *Client) UpdateProject(ctx context.Context, id string, body UpdateProjectRequest, opts ...RequestOption) (VersionResponse, error) {
+	var out VersionResponse
+	err := c.do(ctx, "PATCH", "/api/v1/projects/"+url.PathEscape(id), nil, body, &out, opts)
+	return out, err
+}
+
+// UpdateTask PATCH /api/v1/tasks/{id}
+//
+// Update a task
+func (c *Client) UpdateTask(ctx context.Context, id string, body UpdateTaskRequest, opts ...RequestOption) (VersionResponse, error) {
+	var out VersionResponse
+	err := c.do(ctx, "PATCH", "/api/v1/tasks/"+url.PathEscape(id), nil, body, &out, opts)
+	return out, err
+}
+
+// UpdateWebhook PATCH /api/v1/projects/{id}/webhooks/{hookId}
+//
+// Update a webhook
+func (c *Client) UpdateWebhook(ctx context.Context, id string, hookID string, body UpdateWebhookRequest, opts ...RequestOption) (Webhook, error) {
+	var out Webhook
+	err := c.do(ctx, "PATCH", "/api/v1/projects/"+url.PathEscape(id)+"/webhooks/"+url.PathEscape(hookID), nil, body, &out, opts)
+	return out, err
+}
+
+// UploadAttachment POST /api/v1/tasks/{id}/attachments
+//
+// Upload a file
+func (c *Client) UploadAttachment(ctx context.Context, id string, filename string, file io.Reader, opts ...RequestOption) (Attachment, error) {
+	var out Attachment
+	err := c.upload(ctx, "/api/v1/tasks/"+url.PathEscape(id)+"/attachments", filename, file, &out, opts)
+	return out, err
+}
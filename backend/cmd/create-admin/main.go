@@ -0,0 +1,98 @@
+// create-admin สร้าง (หรือเลื่อนขั้น) user ให้เป็น ADMIN ของทั้งระบบ ใช้ตั้ง admin คนแรก
+//
+//	go run ./cmd/create-admin -email admin@example.com -name Admin
+//
+// ถ้ายังไม่มี user ที่ email นี้ ต้องตั้งรหัสผ่านผ่าน -password หรือ env ADMIN_PASSWORD
+// ถ้ามี admin อยู่แล้วจะไม่ทำอะไร (ใช้ -force เพื่อเพิ่ม admin อีกคนจาก CLI)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+
+	"mini-taskmgr-backend/internal/config"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+func main() {
+	email := flag.String("email", "", "email ของ admin (จำเป็น)")
+	name := flag.String("name", "Admin", "ชื่อ (ใช้ตอนสร้าง user ใหม่)")
+	password := flag.String("password", os.Getenv("ADMIN_PASSWORD"), "รหัสผ่าน (ใช้ตอนสร้าง user ใหม่, ค่าเริ่มต้นจาก ADMIN_PASSWORD)")
+	force := flag.Bool("force", false, "ทำต่อแม้มี admin อยู่แล้ว")
+	configFile := flag.String("config", "", "ไฟล์ config แบบ YAML (ค่าเริ่มต้นจาก CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	addr := strings.ToLower(strings.TrimSpace(*email))
+	if addr == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db.Connect(cfg.Mongo.URI, cfg.Mongo.Database)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	defer db.Disconnect(context.Background())
+
+	users := db.Database.Collection("users")
+
+	if !*force {
+		n, err := users.CountDocuments(ctx, bson.M{"role": models.RoleAdmin})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n > 0 {
+			log.Fatalf("%d admin(s) already exist; use -force to add another", n)
+		}
+	}
+
+	var u models.User
+	err = users.FindOne(ctx, bson.M{"email": addr}).Decode(&u)
+	switch {
+	case err == nil:
+		if _, err := users.UpdateByID(ctx, u.ID, bson.M{
+			"$set":   bson.M{"role": models.RoleAdmin},
+			"$unset": bson.M{"disabled": "", "disabledAt": ""},
+		}); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("promoted %s (%s) to ADMIN", addr, u.ID.Hex())
+
+	case errors.Is(err, mongo.ErrNoDocuments):
+		if len(*password) < 6 {
+			log.Fatal("user does not exist: -password (or ADMIN_PASSWORD) of at least 6 characters is required")
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(*password), 10)
+		if err != nil {
+			log.Fatal(err)
+		}
+		res, err := users.InsertOne(ctx, bson.M{
+			"name":         *name,
+			"email":        addr,
+			"passwordHash": string(hash),
+			"role":         models.RoleAdmin,
+			"createdAt":    time.Now(),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("created ADMIN %s (%v)", addr, res.InsertedID)
+
+	default:
+		log.Fatal(err)
+	}
+}
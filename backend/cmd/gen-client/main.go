@@ -0,0 +1,353 @@
+// gen-client สร้าง Go client (package client) จากเอกสาร OpenAPI ของ server
+//
+//	go generate ./client
+//
+// type ทุกตัวมาจาก components.schemas และ method หนึ่งตัวต่อ operation (ชื่อตาม operationId)
+// operation ที่ใช้ผ่าน browser เท่านั้น (x-browser-only) จะถูกข้าม
+// header parameter (เช่น If-Match, Idempotency-Key) ไม่เป็น argument แต่ส่งผ่าน opts ...RequestOption ที่ทุก method รับ
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mini-taskmgr-backend/internal/openapi"
+)
+
+// ชื่อที่เขียนเองไว้ใน client.go แล้ว
+var reserved = map[string]bool{"Client": true, "Error": true, "New": true, "RequestOption": true, "IfMatch": true, "IdempotencyKey": true}
+
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true, "sso": true, "api": true, "json": true, "http": true}
+
+func main() {
+	out := flag.String("o", "client_gen.go", "ไฟล์ที่จะเขียน")
+	pkg := flag.String("package", "client", "ชื่อ package")
+	flag.Parse()
+
+	src, err := generate(openapi.Build(), *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(doc *openapi.Document, pkg string) ([]byte, error) {
+	g := &gen{imports: map[string]bool{"context": true}}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if reserved[name] {
+			return nil, fmt.Errorf("schema %s clashes with a hand-written name in the client package", name)
+		}
+		g.p("// %s mirrors components.schemas.%s.", name, name)
+		g.p("type %s %s\n", name, g.goType(doc.Components.Schemas[name]))
+	}
+
+	type entry struct {
+		path, method string
+		op           *openapi.OperationDoc
+	}
+	var ops []entry
+	for path, methods := range doc.Paths {
+		for method, op := range methods {
+			ops = append(ops, entry{path, method, op})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].op.OperationID < ops[j].op.OperationID })
+	for _, e := range ops {
+		if browser, _ := e.op.Extensions["x-browser-only"].(bool); browser {
+			continue
+		}
+		if err := g.method(e.path, strings.ToUpper(e.method), e.op); err != nil {
+			return nil, err
+		}
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by gen-client from the OpenAPI document. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&file, "%q\n", imp)
+	}
+	file.WriteString(")\n\n")
+	file.Write(g.buf.Bytes())
+
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, file.String())
+	}
+	return src, nil
+}
+
+type gen struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *gen) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// goType type ของ Go ที่ตรงกับ schema
+func (g *gen) goType(s *openapi.Schema) string {
+	if s.Ref != "" {
+		return openapi.Component(s.Ref)
+	}
+	if len(s.AllOf) == 1 {
+		t := g.goType(s.AllOf[0])
+		if s.Nullable {
+			return "*" + t
+		}
+		return t
+	}
+
+	var t string
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			t = "time.Time"
+		case "byte":
+			t = "[]byte"
+		default:
+			t = "string"
+		}
+	case "integer":
+		t = "int64"
+		if s.Format == "int32" {
+			t = "int32"
+		}
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		t = "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			t = "map[string]" + g.goType(s.AdditionalProperties)
+			break
+		}
+		t = g.structType(s)
+	default:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+	if s.Nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (g *gen) structType(s *openapi.Schema) string {
+	if s.Properties == nil {
+		return "struct{}"
+	}
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, prop := range *s.Properties {
+		tag := prop.Name
+		if !required[prop.Name] {
+			tag += ",omitempty"
+		}
+		if d := prop.Schema.Description; d != "" {
+			fmt.Fprintf(&b, "// %s\n", d)
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", exportedName(prop.Name), g.goType(prop.Schema), tag)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func (g *gen) method(path, method string, op *openapi.OperationDoc) error {
+	name := op.OperationID
+	var args []string
+	args = append(args, "ctx context.Context")
+
+	// path: "/api/v1/projects/" + url.PathEscape(id) + ...
+	var pathExpr []string
+	rest := path
+	for {
+		i := strings.IndexByte(rest, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(rest, '}')
+		param := rest[i+1 : j]
+		arg := strings.TrimSuffix(param, "Id")
+		if arg != param {
+			arg += "ID"
+		}
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", rest[:i]), "url.PathEscape("+arg+")")
+		g.imports["net/url"] = true
+		args = append(args, arg+" string")
+		rest = rest[j+1:]
+	}
+	if rest != "" || len(pathExpr) == 0 {
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", rest))
+	}
+
+	var query []openapi.Parameter
+	for _, p := range op.Parameters {
+		if p.In == "query" {
+			query = append(query, p)
+		}
+	}
+	queryArg := "nil"
+	if len(query) > 0 {
+		g.params(name, query)
+		args = append(args, "params "+name+"Params")
+		queryArg = "params.values()"
+	}
+
+	bodyArg := "nil"
+	upload := false
+	if rb := op.RequestBody; rb != nil {
+		if _, ok := rb.Content["multipart/form-data"]; ok {
+			upload = true
+			g.imports["io"] = true
+			args = append(args, "filename string", "file io.Reader")
+		} else {
+			t := g.goType(rb.Content["application/json"].Schema)
+			if !rb.Required {
+				t = "*" + t
+			}
+			args = append(args, "body "+t)
+			bodyArg = "body"
+		}
+	}
+
+	args = append(args, "opts ...RequestOption")
+
+	var status string
+	var ok openapi.Response
+	for code, resp := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			status, ok = code, resp
+		}
+	}
+	if status == "" {
+		return fmt.Errorf("%s: no success response", name)
+	}
+
+	g.p("// %s %s %s", name, method, path)
+	if op.Summary != "" {
+		g.p("//\n// %s", op.Summary)
+	}
+	sig := fmt.Sprintf("func (c *Client) %s(%s)", name, strings.Join(args, ", "))
+	pathArg := strings.Join(pathExpr, " + ")
+
+	var resultType string
+	binary := false
+	for ct, mt := range ok.Content {
+		if ct == "application/json" {
+			resultType = g.goType(mt.Schema)
+		} else {
+			binary = true
+		}
+	}
+
+	switch {
+	case binary:
+		g.p("%s ([]byte, error) {", sig)
+		g.p("return c.download(ctx, %q, %s, %s, opts)\n}\n", method, pathArg, queryArg)
+	case upload:
+		g.p("%s (%s, error) {", sig, resultType)
+		g.p("var out %s", resultType)
+		g.p("err := c.upload(ctx, %s, filename, file, &out, opts)", pathArg)
+		g.p("return out, err\n}\n")
+	case resultType != "":
+		g.p("%s (%s, error) {", sig, resultType)
+		g.p("var out %s", resultType)
+		g.p("err := c.do(ctx, %q, %s, %s, %s, &out, opts)", method, pathArg, queryArg, bodyArg)
+		g.p("return out, err\n}\n")
+	default:
+		g.p("%s error {", sig)
+		g.p("return c.do(ctx, %q, %s, %s, %s, nil, opts)\n}\n", method, pathArg, queryArg, bodyArg)
+	}
+	return nil
+}
+
+// params struct ของ query parameter (ค่าศูนย์ / nil = ไม่ส่ง)
+func (g *gen) params(name string, query []openapi.Parameter) {
+	g.p("// %sParams are the query parameters of %s; zero values are not sent.", name, name)
+	g.p("type %sParams struct {", name)
+	for _, q := range query {
+		t := "string"
+		switch q.Schema.Type {
+		case "integer":
+			t = "int"
+		case "boolean":
+			t = "*bool"
+		}
+		if q.Description != "" {
+			g.p("// %s", q.Description)
+		}
+		g.p("%s %s", exportedName(q.Name), t)
+	}
+	g.p("}\n")
+
+	g.imports["net/url"] = true
+	g.p("func (p %sParams) values() url.Values {", name)
+	g.p("v := url.Values{}")
+	for _, q := range query {
+		field := "p." + exportedName(q.Name)
+		switch q.Schema.Type {
+		case "integer":
+			g.imports["strconv"] = true
+			g.p("if %s != 0 {\nv.Set(%q, strconv.Itoa(%s))\n}", field, q.Name, field)
+		case "boolean":
+			g.imports["strconv"] = true
+			g.p("if %s != nil {\nv.Set(%q, strconv.FormatBool(*%s))\n}", field, q.Name, field)
+		default:
+			g.p("if %s != \"\" {\nv.Set(%q, %s)\n}", field, q.Name, field)
+		}
+	}
+	g.p("return v\n}\n")
+}
+
+// exportedName แปลงชื่อแบบ camelCase เป็นชื่อ field ของ Go เช่น ownerId -> OwnerID
+func exportedName(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	var b strings.Builder
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
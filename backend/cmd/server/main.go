@@ -1,94 +1,271 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/config"
 	"mini-taskmgr-backend/internal/db"
 	"mini-taskmgr-backend/internal/handlers"
-	"mini-taskmgr-backend/internal/middleware"
+	"mini-taskmgr-backend/internal/idempotency"
+	"mini-taskmgr-backend/internal/logging"
+	"mini-taskmgr-backend/internal/metrics"
+	"mini-taskmgr-backend/internal/notify"
+	"mini-taskmgr-backend/internal/oidc"
+	"mini-taskmgr-backend/internal/openapi"
+	"mini-taskmgr-backend/internal/ratelimit"
+	"mini-taskmgr-backend/internal/scheduler"
+	"mini-taskmgr-backend/internal/storage"
+	"mini-taskmgr-backend/internal/tracing"
+	"mini-taskmgr-backend/internal/utils"
+	"mini-taskmgr-backend/internal/webhooks"
+)
+
+// version ตั้งตอน build เช่น go build -ldflags "-X main.version=1.4.0" ./cmd/server
+var version = "dev"
+
+// เวลาของขั้นตอนปิด server หลัง HTTP (แยกจาก ShutdownTimeout ที่ใช้รอ request)
+const (
+	dbDisconnectTimeout = 10 * time.Second
+	tracingFlushTimeout = 5 * time.Second
 )
 
 func main() {
-	// โหลด env
-	_ = godotenv.Load()
+	configFile := flag.String("config", "", "ไฟล์ config แบบ YAML (ค่าเริ่มต้นจาก CONFIG_FILE)")
+	flag.Parse()
+
+	// โหลด config จาก .env, env และไฟล์ YAML แล้วตรวจค่าก่อนเริ่มทำงาน
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// log แบบ JSON ผ่าน slog (log.Println ที่เหลืออยู่จะออกทางนี้ด้วย)
+	if err := logging.Setup(os.Stderr, cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("starting", "env", cfg.Env, "version", version)
+
+	// OpenTelemetry tracing (ส่งไปที่ OTLP/HTTP collector)
+	if cfg.Tracing.Enabled {
+		if err := tracing.Setup(tracing.Config{
+			Endpoint:    cfg.Tracing.Endpoint,
+			ServiceName: cfg.Tracing.ServiceName,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		}); err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("tracing enabled", "endpoint", cfg.Tracing.Endpoint, "sampleRatio", cfg.Tracing.SampleRatio)
+	}
+	if cfg.Env == config.EnvProduction {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// key สำหรับเซ็น/ตรวจ JWT
+	if err := utils.LoadSigningKeys(cfg.Auth.SigningKeyFile, cfg.Auth.VerifyKeyFiles); err != nil {
+		log.Fatal("jwt: ", err)
+	}
+	utils.TokenIssuer = cfg.Auth.Issuer
+	utils.TokenAudience = cfg.Auth.Audience
 
 	// ต่อ MongoDB
-	db.Connect()
-
-	// ใช้ gin.Default() -> มี Logger, Recovery
-	r := gin.Default()
-
-	// 👇 CORS สำคัญมาก
-	// Configure CORS
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:5173"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
-	r.Use(cors.New(config))
-
-	// ปิด warning proxy (ดีใน dev)
-	r.SetTrustedProxies(nil)
-
-	// health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"ok": true})
-	})
+	db.Connect(cfg.Mongo.URI, cfg.Mongo.Database)
 
-	api := r.Group("/api/v1")
-	{
-		// public routes
-		api.POST("/auth/register", handlers.Register)
-		api.POST("/auth/login", handlers.Login)
-		api.POST("/auth/forgot-password", handlers.ForgotPassword)
-		api.POST("/auth/reset-password", handlers.ResetPassword)
-
-		// protected routes
-		protected := api.Group("")
-		protected.Use(middleware.RequireAuth())
-		{
-			protected.GET("/me", handlers.Me)
-
-			// Projects
-			protected.GET("/projects", handlers.ListProjects)
-			protected.POST("/projects", handlers.CreateProject)
-			protected.GET("/projects/:id", handlers.GetProjectDetail)
-			protected.PATCH("/projects/:id", handlers.UpdateProject)
-			protected.DELETE("/projects/:id", handlers.DeleteProject)
-
-			// Columns
-			protected.POST("/columns", handlers.CreateColumn)
-			protected.PATCH("/columns/:id", handlers.UpdateColumn)
-			protected.DELETE("/columns/:id", handlers.DeleteColumn)
-
-			// Tasks
-			protected.POST("/tasks", handlers.CreateTask)
-			protected.PATCH("/tasks/:id", handlers.UpdateTask)
-			protected.DELETE("/tasks/:id", handlers.DeleteTask)
-			protected.PATCH("/tasks/move", handlers.MoveTask)
-
-			// Users
-			protected.PATCH("/users/:id", handlers.UpdateProfile)
-			protected.DELETE("/users/:id", handlers.DeleteAccount)
-			protected.POST("/auth/change-password", handlers.ChangePassword)
+	idxCtx, idxCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	schemaErr := db.EnsureIndexes(idxCtx)
+	if schemaErr != nil {
+		slog.Error("db: ensure indexes error", "err", schemaErr)
+	}
+	if err := db.BackfillCompletedAt(idxCtx); err != nil {
+		slog.Error("db: backfill completedAt error", "err", err)
+		schemaErr = err
+	}
+	if err := notify.EnsureIndexes(idxCtx); err != nil {
+		slog.Error("notifications: ensure indexes error", "err", err)
+		schemaErr = err
+	}
+	// /readyz จะไม่พร้อมจนกว่า database จะอยู่ที่ db.SchemaVersion
+	if schemaErr == nil {
+		if err := db.RecordSchemaVersion(idxCtx); err != nil {
+			slog.Error("db: record schema version error", "err", err)
 		}
+	}
+	idxCancel()
+
+	// SSO ผ่าน OIDC
+	for _, p := range cfg.OIDC.Providers {
+		handlers.OIDCProviders[p.Name] = oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	handlers.OIDCSuccessRedirect = cfg.OIDC.SuccessRedirect
+	handlers.ExposeResetTokens = cfg.Env == config.EnvDevelopment
+
+	// ที่เก็บไฟล์แนบ
+	store, err := storage.New(cfg.Storage.Backend, cfg.Storage.LocalDir, storage.S3Config{
+		Endpoint:  cfg.Storage.S3.Endpoint,
+		Region:    cfg.Storage.S3.Region,
+		Bucket:    cfg.Storage.S3.Bucket,
+		AccessKey: cfg.Storage.S3.AccessKey,
+		SecretKey: cfg.Storage.S3.SecretKey,
+		PathStyle: cfg.Storage.S3.PathStyle,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	handlers.AttachmentStorage = store
+	handlers.AttachmentLimits = handlers.AttachmentConfig{
+		MaxBytes:     cfg.Attachments.MaxBytes,
+		ProjectQuota: cfg.Attachments.ProjectQuotaBytes,
+		AllowedTypes: cfg.Attachments.AllowedTypes,
+		LinkTTL:      cfg.Attachments.LinkTTL,
+	}
 
+	// background jobs (แจ้งเตือน due date) ปลอดภัยเมื่อรันหลาย replica เพราะใช้ lease ใน MongoDB
+	sched := scheduler.New(db.Database)
+	sched.Add(scheduler.DueReminders(
+		cfg.Jobs.DueReminderInterval,
+		cfg.Jobs.DueSoonWindow,
+	))
+	sched.Add(scheduler.Job{
+		Name:     "webhook-deliveries",
+		Interval: cfg.Jobs.WebhookDeliveryInterval,
+		Run:      webhooks.Deliver,
+	})
+	sched.Start(context.Background())
+	handlers.Workers = sched
+	handlers.BuildVersion = version
+
+	// rate limit ของ auth endpoints (ตั้ง RATE_LIMIT_STORE=mongo เมื่อรันหลาย replica)
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "mongo" {
+		mongoStore := ratelimit.NewMongoStore(db.Database)
+		idxCtx, idxCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := mongoStore.EnsureIndexes(idxCtx); err != nil {
+			slog.Error("rate limit: ensure indexes error", "err", err)
+		}
+		idxCancel()
+		limitStore = mongoStore
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8000"
+	// Idempotency-Key ของ POST / PATCH (เก็บใน MongoDB ทุก replica จึงตอบซ้ำได้เหมือนกัน)
+	idemStore := idempotency.NewMongoStore(db.Database, cfg.Idempotency.TTL)
+	idemCtx, idemCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := idemStore.EnsureIndexes(idemCtx); err != nil {
+		slog.Error("idempotency: ensure indexes error", "err", err)
 	}
+	idemCancel()
 
-	log.Println("Server listening on :" + port)
+	if cfg.Metrics.Enabled {
+		registerBusinessMetrics()
+	}
+	apierror.UseJSONFieldNames()
+	r := newRouter(cfg, limitStore, idempotency.Middleware(idemStore, idempotency.Options{
+		MaxBody:   cfg.Idempotency.MaxBodyBytes,
+		MaxUpload: cfg.Attachments.MaxBytes,
+		// token / TOTP secret / recovery code / reset token / webhook secret ห้ามเก็บไว้ใน idempotencyKeys
+		Skip: openapi.SecretRoutes(),
+	}))
 
-	if err := r.Run(":" + port); err != nil {
+	// เอกสาร API: ถ้า route ไม่ตรงกับตารางใน internal/openapi ให้ล้มตั้งแต่ตอน start (test ใน routes_test.go เช็คเหมือนกัน)
+	openapi.Version = version
+	if err := openapi.Check(r.Routes()); err != nil {
 		log.Fatal(err)
 	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// รอ SIGINT / SIGTERM (หรือ server พัง) แล้วปิดตามลำดับ:
+	// /readyz ตอบ 503 แล้วรับ request ต่ออีก DrainDelay -> หยุดรับ connection ใหม่และรอ request ที่ค้างอยู่
+	// -> หยุด background jobs -> ปิด MongoDB -> ส่ง span ที่เหลือ แต่ละขั้นมีเวลาของตัวเอง ขั้นที่ช้าไม่กินเวลาของขั้นถัดไป
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	drain := cfg.Server.DrainDelay
+	select {
+	case sig := <-quit:
+		slog.Info("shutting down", "signal", sig.String(), "drainDelay", drain)
+	case err := <-serverErr:
+		// server ไม่ได้รับ request แล้ว ไม่ต้องรอ load balancer
+		slog.Error("server error", "err", err)
+		drain = 0
+	}
+	handlers.BeginShutdown()
+	if drain > 0 {
+		select {
+		case <-time.After(drain):
+		case sig := <-quit:
+			// ส่ง signal ซ้ำ = ไม่รอแล้ว
+			slog.Info("skipping drain delay", "signal", sig.String())
+		}
+	}
+
+	httpCtx, httpCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer httpCancel()
+	if err := srv.Shutdown(httpCtx); err != nil {
+		// เกินเวลาที่ให้ drain: ตัด connection ที่เหลือทิ้ง
+		slog.Error("server shutdown error", "err", err)
+		srv.Close()
+	}
+
+	sched.Stop()
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), dbDisconnectTimeout)
+	defer dbCancel()
+	if err := db.Disconnect(dbCtx); err != nil {
+		slog.Error("db: disconnect error", "err", err)
+	}
+	// ส่ง span ที่เหลือหลังปิดทุกอย่างแล้ว จะได้ไม่ตกหล่น span ของ request สุดท้าย
+	traceCtx, traceCancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer traceCancel()
+	if err := tracing.Shutdown(traceCtx); err != nil {
+		slog.Error("tracing: shutdown error", "err", err)
+	}
+	slog.Info("shutdown complete")
+}
+
+// registerBusinessMetrics gauge ของข้อมูลในระบบ อ่านจาก metadata ของ collection ตอน scrape (ไม่ scan ทั้ง collection)
+func registerBusinessMetrics() {
+	for _, m := range []struct{ name, help, coll string }{
+		{"taskmgr_users", "Number of user accounts.", "users"},
+		{"taskmgr_projects", "Number of projects.", "projects"},
+		{"taskmgr_tasks", "Number of tasks.", "tasks"},
+	} {
+		coll := db.Database.Collection(m.coll)
+		metrics.NewGaugeFunc(m.name, m.help, func(ctx context.Context) (float64, error) {
+			n, err := coll.EstimatedDocumentCount(ctx)
+			return float64(n), err
+		})
+	}
+	metrics.RegisterGoRuntime()
 }
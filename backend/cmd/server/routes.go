@@ -0,0 +1,184 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/config"
+	"mini-taskmgr-backend/internal/handlers"
+	"mini-taskmgr-backend/internal/idempotency"
+	"mini-taskmgr-backend/internal/logging"
+	"mini-taskmgr-backend/internal/metrics"
+	"mini-taskmgr-backend/internal/middleware"
+	"mini-taskmgr-backend/internal/openapi"
+	"mini-taskmgr-backend/internal/ratelimit"
+	"mini-taskmgr-backend/internal/tracing"
+)
+
+// newRouter สร้าง gin engine พร้อม middleware และทุก route (ไม่แตะ database ตอนสร้าง จึงเรียกจาก test ได้)
+// idem คือ middleware ของ Idempotency-Key ที่ครอบ protected routes
+func newRouter(cfg *config.Config, limitStore ratelimit.Store, idem gin.HandlerFunc) *gin.Engine {
+	// request id -> span -> access log -> metrics -> error renderer -> recovery
+	// ตัวเขียน error ต้องอยู่ในสุดถัดจาก recovery: log / metrics / span จึงเห็น status จริง (รวม 500 จาก panic)
+	r := gin.New()
+	r.Use(logging.RequestIDMiddleware(), tracing.Middleware(), logging.AccessLog())
+	if cfg.Metrics.Enabled {
+		r.Use(metrics.Middleware())
+	}
+	r.Use(apierror.Middleware(), logging.Recovery())
+	r.NoRoute(apierror.NoRoute)
+
+	// 👇 CORS สำคัญมาก
+	// Configure CORS
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.CORS.AllowOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", logging.RequestIDHeader, "traceparent", "If-Match", idempotency.Header}
+	corsConfig.ExposeHeaders = []string{logging.RequestIDHeader, "ETag", idempotency.ReplayedHeader}
+	corsConfig.AllowCredentials = true
+	r.Use(cors.New(corsConfig))
+
+	// เชื่อ X-Forwarded-For เฉพาะจาก proxy ที่ตั้งไว้ ไม่อย่างนั้น rate limit ต่อ IP จะกลายเป็น IP ของ proxy
+	// หรือ client ปลอม header หลบ limit ได้ (ค่าผ่าน Validate แล้ว)
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		panic(err)
+	}
+
+	r.GET("/.well-known/jwks.json", handlers.JWKS)
+
+	// health check: /livez = process ยังทำงาน, /readyz = พร้อมรับ traffic (ตรวจ MongoDB ด้วย)
+	r.GET("/livez", handlers.Livez)
+	r.GET("/readyz", handlers.Readyz)
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	// Prometheus (ตั้ง METRICS_TOKEN เพื่อบังคับให้ scraper ส่ง bearer token)
+	if cfg.Metrics.Enabled {
+		r.GET("/metrics", metrics.Handler(cfg.Metrics.Token))
+	}
+
+	limits := cfg.RateLimit
+	limit := func(name string, rule config.RateLimitRule, account func(c *gin.Context) string) gin.HandlerFunc {
+		return ratelimit.Middleware(limitStore, ratelimit.Rule{
+			Name:       name,
+			PerIP:      ratelimit.Limit{Requests: rule.PerIP.Requests, Per: rule.PerIP.Per},
+			PerAccount: ratelimit.Limit{Requests: rule.PerAccount.Requests, Per: rule.PerAccount.Per},
+			AccountKey: account,
+		})
+	}
+	byEmail := ratelimit.JSONField("email")
+	loginLimit := limit("login", limits.Login, byEmail)
+	forgotPasswordLimit := limit("forgot-password", limits.ForgotPassword, byEmail)
+	resetPasswordLimit := limit("reset-password", limits.ResetPassword, nil)
+	loginTwoFactorLimit := limit("login-2fa", limits.LoginTwoFactor, nil)
+	oidcLimit := limit("oidc", limits.OIDC, nil)
+	registerLimit := limit("register", limits.Register, byEmail)
+
+	api := r.Group("/api/v1")
+	{
+		// public routes
+		api.POST("/auth/register", registerLimit, handlers.Register)
+		api.POST("/auth/login", loginLimit, handlers.Login)
+		api.POST("/auth/login/2fa", loginTwoFactorLimit, handlers.LoginTwoFactor)
+		api.GET("/auth/oidc/providers", handlers.ListOIDCProviders)
+		api.GET("/auth/oidc/:provider/login", oidcLimit, handlers.OIDCLogin)
+		api.GET("/auth/oidc/:provider/callback", oidcLimit, handlers.OIDCCallback)
+		// ลิงก์ดาวน์โหลดไฟล์แนบมี token อายุสั้นอยู่ใน URL แล้ว
+		api.GET("/attachments/download", handlers.DownloadAttachment)
+		api.POST("/auth/forgot-password", forgotPasswordLimit, handlers.ForgotPassword)
+		api.POST("/auth/reset-password", resetPasswordLimit, handlers.ResetPassword)
+
+		// protected routes
+		protected := api.Group("")
+		protected.Use(middleware.RequireAuth(), idem)
+		{
+			// scope ที่ personal access token ต้องมี (JWT จาก login ผ่านทุก scope)
+			scope := middleware.RequireScope
+			session := middleware.RequireSession()
+
+			protected.GET("/me", scope(middleware.ScopeProfileRead), handlers.Me)
+
+			// Projects
+			protected.GET("/projects", scope(middleware.ScopeProjectsRead), handlers.ListProjects)
+			protected.POST("/projects", scope(middleware.ScopeProjectsWrite), handlers.CreateProject)
+			protected.GET("/projects/:id", scope(middleware.ScopeProjectsRead), handlers.GetProjectDetail)
+			protected.PATCH("/projects/:id", scope(middleware.ScopeProjectsWrite), handlers.UpdateProject)
+			protected.DELETE("/projects/:id", scope(middleware.ScopeProjectsWrite), handlers.DeleteProject)
+			protected.GET("/projects/:id/analytics", scope(middleware.ScopeProjectsRead), handlers.GetProjectAnalytics)
+			protected.POST("/projects/:id/members", scope(middleware.ScopeProjectsWrite), handlers.InviteProjectMember)
+			protected.POST("/projects/:id/leave", scope(middleware.ScopeProjectsWrite), handlers.LeaveProject)
+			protected.POST("/projects/:id/transfer", session, handlers.TransferProject)
+
+			// Webhooks (project admins)
+			protected.GET("/projects/:id/webhooks", scope(middleware.ScopeProjectsWrite), handlers.ListWebhooks)
+			protected.POST("/projects/:id/webhooks", scope(middleware.ScopeProjectsWrite), handlers.CreateWebhook)
+			protected.PATCH("/projects/:id/webhooks/:hookId", scope(middleware.ScopeProjectsWrite), handlers.UpdateWebhook)
+			protected.DELETE("/projects/:id/webhooks/:hookId", scope(middleware.ScopeProjectsWrite), handlers.DeleteWebhook)
+			protected.GET("/projects/:id/webhooks/:hookId/deliveries", scope(middleware.ScopeProjectsWrite), handlers.ListWebhookDeliveries)
+			protected.POST("/projects/:id/webhooks/:hookId/deliveries/:deliveryId/redeliver", scope(middleware.ScopeProjectsWrite), handlers.RedeliverWebhook)
+
+			// Columns
+			protected.POST("/columns", scope(middleware.ScopeProjectsWrite), handlers.CreateColumn)
+			protected.PATCH("/columns/:id", scope(middleware.ScopeProjectsWrite), handlers.UpdateColumn)
+			protected.DELETE("/columns/:id", scope(middleware.ScopeProjectsWrite), handlers.DeleteColumn)
+
+			// Tasks
+			protected.POST("/tasks", scope(middleware.ScopeTasksWrite), handlers.CreateTask)
+			protected.PATCH("/tasks/:id", scope(middleware.ScopeTasksWrite), handlers.UpdateTask)
+			protected.DELETE("/tasks/:id", scope(middleware.ScopeTasksWrite), handlers.DeleteTask)
+			protected.PATCH("/tasks/move", scope(middleware.ScopeTasksWrite), handlers.MoveTask)
+			protected.POST("/tasks/bulk", scope(middleware.ScopeTasksWrite), handlers.BulkTasks)
+			protected.POST("/tasks/:id/dependencies", scope(middleware.ScopeTasksWrite), handlers.AddTaskDependency)
+			protected.DELETE("/tasks/:id/dependencies/:blockerId", scope(middleware.ScopeTasksWrite), handlers.RemoveTaskDependency)
+			protected.GET("/tasks/:id/comments", scope(middleware.ScopeTasksRead), handlers.ListComments)
+			protected.POST("/tasks/:id/comments", scope(middleware.ScopeTasksWrite), handlers.CreateComment)
+			protected.GET("/tasks/:id/attachments", scope(middleware.ScopeTasksRead), handlers.ListAttachments)
+			protected.POST("/tasks/:id/attachments", scope(middleware.ScopeTasksWrite), handlers.UploadAttachment)
+			protected.GET("/attachments/:id/link", scope(middleware.ScopeTasksRead), handlers.GetAttachmentLink)
+			protected.DELETE("/attachments/:id", scope(middleware.ScopeTasksWrite), handlers.DeleteAttachment)
+
+			// Notifications
+			protected.GET("/notifications", scope(middleware.ScopeNotificationsRead), handlers.ListNotifications)
+			protected.GET("/notifications/unread-count", scope(middleware.ScopeNotificationsRead), handlers.UnreadNotificationCount)
+			protected.PATCH("/notifications/:id/read", scope(middleware.ScopeNotificationsWrite), handlers.MarkNotificationRead)
+			protected.POST("/notifications/read-all", scope(middleware.ScopeNotificationsWrite), handlers.MarkAllNotificationsRead)
+			protected.GET("/me/notification-preferences", scope(middleware.ScopeNotificationsRead), handlers.GetNotificationPreferences)
+			protected.PUT("/me/notification-preferences", scope(middleware.ScopeNotificationsWrite), handlers.UpdateNotificationPreferences)
+
+			// Personal access tokens (จัดการได้เฉพาะตอน login ด้วย JWT)
+			protected.GET("/me/tokens", session, handlers.ListPersonalAccessTokens)
+			protected.POST("/me/tokens", session, handlers.CreatePersonalAccessToken)
+			protected.DELETE("/me/tokens/:id", session, handlers.RevokePersonalAccessToken)
+
+			// Users
+			protected.PATCH("/users/:id", scope(middleware.ScopeProfileWrite), handlers.UpdateProfile)
+			protected.DELETE("/users/:id", session, handlers.DeleteAccount)
+			protected.GET("/me/export", session, handlers.ExportMyData)
+			protected.POST("/auth/change-password", session, handlers.ChangePassword)
+
+			// Admin console (role ADMIN ของทั้งระบบ, ใช้ได้เฉพาะ JWT จากการ login)
+			admin := protected.Group("/admin", session, middleware.RequireAdmin())
+			admin.GET("/users", handlers.AdminListUsers)
+			admin.PATCH("/users/:id/role", handlers.AdminUpdateUserRole)
+			admin.POST("/users/:id/disable", handlers.AdminDisableUser)
+			admin.POST("/users/:id/enable", handlers.AdminEnableUser)
+			admin.POST("/users/:id/force-password-reset", handlers.AdminForcePasswordReset)
+			admin.POST("/projects/:id/transfer", handlers.AdminTransferProject)
+			admin.GET("/stats", handlers.AdminStats)
+
+			// Two-factor authentication
+			protected.POST("/auth/2fa/enroll", session, handlers.EnrollTwoFactor)
+			protected.POST("/auth/2fa/confirm", session, handlers.ConfirmTwoFactor)
+			protected.POST("/auth/2fa/disable", session, handlers.DisableTwoFactor)
+		}
+
+	}
+
+	// เอกสาร API (ตารางใน internal/openapi ต้องตรงกับ route ข้างบน ดู routes_test.go)
+	r.GET("/openapi.json", openapi.Handler)
+	return r
+}
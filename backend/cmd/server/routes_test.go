@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/config"
+	"mini-taskmgr-backend/internal/openapi"
+	"mini-taskmgr-backend/internal/ratelimit"
+)
+
+// route ที่ลงทะเบียนจริงต้องตรงกับตาราง Operations ของ internal/openapi ทุกตัว
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Metrics.Enabled = true
+
+	r := newRouter(&cfg, ratelimit.NewMemoryStore(), func(c *gin.Context) { c.Next() })
+	if err := openapi.Check(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// rate limit ต่อ IP ต้องใช้ X-Forwarded-For เฉพาะเมื่อมาจาก proxy ที่ตั้งไว้
+func TestRateLimitUsesTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	send := func(r http.Handler, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/unknown/login", nil)
+		req.RemoteAddr = "10.1.2.3:40000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	newTestRouter := func(proxies []string) http.Handler {
+		cfg := config.Default()
+		cfg.Server.TrustedProxies = proxies
+		cfg.RateLimit.OIDC.PerIP = config.RateLimit{Requests: 1, Per: time.Hour}
+		return newRouter(&cfg, ratelimit.NewMemoryStore(), func(c *gin.Context) { c.Next() })
+	}
+
+	// ไม่เชื่อ proxy: ทุก request นับเป็น IP เดียวกัน ปลอม header หลบ limit ไม่ได้
+	r := newTestRouter(nil)
+	if send(r, "203.0.113.1") != http.StatusNotFound || send(r, "203.0.113.2") != http.StatusTooManyRequests {
+		t.Fatal("untrusted X-Forwarded-For must not split the per-IP bucket")
+	}
+
+	// หลัง load balancer: client แต่ละคนได้ bucket ของตัวเอง
+	r = newTestRouter([]string{"10.0.0.0/8"})
+	if send(r, "203.0.113.1") != http.StatusNotFound || send(r, "203.0.113.2") != http.StatusNotFound {
+		t.Fatal("clients behind a trusted proxy share one bucket")
+	}
+	if send(r, "203.0.113.1") != http.StatusTooManyRequests {
+		t.Fatal("per-IP limit not applied to the forwarded client address")
+	}
+}
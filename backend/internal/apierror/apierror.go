@@ -0,0 +1,199 @@
+// Package apierror error ของ API ที่มี code คงที่ (เช่น TASK_NOT_FOUND) ให้ frontend เอาไปแปลข้อความเอง
+// handler เรียก Abort แล้ว Middleware จะตอบเป็น RFC 7807 (application/problem+json) ให้ที่เดียว
+package apierror
+
+import (
+	"net/http"
+)
+
+// FieldError รายละเอียดของ field ที่ไม่ผ่าน validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error error ที่จะตอบกลับ client
+// ค่าใน catalog ด้านล่างเป็นต้นแบบ: With* / Wrap คืนสำเนาใหม่เสมอ ไม่แก้ตัวต้นแบบ
+type Error struct {
+	Status     int
+	Code       string
+	Detail     string
+	Fields     []FieldError
+	Extensions map[string]interface{}
+
+	cause error // ไม่ส่งให้ client ใช้ตอน log เท่านั้น
+}
+
+// New สร้าง error ใหม่
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.Detail + ": " + e.cause.Error()
+	}
+	return e.Code + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is ให้ errors.Is(err, apierror.ErrTaskNotFound) จริงสำหรับทุกสำเนาที่มี code เดียวกัน
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) clone() *Error {
+	cp := *e
+	cp.Fields = append([]FieldError(nil), e.Fields...)
+	if e.Extensions != nil {
+		cp.Extensions = make(map[string]interface{}, len(e.Extensions))
+		for k, v := range e.Extensions {
+			cp.Extensions[k] = v
+		}
+	}
+	return &cp
+}
+
+// WithDetail เปลี่ยนข้อความ (ภาษาอังกฤษ สำหรับคนอ่าน) แต่คง code เดิม
+func (e *Error) WithDetail(detail string) *Error {
+	cp := e.clone()
+	cp.Detail = detail
+	return cp
+}
+
+// With เพิ่ม field เสริมใน body เช่น maxBytes, blockedBy
+func (e *Error) With(key string, value interface{}) *Error {
+	cp := e.clone()
+	if cp.Extensions == nil {
+		cp.Extensions = map[string]interface{}{}
+	}
+	cp.Extensions[key] = value
+	return cp
+}
+
+// WithField เพิ่มรายละเอียดของ field ที่ผิด
+func (e *Error) WithField(f FieldError) *Error {
+	cp := e.clone()
+	cp.Fields = append(cp.Fields, f)
+	return cp
+}
+
+// Wrap แนบ error ต้นเหตุไว้สำหรับ log (client ไม่เห็น)
+func (e *Error) Wrap(cause error) *Error {
+	cp := e.clone()
+	cp.cause = cause
+	return cp
+}
+
+// ---- catalog ----
+// code เป็นสัญญากับ frontend: เพิ่มได้ แต่ห้ามเปลี่ยนชื่อหรือเอาออก
+
+var (
+	// ทั่วไป
+	ErrInternal      = New(http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+	ErrValidation    = New(http.StatusBadRequest, "VALIDATION_FAILED", "request validation failed")
+	ErrMalformedBody = New(http.StatusBadRequest, "MALFORMED_BODY", "request body is not valid JSON")
+	ErrInvalidID     = New(http.StatusBadRequest, "INVALID_ID", "invalid id")
+	ErrNothingToDo   = New(http.StatusBadRequest, "NOTHING_TO_UPDATE", "nothing to update")
+	ErrRouteNotFound = New(http.StatusNotFound, "ROUTE_NOT_FOUND", "route not found")
+	ErrRateLimited   = New(http.StatusTooManyRequests, "RATE_LIMITED", "too many requests, please try again later")
+	ErrBodyTooLarge  = New(http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "request body is too large")
+
+	// ยืนยันตัวตน
+	ErrUnauthorized          = New(http.StatusUnauthorized, "UNAUTHORIZED", "invalid token")
+	ErrTokenExpired          = New(http.StatusUnauthorized, "TOKEN_EXPIRED", "token expired")
+	ErrTokenRevoked          = New(http.StatusUnauthorized, "TOKEN_REVOKED", "token revoked")
+	ErrInvalidCredentials    = New(http.StatusUnauthorized, "INVALID_CREDENTIALS", "invalid credentials")
+	ErrInvalidPassword       = New(http.StatusUnauthorized, "INVALID_PASSWORD", "password is incorrect")
+	ErrInvalidResetToken     = New(http.StatusUnauthorized, "INVALID_RESET_TOKEN", "invalid or expired reset token")
+	ErrInvalidTwoFactorCode  = New(http.StatusUnauthorized, "INVALID_2FA_CODE", "invalid code")
+	ErrInvalidChallenge      = New(http.StatusUnauthorized, "INVALID_CHALLENGE_TOKEN", "invalid or expired challenge token")
+	ErrInvalidDownloadLink   = New(http.StatusUnauthorized, "INVALID_DOWNLOAD_LINK", "invalid or expired link")
+	ErrAccountLocked         = New(http.StatusTooManyRequests, "ACCOUNT_LOCKED", "account temporarily locked, please try again later")
+	ErrTwoFactorEnabled      = New(http.StatusConflict, "TWO_FACTOR_ALREADY_ENABLED", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled   = New(http.StatusBadRequest, "TWO_FACTOR_NOT_ENABLED", "two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled  = New(http.StatusBadRequest, "TWO_FACTOR_NOT_ENROLLED", "call /auth/2fa/enroll first")
+	ErrIdentityProvider      = New(http.StatusUnauthorized, "IDENTITY_PROVIDER_ERROR", "could not verify identity")
+	ErrIdentityUnavailable   = New(http.StatusBadGateway, "IDENTITY_PROVIDER_UNAVAILABLE", "identity provider unavailable")
+	ErrInvalidLoginState     = New(http.StatusUnauthorized, "INVALID_LOGIN_STATE", "invalid or expired login state")
+	ErrEmailNotVerified      = New(http.StatusUnauthorized, "EMAIL_NOT_VERIFIED", "identity provider did not return a verified email")
+	ErrAccountDisabled       = New(http.StatusForbidden, "ACCOUNT_DISABLED", "account disabled")
+	ErrPasswordResetRequired = New(http.StatusForbidden, "PASSWORD_RESET_REQUIRED", "password reset required")
+
+	// สิทธิ์
+	ErrForbidden            = New(http.StatusForbidden, "FORBIDDEN", "you don't have permission to do this")
+	ErrInsufficientScope    = New(http.StatusForbidden, "INSUFFICIENT_SCOPE", "token is missing a required scope")
+	ErrSessionRequired      = New(http.StatusForbidden, "SESSION_REQUIRED", "personal access tokens cannot be used here")
+	ErrAdminRequired        = New(http.StatusForbidden, "ADMIN_REQUIRED", "admin only")
+	ErrOwnerRequired        = New(http.StatusForbidden, "PROJECT_OWNER_REQUIRED", "only the project owner can do this")
+	ErrProjectAdminRequired = New(http.StatusForbidden, "PROJECT_ADMIN_REQUIRED", "only project admins can do this")
+
+	// ไม่พบข้อมูล
+	ErrProjectNotFound      = New(http.StatusNotFound, "PROJECT_NOT_FOUND", "project not found")
+	ErrBoardNotFound        = New(http.StatusNotFound, "BOARD_NOT_FOUND", "board not found")
+	ErrColumnNotFound       = New(http.StatusNotFound, "COLUMN_NOT_FOUND", "column not found")
+	ErrTaskNotFound         = New(http.StatusNotFound, "TASK_NOT_FOUND", "task not found")
+	ErrUserNotFound         = New(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+	ErrAttachmentNotFound   = New(http.StatusNotFound, "ATTACHMENT_NOT_FOUND", "attachment not found")
+	ErrWebhookNotFound      = New(http.StatusNotFound, "WEBHOOK_NOT_FOUND", "webhook not found")
+	ErrDeliveryNotFound     = New(http.StatusNotFound, "DELIVERY_NOT_FOUND", "delivery not found")
+	ErrNotificationNotFound = New(http.StatusNotFound, "NOTIFICATION_NOT_FOUND", "notification not found")
+	ErrTokenNotFound        = New(http.StatusNotFound, "TOKEN_NOT_FOUND", "token not found")
+	ErrDependencyNotFound   = New(http.StatusNotFound, "DEPENDENCY_NOT_FOUND", "dependency not found")
+	ErrProviderNotFound     = New(http.StatusNotFound, "PROVIDER_NOT_FOUND", "unknown provider")
+
+	// กติกาของ project / task
+	ErrAlreadyMember          = New(http.StatusConflict, "ALREADY_MEMBER", "user is already a member")
+	ErrNotProjectMember       = New(http.StatusBadRequest, "NOT_PROJECT_MEMBER", "user is not a project member")
+	ErrAlreadyOwner           = New(http.StatusBadRequest, "ALREADY_OWNER", "user already owns this project")
+	ErrNewOwnerDisabled       = New(http.StatusBadRequest, "NEW_OWNER_DISABLED", "new owner account is disabled")
+	ErrOwnerCannotLeave       = New(http.StatusConflict, "OWNER_CANNOT_LEAVE", "the owner must transfer the project before leaving")
+	ErrOwnerChanged           = New(http.StatusConflict, "OWNER_CHANGED", "project owner changed, please retry")
+	ErrProjectTransferPending = New(http.StatusConflict, "PROJECT_TRANSFER_REQUIRED", "you own projects shared with other members; transfer them to a member first")
+	ErrLastAdmin              = New(http.StatusConflict, "LAST_ADMIN", "cannot remove the last active admin")
+	ErrSelfAdminChange        = New(http.StatusBadRequest, "SELF_ADMIN_CHANGE", "you cannot change your own admin account this way")
+	ErrTaskBlocked            = New(http.StatusConflict, "TASK_BLOCKED", "task is blocked by unfinished tasks")
+	ErrDependencyCycle        = New(http.StatusConflict, "DEPENDENCY_CYCLE", "dependency would create a cycle")
+	ErrDependencyExists       = New(http.StatusConflict, "DEPENDENCY_EXISTS", "dependency already exists")
+	ErrDependencyConflict     = New(http.StatusConflict, "DEPENDENCY_CONFLICT", "dependencies changed while adding, please retry")
+	ErrDependencyInvalid      = New(http.StatusBadRequest, "DEPENDENCY_INVALID", "invalid dependency")
+	ErrBulkAborted            = New(http.StatusFailedDependency, "BULK_ABORTED", "not applied because another operation in the atomic batch failed")
+	ErrAtomicUnsupported      = New(http.StatusNotImplemented, "ATOMIC_NOT_SUPPORTED", "atomic mode needs MongoDB running as a replica set")
+
+	// แก้ไขพร้อมกัน (If-Match / ETag)
+	ErrVersionConflict = New(http.StatusPreconditionFailed, "VERSION_CONFLICT", "this item was changed by someone else, reload and try again")
+	ErrInvalidIfMatch  = New(http.StatusBadRequest, "INVALID_IF_MATCH", "If-Match must be an ETag from a previous response")
+
+	// Idempotency-Key
+	ErrInvalidIdempotencyKey = New(http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be 1-255 printable characters")
+	ErrIdempotencyKeyReused  = New(http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+	ErrIdempotencyInProgress = New(http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is still in progress, retry later")
+
+	// ไฟล์แนบ
+	ErrFileTooLarge        = New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large")
+	ErrQuotaExceeded       = New(http.StatusRequestEntityTooLarge, "ATTACHMENT_QUOTA_EXCEEDED", "project attachment quota exceeded")
+	ErrUnsupportedFileType = New(http.StatusUnsupportedMediaType, "UNSUPPORTED_FILE_TYPE", "file type is not allowed")
+)
+
+// Internal 500 พร้อมข้อความสั้น ๆ และ error ต้นเหตุ (ถ้ามี) สำหรับ log
+func Internal(detail string, cause error) *Error {
+	return ErrInternal.WithDetail(detail).Wrap(cause)
+}
+
+// InvalidID id ใน path / body / query ไม่ใช่ ObjectID
+func InvalidID(field string) *Error {
+	return ErrInvalidID.WithDetail("invalid " + field).WithField(FieldError{
+		Field:   field,
+		Code:    "objectid",
+		Message: "must be a valid id",
+	})
+}
+
+// Invalid VALIDATION_FAILED ของ field เดียว (code เป็นชื่อกฎแบบเดียวกับ validator เช่น required, oneof)
+func Invalid(field, code, message string) *Error {
+	return ErrValidation.WithDetail(message).WithField(FieldError{Field: field, Code: code, Message: message})
+}
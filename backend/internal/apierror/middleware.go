@@ -0,0 +1,162 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mini-taskmgr-backend/internal/logging"
+)
+
+// ProblemContentType content type ของ RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// Abort บันทึก err ไว้ใน context แล้วหยุด chain; Middleware จะเป็นคนเขียน response
+// err ที่ไม่ใช่ *Error จะตอบเป็น INTERNAL_ERROR
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Lookup แปลง error จาก FindOne: ไม่พบเอกสาร → notFound, อย่างอื่นเป็น 500
+func Lookup(err error, notFound *Error) *Error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound
+	}
+	return Internal("db error", err)
+}
+
+// NoRoute ใช้กับ r.NoRoute ให้ 404 ของ path ที่ไม่มีเป็นรูปแบบเดียวกัน
+func NoRoute(c *gin.Context) {
+	Abort(c, ErrRouteNotFound)
+}
+
+// Middleware เขียน error ล่าสุดใน c.Errors เป็น problem+json ถ้า handler ยังไม่ได้เขียน response เอง
+// ต้องอยู่ถัดจาก middleware ที่อ่าน status (access log, metrics, tracing) เพื่อให้เห็น status จริง
+// error ต้นเหตุของ 5xx จะไปอยู่ใน access log (field errors) อยู่แล้ว
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		Flush(c)
+	}
+}
+
+// Flush เขียน error ที่ Abort ไว้ทันที (ถ้ายังไม่มี response) สำหรับ middleware ชั้นในที่ต้องเห็น body จริง
+// เช่น idempotency ที่เก็บ response ไว้ตอบซ้ำ
+func Flush(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	var e *Error
+	if !errors.As(err, &e) {
+		e = ErrInternal.Wrap(err)
+	}
+	render(c, e)
+}
+
+func render(c *gin.Context, e *Error) {
+	body := make(map[string]interface{}, len(e.Extensions)+8)
+	for k, v := range e.Extensions {
+		body[k] = v
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(e.Status)
+	body["status"] = e.Status
+	body["detail"] = e.Detail
+	body["instance"] = c.Request.URL.Path
+	body["code"] = e.Code
+	if id := logging.RequestID(c.Request.Context()); id != "" {
+		body["requestId"] = id
+	}
+	if len(e.Fields) > 0 {
+		body["errors"] = e.Fields
+	}
+	// client รุ่นเก่าอ่านข้อความจาก "error"
+	body["error"] = e.Detail
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(e.Status, body)
+}
+
+var registerTagName sync.Once
+
+// Validation แปลง error จาก ShouldBindJSON เป็น VALIDATION_FAILED (รายละเอียดราย field ใช้ชื่อตาม json tag)
+// หรือ MALFORMED_BODY ถ้า body อ่านไม่ได้ โดยไม่ส่งข้อความดิบของ validator ให้ client
+func Validation(err error) *Error {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		e := ErrValidation.Wrap(err)
+		for _, fe := range verrs {
+			e.Fields = append(e.Fields, FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fieldMessage(fe),
+			})
+		}
+		if len(e.Fields) == 1 {
+			e.Detail = e.Fields[0].Field + " " + e.Fields[0].Message
+		}
+		return e
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Invalid(typeErr.Field, "type", typeErr.Field+" must be a "+typeErr.Type.String()).Wrap(err)
+	}
+	if errors.Is(err, io.EOF) {
+		return ErrMalformedBody.WithDetail("request body is required").Wrap(err)
+	}
+	return ErrMalformedBody.Wrap(err)
+}
+
+// UseJSONFieldNames ให้ validator รายงานชื่อ field ตาม json tag (เรียกครั้งเดียวตอนเริ่ม server)
+func UseJSONFieldNames() {
+	registerTagName.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	})
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "oneof":
+		return "must be one of " + fe.Param()
+	case "url", "http_url":
+		return "must be a valid URL"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,627 @@
+// Package config โหลดค่าตั้งของ server เป็น struct เดียว แล้วตรวจความถูกต้องตอน start
+//
+// ลำดับความสำคัญ (ตัวหลังทับตัวก่อน): ค่าเริ่มต้น -> ไฟล์ YAML (ถ้ามี) -> env / .env
+// binary เดียวกันจึงรันได้ทั้ง dev, staging และ prod โดยเปลี่ยนแค่ไฟล์ config หรือ env
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ค่า APP_ENV ที่รองรับ
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config ค่าตั้งทั้งหมดของ server
+type Config struct {
+	Env         string            `yaml:"env"`
+	Server      ServerConfig      `yaml:"server"`
+	Mongo       MongoConfig       `yaml:"mongo"`
+	Auth        AuthConfig        `yaml:"auth"`
+	CORS        CORSConfig        `yaml:"cors"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Attachments AttachmentsConfig `yaml:"attachments"`
+	Jobs        JobsConfig        `yaml:"jobs"`
+	OIDC        OIDCConfig        `yaml:"oidc"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	// timeout ของ http.Server (0 = ไม่จำกัด ยกเว้น ReadHeaderTimeout)
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	// DrainDelay เวลาที่ยังรับ request ต่อหลังได้ SIGTERM (/readyz ตอบ 503 แล้ว) ให้ load balancer เห็นก่อนหยุดรับ connection
+	// (0 = หยุดทันที ควรตั้งให้นานกว่ารอบของ readiness probe เมื่อรันหลัง load balancer)
+	DrainDelay time.Duration `yaml:"drainDelay"`
+	// ShutdownTimeout เวลาที่รอ request ที่ค้างอยู่ให้จบหลังหยุดรับ connection ใหม่
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// TrustedProxies IP / CIDR ของ reverse proxy / load balancer ที่เชื่อ X-Forwarded-For ได้
+	// (ว่าง = ไม่เชื่อ header ใช้ address ที่ต่อเข้ามาตรง ๆ เป็น IP ของ client)
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+type MongoConfig struct {
+	URI      string `yaml:"uri"`
+	Database string `yaml:"database"`
+}
+
+type AuthConfig struct {
+	// SigningKeyFile PEM private key ที่ใช้เซ็น JWT (ว่างได้เฉพาะ development: จะสุ่ม key ชั่วคราว)
+	SigningKeyFile string `yaml:"signingKeyFile"`
+	// VerifyKeyFiles key เก่าที่ยังยอมรับระหว่าง rotate
+	VerifyKeyFiles []string `yaml:"verifyKeyFiles"`
+	Issuer         string   `yaml:"issuer"`
+	Audience       string   `yaml:"audience"`
+}
+
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allowOrigins"`
+}
+
+type RateLimitConfig struct {
+	// Store memory (ค่าเริ่มต้น) หรือ mongo (เมื่อรันหลาย replica)
+	Store string `yaml:"store"`
+	// limit ของ route ที่ไม่ต้อง login
+	Login          RateLimitRule `yaml:"login"`
+	LoginTwoFactor RateLimitRule `yaml:"loginTwoFactor"`
+	ForgotPassword RateLimitRule `yaml:"forgotPassword"`
+	ResetPassword  RateLimitRule `yaml:"resetPassword"`
+	Register       RateLimitRule `yaml:"register"`
+	OIDC           RateLimitRule `yaml:"oidc"`
+}
+
+// RateLimitRule limit ต่อ IP และต่อบัญชี (email ใน body) ของ route หนึ่ง
+type RateLimitRule struct {
+	PerIP      RateLimit `yaml:"perIp"`
+	PerAccount RateLimit `yaml:"perAccount"`
+}
+
+// RateLimit อนุญาต Requests ครั้งต่อ Per (Requests = 0 คือไม่จำกัด)
+type RateLimit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+}
+
+type IdempotencyConfig struct {
+	// TTL เวลาที่เก็บ response ของ Idempotency-Key ไว้ตอบซ้ำ
+	TTL time.Duration `yaml:"ttl"`
+	// MaxBodyBytes ขนาดสูงสุดของ body (ที่ไม่ใช่ไฟล์แนบ) ของ request ที่มี Idempotency-Key
+	MaxBodyBytes int64 `yaml:"maxBodyBytes"`
+}
+
+type StorageConfig struct {
+	// Backend local หรือ s3
+	Backend  string   `yaml:"backend"`
+	LocalDir string   `yaml:"localDir"`
+	S3       S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	PathStyle bool   `yaml:"pathStyle"`
+}
+
+type AttachmentsConfig struct {
+	MaxBytes          int64         `yaml:"maxBytes"`
+	ProjectQuotaBytes int64         `yaml:"projectQuotaBytes"`
+	AllowedTypes      []string      `yaml:"allowedTypes"`
+	LinkTTL           time.Duration `yaml:"linkTTL"`
+}
+
+type JobsConfig struct {
+	DueReminderInterval     time.Duration `yaml:"dueReminderInterval"`
+	DueSoonWindow           time.Duration `yaml:"dueSoonWindow"`
+	WebhookDeliveryInterval time.Duration `yaml:"webhookDeliveryInterval"`
+}
+
+type OIDCConfig struct {
+	// SuccessRedirect หน้า frontend ที่รับ token ผ่าน URL fragment หลัง login สำเร็จ
+	SuccessRedirect string         `yaml:"successRedirect"`
+	Providers       []OIDCProvider `yaml:"providers"`
+}
+
+type MetricsConfig struct {
+	// Enabled เปิด /metrics (Prometheus)
+	Enabled bool `yaml:"enabled"`
+	// Token ถ้าตั้งไว้ scraper ต้องส่ง Authorization: Bearer <token>
+	Token string `yaml:"token"`
+}
+
+type LoggingConfig struct {
+	// Level debug | info | warn | error
+	Level string `yaml:"level"`
+	// Format json | text
+	Format string `yaml:"format"`
+}
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint URL ของ OTLP/HTTP traces เช่น http://localhost:4318/v1/traces
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret"`
+	RedirectURL  string   `yaml:"redirectUrl"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// Default ค่าเริ่มต้นสำหรับรันบนเครื่อง dev
+func Default() Config {
+	return Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Port:              "8000",
+			ReadHeaderTimeout: 10 * time.Second,
+			// upload ไฟล์แนบ / export ข้อมูลใช้เวลานานกว่า request ทั่วไป
+			ReadTimeout:     2 * time.Minute,
+			WriteTimeout:    2 * time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			Issuer:   "mini-taskmgr",
+			Audience: "mini-taskmgr-api",
+		},
+		CORS: CORSConfig{AllowOrigins: []string{"http://localhost:5173"}},
+		RateLimit: RateLimitConfig{
+			Store: "memory",
+			Login: RateLimitRule{
+				PerIP:      RateLimit{Requests: 20, Per: time.Minute},
+				PerAccount: RateLimit{Requests: 10, Per: 10 * time.Minute},
+			},
+			LoginTwoFactor: RateLimitRule{PerIP: RateLimit{Requests: 20, Per: time.Minute}},
+			ForgotPassword: RateLimitRule{
+				PerIP:      RateLimit{Requests: 10, Per: 10 * time.Minute},
+				PerAccount: RateLimit{Requests: 3, Per: time.Hour},
+			},
+			ResetPassword: RateLimitRule{PerIP: RateLimit{Requests: 10, Per: 10 * time.Minute}},
+			Register:      RateLimitRule{PerIP: RateLimit{Requests: 10, Per: time.Hour}},
+			OIDC:          RateLimitRule{PerIP: RateLimit{Requests: 30, Per: time.Minute}},
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, MaxBodyBytes: 1 << 20},
+		Storage: StorageConfig{
+			Backend:  "local",
+			LocalDir: "./data/attachments",
+			S3:       S3Config{Region: "us-east-1"},
+		},
+		Attachments: AttachmentsConfig{
+			MaxBytes:          10 << 20,
+			ProjectQuotaBytes: 500 << 20,
+			AllowedTypes: []string{
+				"image/png", "image/jpeg", "image/gif", "image/webp",
+				"application/pdf", "text/plain", "application/zip",
+			},
+			LinkTTL: 5 * time.Minute,
+		},
+		Jobs: JobsConfig{
+			DueReminderInterval:     5 * time.Minute,
+			DueSoonWindow:           24 * time.Hour,
+			WebhookDeliveryInterval: 10 * time.Second,
+		},
+		Metrics: MetricsConfig{Enabled: true},
+		Logging: LoggingConfig{Level: "info", Format: "json"},
+		Tracing: TracingConfig{
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "mini-taskmgr-api",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load โหลด .env (ถ้ามี) แล้วอ่านไฟล์ YAML ที่ path (หรือ CONFIG_FILE ถ้า path ว่าง)
+// ตามด้วย env แล้ว Validate ผลลัพธ์
+func Load(path string) (*Config, error) {
+	// .env ไม่ทับ env ที่ตั้งไว้แล้ว
+	_ = godotenv.Load()
+
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	// พิมพ์ชื่อ key ผิดต้อง error ไม่ใช่เงียบ ๆ ใช้ค่าเริ่มต้น
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv ทับค่าด้วย env ที่ตั้งไว้ (env ที่ไม่ได้ตั้งหรือว่างจะไม่เปลี่ยนค่าเดิม)
+func (c *Config) loadEnv() error {
+	e := envReader{}
+
+	e.str("APP_ENV", &c.Env)
+	e.str("PORT", &c.Server.Port)
+	e.duration("SERVER_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout)
+	e.duration("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	e.duration("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	e.duration("SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	e.duration("SHUTDOWN_DRAIN_DELAY", &c.Server.DrainDelay)
+	e.duration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	e.list("TRUSTED_PROXIES", &c.Server.TrustedProxies)
+
+	e.str("MONGODB_URI", &c.Mongo.URI)
+	e.str("MONGODB_DB", &c.Mongo.Database)
+
+	e.str("JWT_SIGNING_KEY_FILE", &c.Auth.SigningKeyFile)
+	e.list("JWT_VERIFY_KEY_FILES", &c.Auth.VerifyKeyFiles)
+	e.str("JWT_ISSUER", &c.Auth.Issuer)
+	e.str("JWT_AUDIENCE", &c.Auth.Audience)
+	if os.Getenv("JWT_SECRET") != "" {
+		slog.Warn("config: JWT_SECRET is no longer used, tokens are signed with JWT_SIGNING_KEY_FILE")
+	}
+
+	e.list("CORS_ALLOWED_ORIGINS", &c.CORS.AllowOrigins)
+
+	e.str("RATE_LIMIT_STORE", &c.RateLimit.Store)
+	// RATE_LIMIT_LOGIN_PER_IP=20/1m, RATE_LIMIT_LOGIN_PER_ACCOUNT=10/10m, ... (0 = ไม่จำกัด)
+	for name, rule := range c.RateLimit.rules() {
+		e.rateLimit("RATE_LIMIT_"+name+"_PER_IP", &rule.PerIP)
+		e.rateLimit("RATE_LIMIT_"+name+"_PER_ACCOUNT", &rule.PerAccount)
+	}
+	e.duration("IDEMPOTENCY_TTL", &c.Idempotency.TTL)
+	e.int64("IDEMPOTENCY_MAX_BODY_BYTES", &c.Idempotency.MaxBodyBytes)
+
+	e.str("STORAGE_BACKEND", &c.Storage.Backend)
+	e.str("STORAGE_LOCAL_DIR", &c.Storage.LocalDir)
+	e.str("S3_ENDPOINT", &c.Storage.S3.Endpoint)
+	e.str("S3_REGION", &c.Storage.S3.Region)
+	e.str("S3_BUCKET", &c.Storage.S3.Bucket)
+	e.str("S3_ACCESS_KEY", &c.Storage.S3.AccessKey)
+	e.str("S3_SECRET_KEY", &c.Storage.S3.SecretKey)
+	e.boolean("S3_PATH_STYLE", &c.Storage.S3.PathStyle)
+
+	e.int64("ATTACHMENT_MAX_BYTES", &c.Attachments.MaxBytes)
+	e.int64("ATTACHMENT_PROJECT_QUOTA_BYTES", &c.Attachments.ProjectQuotaBytes)
+	e.list("ATTACHMENT_ALLOWED_TYPES", &c.Attachments.AllowedTypes)
+	e.duration("ATTACHMENT_LINK_TTL", &c.Attachments.LinkTTL)
+
+	e.duration("DUE_REMINDER_INTERVAL", &c.Jobs.DueReminderInterval)
+	e.duration("DUE_SOON_WINDOW", &c.Jobs.DueSoonWindow)
+	e.duration("WEBHOOK_DELIVERY_INTERVAL", &c.Jobs.WebhookDeliveryInterval)
+
+	e.boolean("METRICS_ENABLED", &c.Metrics.Enabled)
+	e.str("METRICS_TOKEN", &c.Metrics.Token)
+
+	e.str("LOG_LEVEL", &c.Logging.Level)
+	e.str("LOG_FORMAT", &c.Logging.Format)
+
+	// ใช้ชื่อ env มาตรฐานของ OpenTelemetry
+	e.boolean("TRACING_ENABLED", &c.Tracing.Enabled)
+	if base := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")); base != "" {
+		c.Tracing.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	e.str("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", &c.Tracing.Endpoint)
+	e.str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+	e.float64("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	e.str("OIDC_SUCCESS_REDIRECT", &c.OIDC.SuccessRedirect)
+	// OIDC_PROVIDERS=company,google แล้วตั้ง OIDC_COMPANY_ISSUER, OIDC_COMPANY_CLIENT_ID,
+	// OIDC_COMPANY_CLIENT_SECRET, OIDC_COMPANY_REDIRECT_URL, OIDC_COMPANY_SCOPES (คั่นด้วยช่องว่าง)
+	if names := os.Getenv("OIDC_PROVIDERS"); names != "" {
+		c.OIDC.Providers = nil
+		for _, name := range strings.Split(names, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+			c.OIDC.Providers = append(c.OIDC.Providers, OIDCProvider{
+				Name:         name,
+				Issuer:       os.Getenv(prefix + "ISSUER"),
+				ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+				ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+				RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+				Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			})
+		}
+	}
+
+	return errors.Join(e.errs...)
+}
+
+// Validate ตรวจค่าทั้งหมดแล้วคืนทุกปัญหาที่เจอในครั้งเดียว
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("config: "+format, args...))
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		fail("APP_ENV must be %s, %s or %s (got %q)", EnvDevelopment, EnvStaging, EnvProduction, c.Env)
+	}
+
+	if n, err := strconv.Atoi(c.Server.Port); err != nil || n < 1 || n > 65535 {
+		fail("PORT must be a number between 1 and 65535 (got %q)", c.Server.Port)
+	}
+	if c.Server.ReadHeaderTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		fail("SERVER_READ_HEADER_TIMEOUT and SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.Server.DrainDelay < 0 {
+		fail("SHUTDOWN_DRAIN_DELAY must not be negative")
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		fail("SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT must not be negative")
+	}
+
+	if c.Mongo.URI == "" {
+		fail("MONGODB_URI is required")
+	}
+	if c.Mongo.Database == "" {
+		fail("MONGODB_DB is required")
+	}
+
+	// key ชั่วคราวใช้ร่วมกันหลาย replica ไม่ได้และ token หายทุกครั้งที่ restart
+	if c.Auth.SigningKeyFile == "" && c.Env != EnvDevelopment {
+		fail("JWT_SIGNING_KEY_FILE is required when APP_ENV is %s", c.Env)
+	}
+	if c.Auth.Issuer == "" || c.Auth.Audience == "" {
+		fail("JWT_ISSUER and JWT_AUDIENCE must not be empty")
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		fail("CORS_ALLOWED_ORIGINS is required")
+	}
+	for _, origin := range c.CORS.AllowOrigins {
+		// ใช้ "*" ไม่ได้เพราะ CORS เปิด credentials ไว้
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			fail("CORS_ALLOWED_ORIGINS: %q is not an origin like https://app.example.com", origin)
+		}
+	}
+
+	for _, p := range c.Server.TrustedProxies {
+		if net.ParseIP(p) == nil {
+			if _, _, err := net.ParseCIDR(p); err != nil {
+				fail("TRUSTED_PROXIES: %q is not an IP address or CIDR", p)
+			}
+		}
+	}
+
+	switch c.RateLimit.Store {
+	case "memory", "mongo":
+	default:
+		fail("RATE_LIMIT_STORE must be memory or mongo (got %q)", c.RateLimit.Store)
+	}
+	for name, rule := range c.RateLimit.rules() {
+		for suffix, l := range map[string]RateLimit{"PER_IP": rule.PerIP, "PER_ACCOUNT": rule.PerAccount} {
+			if l.Requests < 0 || (l.Requests > 0 && l.Per <= 0) {
+				fail("RATE_LIMIT_%s_%s must be requests/period with a positive period (got %d/%s)", name, suffix, l.Requests, l.Per)
+			}
+		}
+	}
+
+	if c.Idempotency.TTL <= 0 {
+		fail("IDEMPOTENCY_TTL must be positive")
+	}
+	if c.Idempotency.MaxBodyBytes <= 0 {
+		fail("IDEMPOTENCY_MAX_BODY_BYTES must be positive")
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			fail("STORAGE_LOCAL_DIR must not be empty")
+		}
+	case "s3":
+		s3 := c.Storage.S3
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			fail("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required when STORAGE_BACKEND is s3")
+		}
+	default:
+		fail("STORAGE_BACKEND must be local or s3 (got %q)", c.Storage.Backend)
+	}
+
+	if c.Attachments.MaxBytes <= 0 || c.Attachments.ProjectQuotaBytes <= 0 {
+		fail("ATTACHMENT_MAX_BYTES and ATTACHMENT_PROJECT_QUOTA_BYTES must be positive")
+	}
+	if c.Attachments.LinkTTL <= 0 {
+		fail("ATTACHMENT_LINK_TTL must be positive")
+	}
+	if c.Jobs.DueReminderInterval <= 0 || c.Jobs.DueSoonWindow <= 0 || c.Jobs.WebhookDeliveryInterval <= 0 {
+		fail("DUE_REMINDER_INTERVAL, DUE_SOON_WINDOW and WEBHOOK_DELIVERY_INTERVAL must be positive")
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("LOG_LEVEL must be debug, info, warn or error (got %q)", c.Logging.Level)
+	}
+	if c.Logging.Format != "json" && c.Logging.Format != "text" {
+		fail("LOG_FORMAT must be json or text (got %q)", c.Logging.Format)
+	}
+
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: %q is not an http(s) URL", c.Tracing.Endpoint)
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			fail("TRACING_SAMPLE_RATIO must be between 0 and 1")
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, p := range c.OIDC.Providers {
+		if p.Name == "" {
+			fail("oidc provider without a name")
+			continue
+		}
+		if seen[p.Name] {
+			fail("oidc provider %q is defined twice", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			fail("oidc provider %q: issuer, client id and redirect url are required", p.Name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// rules rule ทั้งหมดตามชื่อที่ใช้ใน env
+func (r *RateLimitConfig) rules() map[string]*RateLimitRule {
+	return map[string]*RateLimitRule{
+		"LOGIN":           &r.Login,
+		"LOGIN_2FA":       &r.LoginTwoFactor,
+		"FORGOT_PASSWORD": &r.ForgotPassword,
+		"RESET_PASSWORD":  &r.ResetPassword,
+		"REGISTER":        &r.Register,
+		"OIDC":            &r.OIDC,
+	}
+}
+
+// envReader อ่าน env ทีละตัวแล้วเก็บ error ของค่าที่ parse ไม่ได้ไว้รายงานพร้อมกัน
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		*dst = v
+	}
+}
+
+// list ค่าคั่นด้วย comma
+func (e *envReader) list(key string, dst *[]string) {
+	v := os.Getenv(key)
+	if strings.TrimSpace(v) == "" {
+		return
+	}
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func (e *envReader) int64(key string, dst *int64) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("config: %s: %q is not an integer", key, v))
+		return
+	}
+	*dst = n
+}
+
+// duration รูปแบบของ time.ParseDuration เช่น "5m", "24h"
+func (e *envReader) duration(key string, dst *time.Duration) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("config: %s: %q is not a duration like 5m", key, v))
+		return
+	}
+	*dst = d
+}
+
+// rateLimit รูปแบบ requests/period เช่น "20/1m" (0 = ไม่จำกัด)
+func (e *envReader) rateLimit(key string, dst *RateLimit) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	if v == "0" {
+		*dst = RateLimit{}
+		return
+	}
+	n, per, _ := strings.Cut(v, "/")
+	requests, err := strconv.Atoi(n)
+	d, derr := time.ParseDuration(per)
+	if err != nil || derr != nil {
+		e.errs = append(e.errs, fmt.Errorf("config: %s: %q is not a limit like 20/1m", key, v))
+		return
+	}
+	*dst = RateLimit{Requests: requests, Per: d}
+}
+
+func (e *envReader) float64(key string, dst *float64) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("config: %s: %q is not a number", key, v))
+		return
+	}
+	*dst = f
+}
+
+func (e *envReader) boolean(key string, dst *bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("config: %s: %q is not true or false", key, v))
+		return
+	}
+	*dst = b
+}
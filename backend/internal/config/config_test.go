@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	c := Default()
+	c.Mongo.URI = "mongodb://localhost:27017"
+	c.Mongo.Database = "test"
+	return c
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	c := validConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_LOGIN_PER_IP", "100/30s")
+	t.Setenv("RATE_LIMIT_FORGOT_PASSWORD_PER_ACCOUNT", "0")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+	t.Setenv("SHUTDOWN_DRAIN_DELAY", "7s")
+
+	c := validConfig()
+	if err := c.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.RateLimit.Login.PerIP; got != (RateLimit{Requests: 100, Per: 30 * time.Second}) {
+		t.Errorf("login per ip = %+v", got)
+	}
+	if got := c.RateLimit.Login.PerAccount; got != (RateLimit{Requests: 10, Per: 10 * time.Minute}) {
+		t.Errorf("login per account changed to %+v", got)
+	}
+	if got := c.RateLimit.ForgotPassword.PerAccount; got != (RateLimit{}) {
+		t.Errorf("forgot password per account = %+v, want disabled", got)
+	}
+	if c.Server.DrainDelay != 7*time.Second {
+		t.Errorf("drain delay = %v", c.Server.DrainDelay)
+	}
+	if len(c.Server.TrustedProxies) != 2 || c.Server.TrustedProxies[1] != "192.168.1.10" {
+		t.Errorf("trusted proxies = %v", c.Server.TrustedProxies)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitEnvMalformed(t *testing.T) {
+	t.Setenv("RATE_LIMIT_REGISTER_PER_IP", "ten per hour")
+	c := validConfig()
+	if err := c.loadEnv(); err == nil || !strings.Contains(err.Error(), "RATE_LIMIT_REGISTER_PER_IP") {
+		t.Fatalf("loadEnv = %v", err)
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := map[string]func(c *Config){
+		"negative requests":  func(c *Config) { c.RateLimit.Login.PerIP.Requests = -1 },
+		"zero period":        func(c *Config) { c.RateLimit.OIDC.PerIP = RateLimit{Requests: 5} },
+		"bad trusted proxy":  func(c *Config) { c.Server.TrustedProxies = []string{"lb.internal"} },
+		"bad trusted subnet": func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/33"} },
+		"negative drain":     func(c *Config) { c.Server.DrainDelay = -time.Second },
+	}
+	for name, mutate := range tests {
+		c := validConfig()
+		mutate(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+// ไฟล์ตัวอย่างต้องโหลดได้ (KnownFields ทำให้ key ที่พิมพ์ผิดเป็น error)
+func TestExampleFileLoads(t *testing.T) {
+	c := validConfig()
+	path, err := filepath.Abs("../../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Skip(err)
+	}
+	if err := c.loadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.RateLimit.Login.PerAccount != (RateLimit{Requests: 10, Per: 10 * time.Minute}) {
+		t.Errorf("login per account = %+v", c.RateLimit.Login.PerAccount)
+	}
+	if c.Server.DrainDelay != 5*time.Second {
+		t.Errorf("drain delay = %v", c.Server.DrainDelay)
+	}
+}
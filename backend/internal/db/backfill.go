@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/models"
+)
+
+// BackfillCompletedAt ใส่ completedAt ให้ task ที่อยู่ใน column หมวด done มาก่อนจะมีการ stamp completedAt
+// ไม่รู้เวลาที่เสร็จจริงจึงใช้ createdAt (ไม่ไปโผล่เป็น throughput ของสัปดาห์ที่ deploy) ทำซ้ำได้ไม่มีผลเพิ่ม
+func BackfillCompletedAt(ctx context.Context) error {
+	cur, err := Database.Collection("columns").Find(ctx, bson.M{"category": models.ColumnDone})
+	if err != nil {
+		return err
+	}
+	var columns []models.Column
+	if err := cur.All(ctx, &columns); err != nil {
+		return err
+	}
+	var doneColumns []primitive.ObjectID
+	for _, col := range columns {
+		doneColumns = append(doneColumns, col.ID)
+	}
+	if len(doneColumns) == 0 {
+		return nil
+	}
+
+	_, err = Database.Collection("tasks").UpdateMany(ctx, bson.M{
+		"columnId":    bson.M{"$in": doneColumns},
+		"completedAt": bson.M{"$exists": false},
+	}, bson.A{bson.M{"$set": bson.M{"completedAt": "$createdAt"}}})
+	return err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes สร้าง index ที่ collection ต่าง ๆ ต้องใช้ (เรียกตอน start server)
+func EnsureIndexes(ctx context.Context) error {
+	_, err := Database.Collection("personalAccessTokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = Database.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "oidcIdentities.provider", Value: 1}, {Key: "oidcIdentities.subject", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = Database.Collection("attachments").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "taskId", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// due-reminders หา task ตามช่วง dueDate ทุกรอบ
+	_, err = Database.Collection("tasks").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "dueDate", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// ตัวนับ login ผิดจะหายเองหลังไม่มีการผิดซ้ำนานพอ
+	_, err = Database.Collection("loginFailures").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	// state ของ OIDC login ที่ไม่ถูกใช้จะถูกลบเองเมื่อหมดอายุ
+	_, err = Database.Collection("oidcLoginStates").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
@@ -3,7 +3,6 @@ package db
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,19 +12,25 @@ import (
 var Client *mongo.Client
 var Database *mongo.Database
 
-func Connect() {
-	uri := os.Getenv("MONGODB_URI")
-	dbName := os.Getenv("MONGODB_DB")
-	if uri == "" || dbName == "" {
-		log.Fatal("MONGODB_URI or MONGODB_DB not set")
-	}
+// Connect ต่อ MongoDB แล้วตั้ง Client / Database
+func Connect(uri, dbName string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	// จับเวลาทุกคำสั่งไว้ให้ /metrics และสร้าง span เมื่อเปิด tracing
+	opts := options.Client().ApplyURI(uri).SetMonitor(commandMonitor())
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		log.Fatal(err)
 	}
 	Client = client
 	Database = client.Database(dbName)
 }
+
+// Disconnect ปิด connection ทั้งหมดของ Client (เรียกตอนปิด server หลังหยุดงานที่ใช้ DB แล้ว)
+func Disconnect(ctx context.Context) error {
+	if Client == nil {
+		return nil
+	}
+	return Client.Disconnect(ctx)
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"mini-taskmgr-backend/internal/metrics"
+	"mini-taskmgr-backend/internal/tracing"
+)
+
+// commandMonitor รวม monitor ของ metrics (latency ต่อ collection) และ tracing (span ต่อคำสั่ง)
+func commandMonitor() *event.CommandMonitor {
+	monitors := []*event.CommandMonitor{
+		metrics.MongoMonitor(commandCollection),
+		tracing.MongoMonitor(commandCollection),
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				m.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				m.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				m.Failed(ctx, e)
+			}
+		},
+	}
+}
+
+// commandCollection ชื่อ collection ของคำสั่ง เช่น {find: "tasks"} -> tasks
+// คำสั่งที่ไม่ผูกกับ collection (ping, endSessions, ...) ได้ "none"
+func commandCollection(e *event.CommandStartedEvent) string {
+	key := e.CommandName
+	if key == "getMore" {
+		key = "collection"
+	}
+	if v, err := e.Command.LookupErr(key); err == nil {
+		if s, ok := v.StringValueOK(); ok && s != "" {
+			return s
+		}
+	}
+	return "none"
+}
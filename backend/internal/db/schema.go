@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SchemaVersion เวอร์ชันของ index / โครงสร้างข้อมูลที่ code ชุดนี้ต้องการ
+// เพิ่มเลขทุกครั้งที่แก้ EnsureIndexes (หรือขั้นตอนเตรียม database อื่น ๆ ตอน start)
+const SchemaVersion = 3
+
+// RecordSchemaVersion บันทึกว่าเตรียม database ของ SchemaVersion นี้เสร็จแล้ว
+// ใช้ $max เพื่อไม่ให้ replica รุ่นเก่าระหว่าง rolling deploy ลดเลขลง
+func RecordSchemaVersion(ctx context.Context) error {
+	_, err := Database.Collection("schemaVersion").UpdateByID(ctx, "current", bson.M{
+		"$max": bson.M{"version": SchemaVersion},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// AppliedSchemaVersion เวอร์ชันที่บันทึกไว้ใน database (0 = ยังไม่เคยบันทึก)
+func AppliedSchemaVersion(ctx context.Context) (int, error) {
+	var doc struct {
+		Version int `bson:"version"`
+	}
+	err := Database.Collection("schemaVersion").FindOne(ctx, bson.M{"_id": "current"}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return doc.Version, err
+}
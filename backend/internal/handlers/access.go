@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// projectForBoard หา project ที่เป็นเจ้าของ board
+func projectForBoard(ctx context.Context, boardID primitive.ObjectID) (models.Project, error) {
+	var board models.Board
+	if err := db.Database.Collection("boards").
+		FindOne(ctx, bson.M{"_id": boardID}).
+		Decode(&board); err != nil {
+		return models.Project{}, err
+	}
+
+	var proj models.Project
+	if err := db.Database.Collection("projects").
+		FindOne(ctx, bson.M{"_id": board.ProjectID}).
+		Decode(&proj); err != nil {
+		return models.Project{}, err
+	}
+	return proj, nil
+}
+
+// projectForTask โหลด task พร้อม project ของมัน (task -> board -> project)
+func projectForTask(ctx context.Context, taskID primitive.ObjectID) (models.Task, models.Project, error) {
+	var task models.Task
+	if err := db.Database.Collection("tasks").
+		FindOne(ctx, bson.M{"_id": taskID}).
+		Decode(&task); err != nil {
+		return models.Task{}, models.Project{}, err
+	}
+
+	proj, err := projectForBoard(ctx, task.BoardID)
+	if err != nil {
+		return models.Task{}, models.Project{}, err
+	}
+	return task, proj, nil
+}
+
+// boardIDsForProject คืน id ของทุก board ในโปรเจกต์
+func boardIDsForProject(ctx context.Context, projectID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cur, err := db.Database.Collection("boards").Find(ctx, bson.M{"projectId": projectID})
+	if err != nil {
+		return nil, err
+	}
+	var boards []models.Board
+	if err := cur.All(ctx, &boards); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(boards))
+	for _, b := range boards {
+		ids = append(ids, b.ID)
+	}
+	return ids, nil
+}
+
+// isProjectMember เช็คว่า user เป็นเจ้าของหรือสมาชิกของโปรเจกต์
+func isProjectMember(proj models.Project, userID primitive.ObjectID) bool {
+	if proj.OwnerID == userID {
+		return true
+	}
+	for _, m := range proj.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// isProjectAdmin เช็คว่า user เป็นเจ้าของ หรือเป็นสมาชิก role ADMIN ของโปรเจกต์
+func isProjectAdmin(proj models.Project, userID primitive.ObjectID) bool {
+	if proj.OwnerID == userID {
+		return true
+	}
+	for _, m := range proj.Members {
+		if m.UserID == userID && m.Role == models.RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// errOwnerChanged เจ้าของโปรเจกต์ถูกเปลี่ยนไประหว่างที่กำลังโอน
+var errOwnerChanged = errors.New("project owner changed, please retry")
+
+// transferProjectOwnership โอนโปรเจกต์ให้ newOwnerID
+// เจ้าของใหม่จะเป็นสมาชิก role ADMIN (เพิ่มให้ถ้ายังไม่เป็นสมาชิก) ส่วนเจ้าของเดิมยังอยู่ในโปรเจกต์ในฐานะ ADMIN
+func transferProjectOwnership(ctx context.Context, proj models.Project, newOwnerID primitive.ObjectID) error {
+	members := make([]models.ProjectMember, 0, len(proj.Members)+2)
+	hasNew, hasOld := false, false
+	for _, m := range proj.Members {
+		switch m.UserID {
+		case newOwnerID:
+			m.Role = models.RoleAdmin
+			hasNew = true
+		case proj.OwnerID:
+			m.Role = models.RoleAdmin
+			hasOld = true
+		}
+		members = append(members, m)
+	}
+	if !hasNew {
+		members = append(members, models.ProjectMember{UserID: newOwnerID, Role: models.RoleAdmin})
+	}
+	if !hasOld && proj.OwnerID != newOwnerID {
+		members = append(members, models.ProjectMember{UserID: proj.OwnerID, Role: models.RoleAdmin})
+	}
+
+	res, err := db.Database.Collection("projects").UpdateOne(ctx,
+		bson.M{"_id": proj.ID, "ownerId": proj.OwnerID},
+		bumpVersion(bson.M{"$set": bson.M{
+			"ownerId":   newOwnerID,
+			"members":   members,
+			"updatedAt": time.Now(),
+		}}),
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errOwnerChanged
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// ExportMyData GET /me/export
+// ส่ง ZIP ข้อมูลส่วนตัวทั้งหมดของ user: profile, projects, tasks ที่สร้างหรือถูก assign,
+// comments, activity, notifications และข้อมูลของ personal access token (ไม่รวม secret)
+func ExportMyData(c *gin.Context) {
+	sub := c.MustGet("userSub").(string)
+	oid, _ := primitive.ObjectIDFromHex(sub)
+
+	ctx, cancel := requestContext(c, 60*time.Second)
+	defer cancel()
+
+	var u models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+
+	// โหลดทุกอย่างก่อนเริ่มเขียน response จะได้ยังตอบ error เป็น JSON ได้
+	files := []struct {
+		name string
+		coll string
+		// filter nil = ใช้ data ที่เตรียมไว้แทนการ query
+		filter bson.M
+		data   interface{}
+	}{
+		{name: "profile.json", data: gin.H{
+			"id":                u.ID.Hex(),
+			"name":              u.Name,
+			"email":             u.Email,
+			"role":              u.Role,
+			"createdAt":         u.CreatedAt,
+			"twoFactorEnabled":  u.TOTPEnabled,
+			"ssoIdentities":     u.OIDCIdentities,
+			"notificationPrefs": u.NotificationPrefs,
+		}},
+		{name: "projects.json", coll: "projects", filter: bson.M{"$or": bson.A{
+			bson.M{"ownerId": oid},
+			bson.M{"members.userId": oid},
+		}}},
+		{name: "tasks.json", coll: "tasks", filter: bson.M{"$or": bson.A{
+			bson.M{"createdById": oid},
+			bson.M{"assignees": oid},
+		}}},
+		{name: "comments.json", coll: "comments", filter: bson.M{"authorId": oid}},
+		{name: "attachments.json", coll: "attachments", filter: bson.M{"uploadedById": oid}},
+		{name: "activity.json", coll: "taskEvents", filter: bson.M{"actorId": oid}},
+		{name: "notifications.json", coll: "notifications", filter: bson.M{"userId": oid}},
+		{name: "personal_access_tokens.json", coll: "personalAccessTokens", filter: bson.M{"userId": oid}},
+	}
+
+	for i := range files {
+		f := &files[i]
+		if f.filter == nil {
+			continue
+		}
+		docs, err := exportCollection(ctx, f.coll, f.filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "EXPORT: load failed", "collection", f.coll, "err", err)
+			apierror.Abort(c, apierror.Internal("export failed", err))
+			return
+		}
+		f.data = docs
+	}
+
+	filename := "export-" + u.ID.Hex() + "-" + time.Now().UTC().Format("20060102") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			slog.ErrorContext(ctx, "EXPORT: zip error", "err", err)
+			return
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			slog.ErrorContext(ctx, "EXPORT: encode failed", "file", f.name, "err", err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		slog.ErrorContext(ctx, "EXPORT: zip close error", "err", err)
+	}
+}
+
+// exportCollection โหลด document เป็น extended JSON แบบ relaxed (ObjectID / วันที่อ่านได้)
+// และตัด field ที่เป็น secret ออก
+func exportCollection(ctx context.Context, coll string, filter bson.M) ([]json.RawMessage, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"tokenHash": 0, "secret": 0, "dedupeKey": 0, "storageKey": 0})
+	cur, err := db.Database.Collection(coll).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	docs := []json.RawMessage{}
+	for cur.Next(ctx) {
+		b, err := bson.MarshalExtJSON(cur.Current, false, false)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, b)
+	}
+	return docs, cur.Err()
+}
+
+// anonymizeUserReferences ให้ task / comment / activity ที่อ้างถึง userID ชี้ไปที่ "Deleted user" แทน
+// งานที่ทำร่วมกับคนอื่นจึงยังอยู่ครบ แต่ไม่เหลือข้อมูลที่ระบุตัวตนได้ (task ที่ถูกแก้จะได้ version ใหม่)
+func anonymizeUserReferences(ctx context.Context, userID primitive.ObjectID) error {
+	deleted := models.DeletedUserID
+	if _, err := db.Database.Collection("users").UpdateByID(ctx, deleted, bson.M{
+		"$setOnInsert": bson.M{
+			"name":         models.DeletedUserName,
+			"email":        "deleted-user@invalid",
+			"passwordHash": "",
+			"role":         models.RoleViewer,
+			"disabled":     true,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	// field เดี่ยว: เปลี่ยน id ตรง ๆ
+	for _, ref := range []struct{ coll, field string }{
+		{"tasks", "createdById"},
+		{"comments", "authorId"},
+		{"taskEvents", "actorId"},
+		{"taskDependencies", "createdById"},
+		{"webhooks", "createdById"},
+		{"attachments", "uploadedById"},
+	} {
+		update := bson.M{"$set": bson.M{ref.field: deleted}}
+		if ref.coll == "tasks" {
+			bumpVersion(update)
+		}
+		if _, err := db.Database.Collection(ref.coll).UpdateMany(ctx, bson.M{ref.field: userID}, update); err != nil {
+			return err
+		}
+	}
+
+	// array: ใส่ "Deleted user" แทนแล้วเอา id เดิมออก
+	for _, ref := range []struct{ coll, field string }{
+		{"tasks", "assignees"},
+		{"comments", "mentions"},
+	} {
+		col := db.Database.Collection(ref.coll)
+		if _, err := col.UpdateMany(ctx,
+			bson.M{ref.field: userID},
+			bson.M{"$addToSet": bson.M{ref.field: deleted}},
+		); err != nil {
+			return err
+		}
+		pull := bson.M{"$pull": bson.M{ref.field: userID}}
+		if ref.coll == "tasks" {
+			bumpVersion(pull)
+		}
+		if _, err := col.UpdateMany(ctx, bson.M{ref.field: userID}, pull); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePersonalData ลบข้อมูลที่เป็นของ user คนเดียว (ไม่กระทบงานของคนอื่น)
+func deletePersonalData(ctx context.Context, userID primitive.ObjectID) {
+	for _, coll := range []string{"notifications", "personalAccessTokens", "passwordResetTokens"} {
+		if _, err := db.Database.Collection(coll).DeleteMany(ctx, bson.M{"userId": userID}); err != nil {
+			slog.ErrorContext(ctx, "DELETE_ACCOUNT: delete failed", "collection", coll, "err", err)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// AdminUserResponse ข้อมูล user ที่ admin เห็น
+type AdminUserResponse struct {
+	ID                string      `json:"id"`
+	Name              string      `json:"name"`
+	Email             string      `json:"email"`
+	Role              models.Role `json:"role"`
+	Disabled          bool        `json:"disabled"`
+	MustResetPassword bool        `json:"mustResetPassword"`
+	TwoFactorEnabled  bool        `json:"twoFactorEnabled"`
+	SSO               bool        `json:"sso"`
+	CreatedAt         *time.Time  `json:"createdAt,omitempty"`
+}
+
+func adminUserResponse(u models.User) AdminUserResponse {
+	r := AdminUserResponse{
+		ID:                u.ID.Hex(),
+		Name:              u.Name,
+		Email:             u.Email,
+		Role:              u.Role,
+		Disabled:          u.Disabled,
+		MustResetPassword: u.MustResetPassword,
+		TwoFactorEnabled:  u.TOTPEnabled,
+		SSO:               len(u.OIDCIdentities) > 0,
+	}
+	if !u.CreatedAt.IsZero() {
+		r.CreatedAt = &u.CreatedAt
+	}
+	return r
+}
+
+// AdminUserListResponse ผลของ GET /admin/users
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+}
+
+// AdminUpdateRoleRequest body ของ PATCH /admin/users/:id/role
+type AdminUpdateRoleRequest struct {
+	Role models.Role `json:"role" binding:"required"`
+}
+
+// AdminPasswordResetResponse ผลของ POST /admin/users/:id/force-password-reset
+type AdminPasswordResetResponse struct {
+	Message    string `json:"message"`
+	ResetToken string `json:"resetToken"`
+}
+
+// AdminStatsResponse ผลของ GET /admin/stats
+type AdminStatsResponse struct {
+	Users struct {
+		Total    int64 `json:"total"`
+		Admins   int64 `json:"admins"`
+		Disabled int64 `json:"disabled"`
+	} `json:"users"`
+	Projects struct {
+		Total int64 `json:"total"`
+	} `json:"projects"`
+	Tasks struct {
+		Total     int64 `json:"total"`
+		Open      int64 `json:"open"`
+		Completed int64 `json:"completed"`
+	} `json:"tasks"`
+}
+
+// AdminListUsers GET /admin/users?q=&role=&disabled=&limit=&skip=
+// q ค้นหาจากชื่อหรือ email (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+func AdminListUsers(c *gin.Context) {
+	filter := bson.M{}
+	if q := c.Query("q"); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"name": pattern},
+			bson.M{"email": pattern},
+		}
+	}
+	if role := c.Query("role"); role != "" {
+		filter["role"] = role
+	}
+	switch c.Query("disabled") {
+	case "true":
+		filter["disabled"] = true
+	case "false":
+		filter["disabled"] = bson.M{"$ne": true}
+	}
+
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 64)
+	if err != nil || limit < 1 || limit > 200 {
+		apierror.Abort(c, apierror.Invalid("limit", "range", "limit must be between 1 and 200"))
+		return
+	}
+	skip, err := strconv.ParseInt(c.DefaultQuery("skip", "0"), 10, 64)
+	if err != nil || skip < 0 {
+		apierror.Abort(c, apierror.Invalid("skip", "min", "invalid skip"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 10*time.Second)
+	defer cancel()
+
+	col := db.Database.Collection("users")
+	total, err := col.CountDocuments(ctx, filter)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	cur, err := col.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "email", Value: 1}}).
+		SetSkip(skip).
+		SetLimit(limit))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	var users []models.User
+	if err := cur.All(ctx, &users); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+
+	results := make([]AdminUserResponse, 0, len(users))
+	for _, u := range users {
+		results = append(results, adminUserResponse(u))
+	}
+	c.JSON(http.StatusOK, AdminUserListResponse{Users: results, Total: total})
+}
+
+// AdminDisableUser POST /admin/users/:id/disable
+// ปิดบัญชี: login ไม่ได้ และ token / PAT ที่มีอยู่ใช้ไม่ได้ทันที
+func AdminDisableUser(c *gin.Context) {
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	u, ok := loadAdminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+	if u.ID.Hex() == c.GetString("userSub") {
+		apierror.Abort(c, apierror.ErrSelfAdminChange.WithDetail("you cannot disable your own account"))
+		return
+	}
+	if u.Role == models.RoleAdmin {
+		if !ensureOtherAdmin(ctx, c, u.ID) {
+			return
+		}
+	}
+
+	now := time.Now()
+	if _, err := db.Database.Collection("users").UpdateByID(ctx, u.ID, bson.M{
+		"$set": bson.M{"disabled": true, "disabledAt": now},
+	}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	u.Disabled = true
+	c.JSON(http.StatusOK, adminUserResponse(u))
+}
+
+// AdminEnableUser POST /admin/users/:id/enable
+func AdminEnableUser(c *gin.Context) {
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	u, ok := loadAdminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+	if _, err := db.Database.Collection("users").UpdateByID(ctx, u.ID, bson.M{
+		"$unset": bson.M{"disabled": "", "disabledAt": ""},
+	}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	u.Disabled = false
+	c.JSON(http.StatusOK, adminUserResponse(u))
+}
+
+// AdminForcePasswordReset POST /admin/users/:id/force-password-reset
+// บังคับตั้งรหัสผ่านใหม่: token / PAT ที่มีอยู่ถูกเพิกถอน และ login ด้วยรหัสผ่านเดิมไม่ได้จนกว่าจะ reset
+// reset token จะถูกส่งกลับให้ admin แบบเดียวกับ ForgotPassword
+func AdminForcePasswordReset(c *gin.Context) {
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	u, ok := loadAdminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if _, err := db.Database.Collection("users").UpdateByID(ctx, u.ID, bson.M{
+		"$set": bson.M{"mustResetPassword": true, "tokensValidAfter": now},
+	}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	if _, err := db.Database.Collection("personalAccessTokens").UpdateMany(ctx,
+		bson.M{"userId": u.ID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": now}},
+	); err != nil {
+		slog.ErrorContext(ctx, "ADMIN: revoke tokens error", "err", err)
+	}
+
+	resetToken, err := issuePasswordResetToken(ctx, u)
+	if err != nil {
+		slog.ErrorContext(ctx, "ADMIN: issue reset token error", "err", err)
+		apierror.Abort(c, apierror.Internal("could not generate reset token", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, AdminPasswordResetResponse{Message: "password reset required", ResetToken: resetToken})
+}
+
+// AdminUpdateUserRole PATCH /admin/users/:id/role
+func AdminUpdateUserRole(c *gin.Context) {
+	var input AdminUpdateRoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	switch input.Role {
+	case models.RoleAdmin, models.RoleMember, models.RoleViewer:
+	default:
+		apierror.Abort(c, apierror.Invalid("role", "oneof", "role must be ADMIN, MEMBER or VIEWER"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	u, ok := loadAdminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+	if u.Role == models.RoleAdmin && input.Role != models.RoleAdmin {
+		if u.ID.Hex() == c.GetString("userSub") {
+			apierror.Abort(c, apierror.ErrSelfAdminChange.WithDetail("you cannot remove your own admin role"))
+			return
+		}
+		if !ensureOtherAdmin(ctx, c, u.ID) {
+			return
+		}
+	}
+
+	if _, err := db.Database.Collection("users").UpdateByID(ctx, u.ID, bson.M{
+		"$set": bson.M{"role": input.Role},
+	}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	u.Role = input.Role
+	c.JSON(http.StatusOK, adminUserResponse(u))
+}
+
+// AdminTransferProject POST /admin/projects/:id/transfer
+// โอนโปรเจกต์ให้ user อื่น (เช่นเมื่อเจ้าของเดิมออกจากองค์กร)
+func AdminTransferProject(c *gin.Context) {
+	projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+	var input TransferProjectRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	newOwnerID, err := primitive.ObjectIDFromHex(input.NewOwnerID)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("newOwnerId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": projectID}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return
+	}
+	if proj.OwnerID == newOwnerID {
+		apierror.Abort(c, apierror.ErrAlreadyOwner)
+		return
+	}
+
+	var newOwner models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": newOwnerID}).Decode(&newOwner); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound.WithDetail("new owner not found")))
+		return
+	}
+	if newOwner.Disabled {
+		apierror.Abort(c, apierror.ErrNewOwnerDisabled)
+		return
+	}
+
+	if err := transferProjectOwnership(ctx, proj, newOwnerID); err != nil {
+		if errors.Is(err, errOwnerChanged) {
+			apierror.Abort(c, apierror.ErrOwnerChanged)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("transfer failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, TransferProjectResponse{Message: "project transferred", OwnerID: newOwnerID.Hex()})
+}
+
+// AdminStats GET /admin/stats
+func AdminStats(c *gin.Context) {
+	ctx, cancel := requestContext(c, 10*time.Second)
+	defer cancel()
+
+	var users, admins, disabled, projects, tasks, openTasks int64
+	type count struct {
+		coll   string
+		filter bson.M
+		dst    *int64
+	}
+	for _, q := range []count{
+		{"users", bson.M{}, &users},
+		{"users", bson.M{"role": models.RoleAdmin}, &admins},
+		{"users", bson.M{"disabled": true}, &disabled},
+		{"projects", bson.M{}, &projects},
+		{"tasks", bson.M{}, &tasks},
+		{"tasks", bson.M{"completedAt": bson.M{"$exists": false}}, &openTasks},
+	} {
+		n, err := db.Database.Collection(q.coll).CountDocuments(ctx, q.filter)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("db error", err))
+			return
+		}
+		*q.dst = n
+	}
+
+	var out AdminStatsResponse
+	out.Users.Total, out.Users.Admins, out.Users.Disabled = users, admins, disabled
+	out.Projects.Total = projects
+	out.Tasks.Total, out.Tasks.Open, out.Tasks.Completed = tasks, openTasks, tasks-openTasks
+	c.JSON(http.StatusOK, out)
+}
+
+// loadAdminTargetUser โหลด user จาก :id ถ้าไม่เจอจะตอบ error ให้แล้ว
+func loadAdminTargetUser(ctx context.Context, c *gin.Context) (models.User, bool) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("userId"))
+		return models.User{}, false
+	}
+	var u models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return models.User{}, false
+	}
+	return u, true
+}
+
+// ensureOtherAdmin กันไม่ให้ระบบเหลือ admin ที่ใช้งานได้เป็นศูนย์
+func ensureOtherAdmin(ctx context.Context, c *gin.Context, userID primitive.ObjectID) bool {
+	n, err := db.Database.Collection("users").CountDocuments(ctx, bson.M{
+		"_id":      bson.M{"$ne": userID},
+		"role":     models.RoleAdmin,
+		"disabled": bson.M{"$ne": true},
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return false
+	}
+	if n == 0 {
+		apierror.Abort(c, apierror.ErrLastAdmin)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+const analyticsMaxDays = 366
+
+// CumulativeFlowDay จำนวน task ในแต่ละ column ณ สิ้นวัน
+type CumulativeFlowDay struct {
+	Date   string           `json:"date"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// DurationStats percentile ของระยะเวลา (หน่วยชั่วโมง)
+type DurationStats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P85   float64 `json:"p85"`
+	P95   float64 `json:"p95"`
+}
+
+// ThroughputWeek จำนวน task ที่เสร็จในสัปดาห์ (เริ่มวันจันทร์)
+type ThroughputWeek struct {
+	WeekStart string `json:"weekStart"`
+	Completed int64  `json:"completed"`
+}
+
+// WipBreach จำนวนวันที่ column มี task เกิน wipLimit
+type WipBreach struct {
+	ColumnID   string `json:"columnId"`
+	Name       string `json:"name"`
+	WipLimit   int    `json:"wipLimit"`
+	BreachDays int    `json:"breachDays"`
+}
+
+// AnalyticsColumn column ของ board ที่ใช้อ้างอิง id ใน counts / wipBreaches
+type AnalyticsColumn struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Category models.ColumnCategory `json:"category"`
+	WipLimit *int                  `json:"wipLimit"`
+}
+
+// ProjectAnalyticsResponse ผลของ GET /projects/:id/analytics
+type ProjectAnalyticsResponse struct {
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Columns        []AnalyticsColumn   `json:"columns"`
+	CumulativeFlow []CumulativeFlowDay `json:"cumulativeFlow"`
+	CycleTime      DurationStats       `json:"cycleTime"`
+	LeadTime       DurationStats       `json:"leadTime"`
+	Throughput     []ThroughputWeek    `json:"throughput"`
+	WipBreaches    []WipBreach         `json:"wipBreaches"`
+}
+
+// columnDayState คือผลจาก aggregation: column สุดท้ายของ task ในแต่ละวัน
+type columnDayState struct {
+	TaskID   primitive.ObjectID   `bson:"taskId"`
+	Day      time.Time            `bson:"day"`
+	Type     models.TaskEventType `bson:"type"`
+	ColumnID *primitive.ObjectID  `bson:"columnId"`
+}
+
+// GetProjectAnalytics GET /projects/:id/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GetProjectAnalytics(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	pid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+
+	from, to, err := analyticsRange(c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	ctx, cancel := requestContext(c, 10*time.Second)
+	defer cancel()
+
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": pid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to view this project"))
+		return
+	}
+
+	boardIDs, err := boardIDsForProject(ctx, pid)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+
+	colCur, err := db.Database.Collection("columns").Find(ctx, bson.M{"boardId": bson.M{"$in": boardIDs}})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("cannot load columns", err))
+		return
+	}
+	var columns []models.Column
+	if err := colCur.All(ctx, &columns); err != nil {
+		apierror.Abort(c, apierror.Internal("cannot decode columns", err))
+		return
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Position < columns[j].Position })
+
+	states, err := loadColumnDayStates(ctx, boardIDs, to)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("cannot aggregate task history", err))
+		return
+	}
+	flow := cumulativeFlow(states, from, to)
+
+	cycle, lead, err := loadDurationStats(ctx, boardIDs, from, to)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("cannot aggregate cycle time", err))
+		return
+	}
+
+	throughput, err := loadThroughput(ctx, boardIDs, from, to)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("cannot aggregate throughput", err))
+		return
+	}
+
+	columnsOut := []AnalyticsColumn{}
+	for _, col := range columns {
+		columnsOut = append(columnsOut, AnalyticsColumn{
+			ID:       col.ID.Hex(),
+			Name:     col.Name,
+			Category: columnCategoryOf(col.Category),
+			WipLimit: col.WipLimit,
+		})
+	}
+
+	c.JSON(http.StatusOK, ProjectAnalyticsResponse{
+		From:           from.Format("2006-01-02"),
+		To:             to.Format("2006-01-02"),
+		Columns:        columnsOut,
+		CumulativeFlow: flow,
+		CycleTime:      cycle,
+		LeadTime:       lead,
+		Throughput:     throughput,
+		WipBreaches:    wipBreaches(columns, flow),
+	})
+}
+
+// analyticsRange แปลง from/to (วันที่แบบ UTC) ค่าเริ่มต้นคือย้อนหลัง 30 วัน
+func analyticsRange(fromStr, toStr string, now time.Time) (time.Time, time.Time, error) {
+	to := truncateDay(now)
+	if toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, apierror.Invalid("to", "datetime", "to must be a date in YYYY-MM-DD format")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -29)
+	if fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, apierror.Invalid("from", "datetime", "from must be a date in YYYY-MM-DD format")
+		}
+		from = f
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, apierror.Invalid("from", "range", "from must be before to")
+	}
+	if to.Sub(from) > analyticsMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, apierror.Invalid("from", "range", "range must be at most 366 days")
+	}
+	return from, to, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// loadColumnDayStates ใช้ aggregation หา column สุดท้ายของแต่ละ task ในแต่ละวัน (จนถึงวัน to)
+func loadColumnDayStates(ctx context.Context, boardIDs []primitive.ObjectID, to time.Time) ([]columnDayState, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"boardId": bson.M{"$in": boardIDs},
+			"at":      bson.M{"$lt": to.AddDate(0, 0, 1)},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"taskId": "$taskId",
+				"day":    bson.M{"$dateTrunc": bson.M{"date": "$at", "unit": "day", "timezone": "UTC"}},
+			},
+			"type":     bson.M{"$last": "$type"},
+			"columnId": bson.M{"$last": "$toColumnId"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"taskId":   "$_id.taskId",
+			"day":      "$_id.day",
+			"type":     1,
+			"columnId": 1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "day", Value: 1}}}},
+	}
+
+	cur, err := db.Database.Collection("taskEvents").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var states []columnDayState
+	if err := cur.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// cumulativeFlow ไล่ state ของ task ทีละวัน แล้วนับจำนวน task ในแต่ละ column ณ สิ้นวัน
+// states ต้องเรียงตาม day
+func cumulativeFlow(states []columnDayState, from, to time.Time) []CumulativeFlowDay {
+	current := map[primitive.ObjectID]primitive.ObjectID{}
+	apply := func(s columnDayState) {
+		if s.Type == models.TaskEventDeleted || s.ColumnID == nil {
+			delete(current, s.TaskID)
+			return
+		}
+		current[s.TaskID] = *s.ColumnID
+	}
+
+	i := 0
+	days := []CumulativeFlowDay{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for i < len(states) && !states[i].Day.After(day) {
+			apply(states[i])
+			i++
+		}
+		counts := map[string]int64{}
+		for _, colID := range current {
+			counts[colID.Hex()]++
+		}
+		days = append(days, CumulativeFlowDay{Date: day.Format("2006-01-02"), Counts: counts})
+	}
+	return days
+}
+
+// loadDurationStats ใช้ aggregation คำนวณ cycle time (startedAt -> completedAt)
+// และ lead time (createdAt -> completedAt) ของ task ที่เสร็จในช่วงเวลา
+func loadDurationStats(ctx context.Context, boardIDs []primitive.ObjectID, from, to time.Time) (DurationStats, DurationStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"boardId":     bson.M{"$in": boardIDs},
+			"completedAt": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id": 0,
+			"cycleMs": bson.M{"$cond": bson.A{
+				bson.M{"$ifNull": bson.A{"$startedAt", false}},
+				bson.M{"$subtract": bson.A{"$completedAt", "$startedAt"}},
+				nil,
+			}},
+			"leadMs": bson.M{"$cond": bson.A{
+				bson.M{"$ifNull": bson.A{"$createdAt", false}},
+				bson.M{"$subtract": bson.A{"$completedAt", "$createdAt"}},
+				nil,
+			}},
+		}}},
+	}
+
+	cur, err := db.Database.Collection("tasks").Aggregate(ctx, pipeline)
+	if err != nil {
+		return DurationStats{}, DurationStats{}, err
+	}
+	var rows []struct {
+		CycleMs *int64 `bson:"cycleMs"`
+		LeadMs  *int64 `bson:"leadMs"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return DurationStats{}, DurationStats{}, err
+	}
+
+	var cycle, lead []float64
+	for _, r := range rows {
+		if r.CycleMs != nil && *r.CycleMs >= 0 {
+			cycle = append(cycle, float64(*r.CycleMs)/float64(time.Hour/time.Millisecond))
+		}
+		if r.LeadMs != nil && *r.LeadMs >= 0 {
+			lead = append(lead, float64(*r.LeadMs)/float64(time.Hour/time.Millisecond))
+		}
+	}
+	return durationStats(cycle), durationStats(lead), nil
+}
+
+func durationStats(hours []float64) DurationStats {
+	sort.Float64s(hours)
+	return DurationStats{
+		Count: len(hours),
+		P50:   percentile(hours, 50),
+		P85:   percentile(hours, 85),
+		P95:   percentile(hours, 95),
+	}
+}
+
+// percentile แบบ nearest-rank, sorted ต้องเรียงจากน้อยไปมาก
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	v := sorted[rank-1]
+	return math.Round(v*100) / 100
+}
+
+// loadThroughput นับ task ที่เสร็จในแต่ละสัปดาห์ (สัปดาห์ที่ไม่มีงานเสร็จจะเป็น 0)
+func loadThroughput(ctx context.Context, boardIDs []primitive.ObjectID, from, to time.Time) ([]ThroughputWeek, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"boardId":     bson.M{"$in": boardIDs},
+			"completedAt": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateTrunc": bson.M{
+				"date":        "$completedAt",
+				"unit":        "week",
+				"startOfWeek": "monday",
+				"timezone":    "UTC",
+			}},
+			"completed": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cur, err := db.Database.Collection("tasks").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Week      time.Time `bson:"_id"`
+		Completed int64     `bson:"completed"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	byWeek := map[string]int64{}
+	for _, r := range rows {
+		byWeek[r.Week.UTC().Format("2006-01-02")] = r.Completed
+	}
+	return throughputWeeks(byWeek, from, to), nil
+}
+
+// throughputWeeks เรียงผลรายสัปดาห์ตั้งแต่สัปดาห์ของ from ถึง to (byWeek ใช้วันจันทร์แบบ YYYY-MM-DD เป็น key)
+func throughputWeeks(byWeek map[string]int64, from, to time.Time) []ThroughputWeek {
+	weeks := []ThroughputWeek{}
+	for w := weekStart(from); !w.After(to); w = w.AddDate(0, 0, 7) {
+		key := w.Format("2006-01-02")
+		weeks = append(weeks, ThroughputWeek{WeekStart: key, Completed: byWeek[key]})
+	}
+	return weeks
+}
+
+// weekStart คืนวันจันทร์ของสัปดาห์นั้น
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return truncateDay(day).AddDate(0, 0, -offset)
+}
+
+// wipBreaches นับจำนวนวันที่ column มี task มากกว่า wipLimit
+func wipBreaches(columns []models.Column, flow []CumulativeFlowDay) []WipBreach {
+	out := []WipBreach{}
+	for _, col := range columns {
+		if col.WipLimit == nil {
+			continue
+		}
+		b := WipBreach{ColumnID: col.ID.Hex(), Name: col.Name, WipLimit: *col.WipLimit}
+		for _, day := range flow {
+			if day.Counts[col.ID.Hex()] > int64(*col.WipLimit) {
+				b.BreachDays++
+			}
+		}
+		out = append(out, b)
+	}
+	return out
+}
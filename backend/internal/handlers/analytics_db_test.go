@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/models"
+)
+
+var (
+	boardMain  = oid("640000000000000000000001")
+	boardOther = oid("640000000000000000000002")
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLoadColumnDayStates(t *testing.T) {
+	ctx := useTestDatabase(t)
+	event := func(task, board primitive.ObjectID, typ models.TaskEventType, to *primitive.ObjectID, when string) interface{} {
+		return models.TaskEvent{ID: primitive.NewObjectID(), TaskID: task, BoardID: board, Type: typ, ToColumnID: to, At: at(when)}
+	}
+	seed(t, ctx, "taskEvents",
+		event(taskA, boardMain, models.TaskEventCreated, &colTodo, "2024-01-01T09:00:00Z"),
+		event(taskA, boardMain, models.TaskEventMoved, &colDoing, "2024-01-01T15:00:00Z"), // วันเดียวกัน ใช้อันสุดท้าย
+		event(taskB, boardMain, models.TaskEventCreated, &colTodo, "2024-01-02T08:00:00Z"),
+		event(taskB, boardMain, models.TaskEventDeleted, nil, "2024-01-02T20:00:00Z"),
+		event(taskA, boardMain, models.TaskEventMoved, &colDone, "2024-01-03T10:00:00Z"),
+		event(taskA, boardMain, models.TaskEventMoved, &colTodo, "2024-01-06T01:00:00Z"), // หลัง to
+		event(taskC, boardOther, models.TaskEventCreated, &colTodo, "2024-01-01T10:00:00Z"),
+	)
+
+	got, err := loadColumnDayStates(ctx, []primitive.ObjectID{boardMain}, day("2024-01-05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(got, func(i, j int) bool {
+		if !got[i].Day.Equal(got[j].Day) {
+			return got[i].Day.Before(got[j].Day)
+		}
+		return got[i].TaskID.Hex() < got[j].TaskID.Hex()
+	})
+	for i := range got {
+		got[i].Day = got[i].Day.UTC()
+	}
+
+	want := []columnDayState{
+		{TaskID: taskA, Day: day("2024-01-01"), Type: models.TaskEventMoved, ColumnID: &colDoing},
+		{TaskID: taskB, Day: day("2024-01-02"), Type: models.TaskEventDeleted},
+		{TaskID: taskA, Day: day("2024-01-03"), Type: models.TaskEventMoved, ColumnID: &colDone},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loadColumnDayStates:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoadDurationStats(t *testing.T) {
+	ctx := useTestDatabase(t)
+	task := func(board primitive.ObjectID, created string, started, completed *time.Time) interface{} {
+		return models.Task{ID: primitive.NewObjectID(), BoardID: board, CreatedAt: at(created), StartedAt: started, CompletedAt: completed}
+	}
+	ptr := func(s string) *time.Time { v := at(s); return &v }
+	seed(t, ctx, "tasks",
+		task(boardMain, "2024-01-01T00:00:00Z", ptr("2024-01-01T12:00:00Z"), ptr("2024-01-02T00:00:00Z")), // cycle 12h lead 24h
+		task(boardMain, "2024-01-01T00:00:00Z", nil, ptr("2024-01-03T00:00:00Z")),                         // ไม่เคยเริ่ม: lead 48h
+		task(boardMain, "2023-12-01T00:00:00Z", ptr("2023-12-02T00:00:00Z"), ptr("2023-12-31T00:00:00Z")), // เสร็จก่อน from
+		task(boardMain, "2024-01-01T00:00:00Z", ptr("2024-01-02T00:00:00Z"), nil),                         // ยังไม่เสร็จ
+		task(boardOther, "2024-01-01T00:00:00Z", ptr("2024-01-01T01:00:00Z"), ptr("2024-01-02T00:00:00Z")),
+	)
+
+	cycle, lead, err := loadDurationStats(ctx, []primitive.ObjectID{boardMain}, day("2024-01-01"), day("2024-01-05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (DurationStats{Count: 1, P50: 12, P85: 12, P95: 12}); cycle != want {
+		t.Errorf("cycle = %+v, want %+v", cycle, want)
+	}
+	if want := (DurationStats{Count: 2, P50: 24, P85: 48, P95: 48}); lead != want {
+		t.Errorf("lead = %+v, want %+v", lead, want)
+	}
+}
+
+func TestLoadThroughput(t *testing.T) {
+	ctx := useTestDatabase(t)
+	done := func(board primitive.ObjectID, completed string) interface{} {
+		c := at(completed)
+		return models.Task{ID: primitive.NewObjectID(), BoardID: board, CreatedAt: c.Add(-time.Hour), CompletedAt: &c}
+	}
+	seed(t, ctx, "tasks",
+		done(boardMain, "2024-01-02T10:00:00Z"), // ก่อน from
+		done(boardMain, "2024-01-03T10:00:00Z"),
+		done(boardMain, "2024-01-09T10:00:00Z"),
+		done(boardMain, "2024-01-14T23:00:00Z"), // อาทิตย์ยังเป็นสัปดาห์ของ 01-08
+		done(boardMain, "2024-01-16T20:00:00Z"), // วัน to นับทั้งวัน
+		done(boardMain, "2024-01-17T01:00:00Z"), // หลัง to
+		done(boardOther, "2024-01-09T10:00:00Z"),
+	)
+
+	got, err := loadThroughput(ctx, []primitive.ObjectID{boardMain}, day("2024-01-03"), day("2024-01-16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ThroughputWeek{
+		{WeekStart: "2024-01-01", Completed: 1},
+		{WeekStart: "2024-01-08", Completed: 2},
+		{WeekStart: "2024-01-15", Completed: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loadThroughput:\n got %+v\nwant %+v", got, want)
+	}
+}
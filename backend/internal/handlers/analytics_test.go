@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/models"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func oid(hex string) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+var (
+	colTodo  = oid("650000000000000000000001")
+	colDoing = oid("650000000000000000000002")
+	colDone  = oid("650000000000000000000003")
+
+	taskA = oid("660000000000000000000001")
+	taskB = oid("660000000000000000000002")
+	taskC = oid("660000000000000000000003")
+	taskD = oid("660000000000000000000004")
+)
+
+// fixture ของ state รายวันแบบที่ loadColumnDayStates คืน (เรียงตาม day)
+func flowFixture() []columnDayState {
+	state := func(task primitive.ObjectID, d string, typ models.TaskEventType, col *primitive.ObjectID) columnDayState {
+		return columnDayState{TaskID: task, Day: day(d), Type: typ, ColumnID: col}
+	}
+	return []columnDayState{
+		state(taskD, "2023-12-30", models.TaskEventCreated, &colTodo), // ก่อนช่วงที่ขอ ต้องถูกนับตั้งแต่วันแรก
+		state(taskA, "2024-01-01", models.TaskEventCreated, &colTodo),
+		state(taskB, "2024-01-01", models.TaskEventCreated, &colTodo),
+		state(taskA, "2024-01-02", models.TaskEventMoved, &colDoing),
+		state(taskC, "2024-01-02", models.TaskEventCreated, &colDoing),
+		state(taskB, "2024-01-03", models.TaskEventDeleted, nil),
+		state(taskA, "2024-01-04", models.TaskEventMoved, &colDone),
+	}
+}
+
+func TestCumulativeFlow(t *testing.T) {
+	got := cumulativeFlow(flowFixture(), day("2024-01-01"), day("2024-01-05"))
+
+	todo, doing, done := colTodo.Hex(), colDoing.Hex(), colDone.Hex()
+	want := []CumulativeFlowDay{
+		{Date: "2024-01-01", Counts: map[string]int64{todo: 3}},
+		{Date: "2024-01-02", Counts: map[string]int64{todo: 2, doing: 2}},
+		{Date: "2024-01-03", Counts: map[string]int64{todo: 1, doing: 2}},
+		{Date: "2024-01-04", Counts: map[string]int64{todo: 1, doing: 1, done: 1}},
+		{Date: "2024-01-05", Counts: map[string]int64{todo: 1, doing: 1, done: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("cumulativeFlow:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestCumulativeFlowEmpty(t *testing.T) {
+	got := cumulativeFlow(nil, day("2024-01-01"), day("2024-01-02"))
+	if len(got) != 2 || len(got[0].Counts) != 0 || len(got[1].Counts) != 0 {
+		t.Fatalf("expected two empty days, got %v", got)
+	}
+}
+
+func TestWipBreaches(t *testing.T) {
+	one, two := 1, 2
+	columns := []models.Column{
+		{ID: colTodo, Name: "To do", WipLimit: &two},
+		{ID: colDoing, Name: "Doing", WipLimit: &one},
+		{ID: colDone, Name: "Done"}, // ไม่มี limit ไม่อยู่ในผล
+	}
+	flow := cumulativeFlow(flowFixture(), day("2024-01-01"), day("2024-01-05"))
+
+	got := wipBreaches(columns, flow)
+	want := []WipBreach{
+		{ColumnID: colTodo.Hex(), Name: "To do", WipLimit: 2, BreachDays: 1},
+		{ColumnID: colDoing.Hex(), Name: "Doing", WipLimit: 1, BreachDays: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wipBreaches:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDurationStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours []float64
+		want  DurationStats
+	}{
+		{"empty", nil, DurationStats{}},
+		{"single", []float64{4.25}, DurationStats{Count: 1, P50: 4.25, P85: 4.25, P95: 4.25}},
+		{"unsorted ten", []float64{10, 1, 5, 3, 8, 2, 7, 4, 6, 9}, DurationStats{Count: 10, P50: 5, P85: 9, P95: 10}},
+		{"rounded", []float64{1.23456, 2.34567}, DurationStats{Count: 2, P50: 1.23, P85: 2.35, P95: 2.35}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durationStats(tt.hours); got != tt.want {
+				t.Fatalf("durationStats(%v) = %+v, want %+v", tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{25, 1},
+		{26, 2},
+		{50, 2},
+		{75, 3},
+		{100, 4},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestThroughputWeeks(t *testing.T) {
+	byWeek := map[string]int64{
+		"2024-01-08": 4,
+		"2023-12-25": 9, // ก่อนช่วงที่ขอ
+	}
+	got := throughputWeeks(byWeek, day("2024-01-03"), day("2024-01-16"))
+	want := []ThroughputWeek{
+		{WeekStart: "2024-01-01", Completed: 0},
+		{WeekStart: "2024-01-08", Completed: 4},
+		{WeekStart: "2024-01-15", Completed: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("throughputWeeks:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := map[string]string{
+		"2024-01-01": "2024-01-01", // จันทร์
+		"2024-01-03": "2024-01-01",
+		"2024-01-07": "2024-01-01", // อาทิตย์ยังเป็นสัปดาห์เดิม
+		"2024-01-08": "2024-01-08",
+	}
+	for in, want := range tests {
+		if got := weekStart(day(in)).Format("2006-01-02"); got != want {
+			t.Errorf("weekStart(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestAnalyticsRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 13, 45, 0, 0, time.UTC)
+
+	from, to, err := analyticsRange("", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(day("2024-02-15")) || !to.Equal(day("2024-03-15")) {
+		t.Fatalf("default range = %s..%s", from, to)
+	}
+
+	for _, tt := range []struct{ from, to string }{
+		{"2024-03-10", "2024-03-01"}, // from หลัง to
+		{"2023-01-01", "2024-03-01"}, // เกิน 366 วัน
+		{"yesterday", ""},
+		{"", "2024/03/01"},
+	} {
+		if _, _, err := analyticsRange(tt.from, tt.to, now); err == nil {
+			t.Errorf("analyticsRange(%q, %q) expected error", tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/storage"
+	"mini-taskmgr-backend/internal/utils"
+)
+
+// AttachmentStorage ที่เก็บไฟล์แนบ (ตั้งค่าใน main จาก env)
+var AttachmentStorage storage.Storage
+
+// AttachmentConfig ข้อจำกัดของไฟล์แนบ
+type AttachmentConfig struct {
+	// MaxBytes ขนาดสูงสุดต่อไฟล์
+	MaxBytes int64
+	// ProjectQuota ขนาดรวมสูงสุดของไฟล์แนบทั้งโปรเจกต์
+	ProjectQuota int64
+	// AllowedTypes MIME type ที่รับ (ตรวจจากเนื้อไฟล์ ไม่ใช่จากนามสกุลหรือ header ที่ client ส่งมา)
+	AllowedTypes []string
+	// LinkTTL อายุของลิงก์ดาวน์โหลด
+	LinkTTL time.Duration
+}
+
+// AttachmentLimits ค่าที่ใช้อยู่ (main ตั้งจาก config)
+var AttachmentLimits = AttachmentConfig{
+	MaxBytes:     10 << 20,
+	ProjectQuota: 500 << 20,
+	AllowedTypes: []string{
+		"image/png", "image/jpeg", "image/gif", "image/webp",
+		"application/pdf", "text/plain", "application/zip",
+	},
+	LinkTTL: 5 * time.Minute,
+}
+
+// ListAttachments GET /tasks/:id/attachments
+func ListAttachments(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	taskOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	_, proj, err := projectForTask(ctx, taskOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to view this task"))
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cur, err := db.Database.Collection("attachments").Find(ctx, bson.M{"taskId": taskOID}, opts)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	attachments := []models.Attachment{}
+	if err := cur.All(ctx, &attachments); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadAttachment POST /tasks/:id/attachments (multipart/form-data, field "file")
+func UploadAttachment(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	taskOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+
+	limits := AttachmentLimits
+	// เผื่อที่ให้ header ของ multipart อีก 1MB
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxBytes+1<<20)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Abort(c, apierror.ErrFileTooLarge.With("maxBytes", limits.MaxBytes))
+			return
+		}
+		apierror.Abort(c, apierror.Invalid("file", "required", "file is required"))
+		return
+	}
+	if fh.Size > limits.MaxBytes {
+		apierror.Abort(c, apierror.ErrFileTooLarge.With("maxBytes", limits.MaxBytes))
+		return
+	}
+	if fh.Size == 0 {
+		apierror.Abort(c, apierror.Invalid("file", "required", "file is empty"))
+		return
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("file", "file", "could not read file").Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	// ตรวจ MIME type จากเนื้อไฟล์ 512 ไบต์แรก
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !containsString(limits.AllowedTypes, contentType) {
+		apierror.Abort(c, apierror.ErrUnsupportedFileType.WithDetail("file type "+contentType+" is not allowed").With("allowedTypes", limits.AllowedTypes))
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		apierror.Abort(c, apierror.Internal("could not read file", err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Minute)
+	defer cancel()
+
+	task, proj, err := projectForTask(ctx, taskOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to edit this task"))
+		return
+	}
+
+	// จอง quota ก่อนอัปโหลด ($inc แบบมีเงื่อนไข จึงไม่เกิน quota แม้อัปโหลดพร้อมกันหลายไฟล์)
+	projCol := db.Database.Collection("projects")
+	res, err := projCol.UpdateOne(ctx, bson.M{
+		"_id": proj.ID,
+		"$or": bson.A{
+			bson.M{"attachmentBytes": bson.M{"$exists": false}},
+			bson.M{"attachmentBytes": bson.M{"$lte": limits.ProjectQuota - fh.Size}},
+		},
+	}, bson.M{"$inc": bson.M{"attachmentBytes": fh.Size}})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	if res.MatchedCount == 0 {
+		apierror.Abort(c, apierror.ErrQuotaExceeded.With("quotaBytes", limits.ProjectQuota))
+		return
+	}
+	release := func() {
+		_, _ = projCol.UpdateByID(context.Background(), proj.ID, bson.M{"$inc": bson.M{"attachmentBytes": -fh.Size}})
+	}
+
+	att := models.Attachment{
+		ID:           primitive.NewObjectID(),
+		TaskID:       task.ID,
+		ProjectID:    proj.ID,
+		FileName:     cleanFileName(fh.Filename),
+		ContentType:  contentType,
+		Size:         fh.Size,
+		UploadedByID: userID,
+		CreatedAt:    time.Now(),
+	}
+	att.StorageKey = "projects/" + proj.ID.Hex() + "/tasks/" + task.ID.Hex() + "/" + att.ID.Hex()
+
+	if err := AttachmentStorage.Put(ctx, att.StorageKey, file, att.Size, att.ContentType); err != nil {
+		slog.ErrorContext(ctx, "ATTACHMENT: put error", "err", err)
+		release()
+		apierror.Abort(c, apierror.Internal("could not store file", err))
+		return
+	}
+	if _, err := db.Database.Collection("attachments").InsertOne(ctx, att); err != nil {
+		_ = AttachmentStorage.Delete(context.Background(), att.StorageKey)
+		release()
+		apierror.Abort(c, apierror.Internal("insert failed", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, att)
+}
+
+// AttachmentLinkResponse ลิงก์ดาวน์โหลดอายุสั้น
+type AttachmentLinkResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// GetAttachmentLink GET /attachments/:id/link
+// ออกลิงก์ดาวน์โหลดอายุสั้น ใช้ได้โดยไม่ต้องส่ง Authorization header (เช่นใน <img src>)
+func GetAttachmentLink(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	att, ok := loadAttachmentForMember(ctx, c, userID)
+	if !ok {
+		return
+	}
+
+	ttl := AttachmentLimits.LinkTTL
+	token, err := utils.SignDownloadToken(userSub, att.ID.Hex(), ttl)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not sign link", err))
+		return
+	}
+	c.JSON(http.StatusOK, AttachmentLinkResponse{
+		URL:       "/api/v1/attachments/download?token=" + token,
+		ExpiresAt: time.Now().Add(ttl).UTC().Format(time.RFC3339),
+	})
+}
+
+// DownloadAttachment GET /attachments/download?token=...
+func DownloadAttachment(c *gin.Context) {
+	claims, err := utils.VerifyDownloadToken(c.Query("token"))
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidDownloadLink)
+		return
+	}
+	attID, err := primitive.ObjectIDFromHex(claims.Resource)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidDownloadLink)
+		return
+	}
+	userID, _ := primitive.ObjectIDFromHex(claims.Sub)
+
+	ctx, cancel := requestContext(c, 10*time.Minute)
+	defer cancel()
+
+	var att models.Attachment
+	if err := db.Database.Collection("attachments").FindOne(ctx, bson.M{"_id": attID}).Decode(&att); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrAttachmentNotFound))
+		return
+	}
+	// สิทธิ์อาจถูกถอนไปหลังออกลิงก์ ตรวจอีกครั้ง
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": att.ProjectID}).Decode(&proj); err != nil || !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to download this file"))
+		return
+	}
+
+	rc, err := AttachmentStorage.Open(ctx, att.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			apierror.Abort(c, apierror.ErrAttachmentNotFound.WithDetail("file not found"))
+			return
+		}
+		slog.ErrorContext(ctx, "ATTACHMENT: open error", "err", err)
+		apierror.Abort(c, apierror.Internal("could not read file", err))
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, att.Size, att.ContentType, rc, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": att.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// DeleteAttachment DELETE /attachments/:id (คนอัปโหลด หรือ admin ของโปรเจกต์)
+func DeleteAttachment(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 30*time.Second)
+	defer cancel()
+
+	att, ok := loadAttachmentForMember(ctx, c, userID)
+	if !ok {
+		return
+	}
+	if att.UploadedByID != userID {
+		var proj models.Project
+		if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": att.ProjectID}).Decode(&proj); err != nil || !isProjectAdmin(proj, userID) {
+			apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to delete this file"))
+			return
+		}
+	}
+
+	if err := deleteAttachments(ctx, bson.M{"_id": att.ID}); err != nil {
+		apierror.Abort(c, apierror.Internal("delete failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "deleted"})
+}
+
+// loadAttachmentForMember โหลดไฟล์แนบจาก :id และเช็คว่า user เป็นสมาชิกโปรเจกต์
+func loadAttachmentForMember(ctx context.Context, c *gin.Context, userID primitive.ObjectID) (models.Attachment, bool) {
+	attID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("attachmentId"))
+		return models.Attachment{}, false
+	}
+	var att models.Attachment
+	if err := db.Database.Collection("attachments").FindOne(ctx, bson.M{"_id": attID}).Decode(&att); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrAttachmentNotFound))
+		return models.Attachment{}, false
+	}
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": att.ProjectID}).Decode(&proj); err != nil || !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrAttachmentNotFound)
+		return models.Attachment{}, false
+	}
+	return att, true
+}
+
+// deleteAttachments ลบไฟล์แนบที่ตรง filter ทั้งใน storage และ DB แล้วคืน quota ให้โปรเจกต์
+// ไฟล์ที่ลบจาก storage ไม่สำเร็จจะถูก log ไว้ แต่ยังลบ record ต่อ
+func deleteAttachments(ctx context.Context, filter bson.M) error {
+	col := db.Database.Collection("attachments")
+	cur, err := col.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var atts []models.Attachment
+	if err := cur.All(ctx, &atts); err != nil {
+		return err
+	}
+
+	freed := map[primitive.ObjectID]int64{}
+	for _, a := range atts {
+		if AttachmentStorage != nil {
+			if err := AttachmentStorage.Delete(ctx, a.StorageKey); err != nil {
+				slog.ErrorContext(ctx, "ATTACHMENT: delete failed", "key", a.StorageKey, "err", err)
+			}
+		}
+		if _, err := col.DeleteOne(ctx, bson.M{"_id": a.ID}); err != nil {
+			return err
+		}
+		freed[a.ProjectID] += a.Size
+	}
+	// attachmentBytes เป็นตัวนับภายใน ไม่ใช่การแก้โปรเจกต์ จึงไม่เพิ่ม version
+	for pid, size := range freed {
+		if _, err := db.Database.Collection("projects").UpdateByID(ctx, pid, bson.M{
+			"$inc": bson.M{"attachmentBytes": -size},
+		}); err != nil {
+			slog.ErrorContext(ctx, "ATTACHMENT: release quota error", "err", err)
+		}
+	}
+	return nil
+}
+
+// cleanFileName ตัด path และตัวอักษรควบคุมออกจากชื่อไฟล์ที่ client ส่งมา
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,33 +15,111 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"mini-taskmgr-backend/internal/apierror"
 	"mini-taskmgr-backend/internal/db"
 	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+	"mini-taskmgr-backend/internal/ratelimit"
 	"mini-taskmgr-backend/internal/utils"
 )
 
+// RegisterRequest body ของ POST /auth/register
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginRequest body ของ POST /auth/login
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse ผลของ login (รหัสผ่าน, 2FA หรือ SSO)
+// ถ้าเปิด 2FA ไว้ ขั้นแรกจะได้แค่ twoFactorRequired กับ challengeToken
+type LoginResponse struct {
+	AccessToken       string       `json:"accessToken,omitempty"`
+	User              *UserSummary `json:"user,omitempty"`
+	TwoFactorRequired bool         `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string       `json:"challengeToken,omitempty"`
+}
+
+// UserSummary ข้อมูล user ที่แนบไปกับ access token
+type UserSummary struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+// MeResponse ผลของ GET /me
+type MeResponse struct {
+	ID               string      `json:"id"`
+	Name             string      `json:"name"`
+	Email            string      `json:"email"`
+	Role             models.Role `json:"role"`
+	TwoFactorEnabled bool        `json:"twoFactorEnabled"`
+}
+
+// UpdateProfileRequest body ของ PATCH /users/:id
+type UpdateProfileRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ChangePasswordRequest body ของ POST /auth/change-password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+// DeleteAccountRequest body (ไม่บังคับ) ของ DELETE /users/:id
+// transfers: project id -> user id ของสมาชิกที่จะรับโอนโปรเจกต์ที่มีคนอื่นใช้อยู่
+type DeleteAccountRequest struct {
+	Transfers map[string]string `json:"transfers"`
+}
+
+// ForgotPasswordRequest body ของ POST /auth/forgot-password
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPasswordResponse ผลของ POST /auth/forgot-password
+// resetToken มีเฉพาะตอน APP_ENV=development (ปกติส่งทางอีเมลเท่านั้น)
+type ForgotPasswordResponse struct {
+	Message    string `json:"message"`
+	ResetToken string `json:"resetToken,omitempty"`
+}
+
+// ExposeResetTokens ส่ง reset token กลับใน response ของ forgot-password ด้วย (ตั้งจาก main เฉพาะ development)
+var ExposeResetTokens bool
+
+// ResetPasswordRequest body ของ POST /auth/reset-password
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
 func Register(c *gin.Context) {
-	var body struct {
-		Name     string `json:"name"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-	if err := c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	var body RegisterRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
 	// hash password
 	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "hash error"})
+		apierror.Abort(c, apierror.Internal("hash error", err))
 		return
 	}
 
 	// insert to MongoDB
-	coll := db.Client.Database(os.Getenv("MONGODB_DB")).Collection("users")
-	_, err = coll.InsertOne(context.TODO(), bson.M{
+	coll := db.Database.Collection("users")
+	_, err = coll.InsertOne(c.Request.Context(), bson.M{
 		"name":         body.Name,
 		"email":        strings.ToLower(strings.TrimSpace(body.Email)),
 		"passwordHash": string(hash),
@@ -47,20 +127,17 @@ func Register(c *gin.Context) {
 		"createdAt":    time.Now(),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert error"})
+		apierror.Abort(c, apierror.Internal("insert error", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "registered"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "registered"})
 }
 
 func Login(c *gin.Context) {
-	var input struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
-	}
+	var input LoginRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
@@ -70,10 +147,27 @@ func Login(c *gin.Context) {
 	ctx := c.Request.Context()
 	usersColl := db.Database.Collection("users")
 
+	// ล็อกตาม email ที่พิมพ์มา ไม่ว่าจะมีบัญชีหรือไม่ จึงใช้เดาว่ามีบัญชีไม่ได้
+	if wait, err := loginLockRemaining(ctx, email); err != nil {
+		slog.ErrorContext(ctx, "LOGIN: check lock error", "err", err)
+	} else if wait > 0 {
+		ratelimit.TooManyRequests(c, wait, apierror.ErrAccountLocked)
+		return
+	}
+
 	var u models.User
 	if err := usersColl.FindOne(ctx, bson.M{"email": email}).Decode(&u); err != nil {
-		log.Println("LOGIN: user not found for email:", email, "err:", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			apierror.Abort(c, apierror.Internal("db error", err))
+			return
+		}
+		slog.InfoContext(ctx, "LOGIN: unknown email")
+		// ใช้เวลาเท่ากับกรณีรหัสผ่านผิด
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(input.Password))
+		if err := registerLoginFailure(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "LOGIN: record failure error", "err", err)
+		}
+		apierror.Abort(c, apierror.ErrInvalidCredentials)
 		return
 	}
 
@@ -82,42 +176,156 @@ func Login(c *gin.Context) {
 		[]byte(u.PasswordHash),
 		[]byte(input.Password),
 	); err != nil {
-		log.Println("LOGIN: password mismatch for email:", email, "err:", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		slog.InfoContext(ctx, "LOGIN: password mismatch", "userId", u.ID.Hex())
+		if err := registerLoginFailure(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "LOGIN: record failure error", "err", err)
+		}
+		apierror.Abort(c, apierror.ErrInvalidCredentials)
 		return
 	}
+	clearLoginFailures(ctx, email)
 
+	if u.Disabled {
+		apierror.Abort(c, apierror.ErrAccountDisabled)
+		return
+	}
+	// admin สั่ง reset รหัสผ่าน: ต้องตั้งรหัสใหม่ผ่าน /auth/reset-password ก่อน
+	if u.MustResetPassword {
+		apierror.Abort(c, apierror.ErrPasswordResetRequired.With("passwordResetRequired", true))
+		return
+	}
+
+	payload, err := loginPayload(u)
+	if err != nil {
+		slog.ErrorContext(ctx, "LOGIN: sign token error", "err", err)
+		apierror.Abort(c, apierror.Internal("could not sign token", err))
+		return
+	}
+	c.JSON(http.StatusOK, payload)
+}
+
+// loginPayload ผลของการ login ขั้นแรก (รหัสผ่านหรือ SSO)
+// ถ้าเปิด 2FA ไว้ จะยังไม่ออก access token แต่ให้ challenge token ไปยืนยัน code ที่ /auth/login/2fa ก่อน
+func loginPayload(u models.User) (LoginResponse, error) {
+	if u.TOTPEnabled {
+		challenge, err := utils.SignChallengeToken(u.ID.Hex())
+		if err != nil {
+			return LoginResponse{}, err
+		}
+		return LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+	return accessTokenPayload(u)
+}
+
+// accessTokenPayload ออก access token พร้อมข้อมูล user หลัง login สำเร็จ
+func accessTokenPayload(u models.User) (LoginResponse, error) {
 	id := u.ID.Hex()
 	token, err := utils.Sign(id, string(u.Role))
 	if err != nil {
-		log.Println("LOGIN: sign token error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not sign token"})
-		return
+		return LoginResponse{}, err
 	}
+	return LoginResponse{
+		AccessToken: token,
+		User:        &UserSummary{ID: id, Name: u.Name, Email: u.Email, Role: u.Role},
+	}, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"accessToken": token,
-		"user": gin.H{
-			"id":    id,
-			"name":  u.Name,
-			"email": u.Email,
-			"role":  u.Role,
+const (
+	// loginLockThreshold จำนวนครั้งที่ login ผิดติดกันก่อนเริ่มล็อกบัญชี
+	loginLockThreshold = 5
+	loginLockBase      = time.Minute
+	loginLockMax       = time.Hour
+	// loginFailureTTL นับครั้งที่ผิดต่อเนื่องภายในช่วงนี้ (นับจากครั้งล่าสุด)
+	loginFailureTTL = 24 * time.Hour
+)
+
+// loginFailure จำนวนครั้งที่ login ผิดของ email หนึ่ง (collection loginFailures, _id = email)
+type loginFailure struct {
+	Email        string     `bson:"_id"`
+	FailedLogins int        `bson:"failedLogins"`
+	LockedUntil  *time.Time `bson:"lockedUntil,omitempty"`
+	ExpiresAt    time.Time  `bson:"expiresAt"`
+}
+
+// loginLockRemaining เวลาที่ email ยังถูกล็อกอยู่ (0 = ไม่ได้ล็อก)
+func loginLockRemaining(ctx context.Context, email string) (time.Duration, error) {
+	var f loginFailure
+	err := db.Database.Collection("loginFailures").FindOne(ctx, bson.M{"_id": email}).Decode(&f)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if f.LockedUntil == nil {
+		return 0, nil
+	}
+	return max(time.Until(*f.LockedUntil), 0), nil
+}
+
+// registerLoginFailure นับครั้งที่ login ผิด (รวม email ที่ไม่มีบัญชี) ถ้าถึง threshold จะล็อก email นั้น
+// นานขึ้นเป็นเท่าตัวทุกครั้งที่ผิดต่อ (1m, 2m, 4m, ... สูงสุด 1h)
+func registerLoginFailure(ctx context.Context, email string) error {
+	coll := db.Database.Collection("loginFailures")
+	now := time.Now()
+	var f loginFailure
+	err := coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": email},
+		bson.M{
+			"$inc": bson.M{"failedLogins": 1},
+			"$set": bson.M{"expiresAt": now.Add(loginFailureTTL)},
 		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&f)
+	if err != nil {
+		return err
+	}
+	if f.FailedLogins < loginLockThreshold {
+		return nil
+	}
+
+	lock := loginLockMax
+	if shift := f.FailedLogins - loginLockThreshold; shift < 6 {
+		lock = loginLockBase << shift
+	}
+	_, err = coll.UpdateByID(ctx, email, bson.M{
+		"$set": bson.M{"lockedUntil": now.Add(lock)},
 	})
+	return err
+}
+
+// clearLoginFailures ล้างตัวนับหลัง login สำเร็จ
+func clearLoginFailures(ctx context.Context, email string) {
+	if _, err := db.Database.Collection("loginFailures").DeleteOne(ctx, bson.M{"_id": email}); err != nil {
+		slog.ErrorContext(ctx, "LOGIN: clear failures error", "err", err)
+	}
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash hash ไว้เทียบตอนไม่พบ email ให้ใช้เวลาพอ ๆ กับรหัสผ่านผิด
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), 10)
+	})
+	return dummyHash
 }
 
 func Me(c *gin.Context) {
 	sub := c.MustGet("userSub").(string)
 	oid, _ := primitive.ObjectIDFromHex(sub)
 	col := db.Database.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 	var u models.User
 	if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"id": u.ID.Hex(), "name": u.Name, "email": u.Email, "role": u.Role})
+	c.JSON(http.StatusOK, MeResponse{ID: u.ID.Hex(), Name: u.Name, Email: u.Email, Role: u.Role, TwoFactorEnabled: u.TOTPEnabled})
 }
 
 // UpdateProfile อัปเดตชื่อผู้ใช้
@@ -128,34 +336,32 @@ func UpdateProfile(c *gin.Context) {
 	userID := c.Param("id")
 	oid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		apierror.Abort(c, apierror.InvalidID("userId"))
 		return
 	}
 
 	// เช็ค permission: user สามารถแก้ไขเฉพาะข้อมูลของตัวเองเท่านั้น
 	if currentUserID != oid {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you can only update your own profile"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you can only update your own profile"))
 		return
 	}
 
-	var input struct {
-		Name string `json:"name" binding:"required"`
-	}
+	var input UpdateProfileRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	col := db.Database.Collection("users")
 	_, err = col.UpdateByID(ctx, oid, bson.M{"$set": bson.M{"name": input.Name}})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierror.Abort(c, apierror.Internal("update failed", err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "updated"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "updated"})
 }
 
 // ChangePassword เปลี่ยนรหัสผ่าน
@@ -163,53 +369,56 @@ func ChangePassword(c *gin.Context) {
 	sub := c.MustGet("userSub").(string)
 	oid, _ := primitive.ObjectIDFromHex(sub)
 
-	var input struct {
-		CurrentPassword string `json:"currentPassword" binding:"required"`
-		NewPassword     string `json:"newPassword" binding:"required"`
-	}
+	var input ChangePasswordRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
 	if len(input.NewPassword) < 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "password must be at least 6 characters"})
+		apierror.Abort(c, apierror.Invalid("newPassword", "min", "password must be at least 6 characters"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	col := db.Database.Collection("users")
 	var u models.User
 	if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
 		return
 	}
 
 	// ตรวจสอบรหัสผ่านเก่า
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(input.CurrentPassword)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is incorrect"})
+		apierror.Abort(c, apierror.ErrInvalidPassword.WithDetail("current password is incorrect"))
 		return
 	}
 
 	// hash รหัสผ่านใหม่
 	newHash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), 10)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "hash error"})
+		apierror.Abort(c, apierror.Internal("hash error", err))
 		return
 	}
 
-	// อัปเดตรหัสผ่าน
-	_, err = col.UpdateByID(ctx, oid, bson.M{"$set": bson.M{"passwordHash": string(newHash)}})
+	// อัปเดตรหัสผ่าน และเพิกถอน access token ที่ออกก่อนหน้านี้ (รวมถึง token ที่ใช้เรียกอยู่ ต้อง login ใหม่)
+	_, err = col.UpdateByID(ctx, oid, bson.M{"$set": bson.M{"passwordHash": string(newHash), "tokensValidAfter": time.Now()}})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierror.Abort(c, apierror.Internal("update failed", err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "password changed"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "password changed"})
 }
 
-// DeleteAccount ลบบัญชี (ลบ user และ projects/tasks ทั้งหมด)
+// DeleteAccount ลบบัญชี
+// โปรเจกต์ที่เป็นเจ้าของคนเดียวจะถูกลบไปด้วย แต่ถ้าเป็นเจ้าของโปรเจกต์ที่มีสมาชิกคนอื่น
+// ต้องระบุผู้รับโอนใน body {"transfers": {"<projectId>": "<newOwnerId>"}} ไม่อย่างนั้นจะตอบ 409
+// พร้อมรายชื่อโปรเจกต์ที่ต้องโอนก่อน
+//
+// ?mode=anonymize เก็บ task / comment / activity ที่ทำร่วมกับคนอื่นไว้ แต่แทนตัวตนด้วย "Deleted user"
+// (ค่าเริ่มต้นคือเอาออกจาก assignees และสมาชิกโปรเจกต์)
 func DeleteAccount(c *gin.Context) {
 	userSub := c.MustGet("userSub").(string)
 	currentUserID, _ := primitive.ObjectIDFromHex(userSub)
@@ -217,120 +426,233 @@ func DeleteAccount(c *gin.Context) {
 	userID := c.Param("id")
 	oid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		apierror.Abort(c, apierror.InvalidID("userId"))
 		return
 	}
 
 	// เช็ค permission: user สามารถลบเฉพาะบัญชีของตัวเองเท่านั้น
 	if currentUserID != oid {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you can only delete your own account"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you can only delete your own account"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	anonymize := false
+	switch c.Query("mode") {
+	case "", "delete":
+	case "anonymize":
+		anonymize = true
+	default:
+		apierror.Abort(c, apierror.Invalid("mode", "oneof", "mode must be delete or anonymize"))
+		return
+	}
+
+	var input DeleteAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 15*time.Second)
 	defer cancel()
 
-	// ลบ projects ที่ user นี้เป็นเจ้าของ
 	projCol := db.Database.Collection("projects")
-	projCol.DeleteMany(ctx, bson.M{"ownerId": oid})
+	cur, err := projCol.Find(ctx, bson.M{"ownerId": oid})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	var owned []models.Project
+	if err := cur.All(ctx, &owned); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+
+	// แยกโปรเจกต์ที่มีคนอื่นใช้อยู่ ต้องโอนให้สมาชิกก่อน ห้ามลบทิ้ง
+	type transfer struct {
+		project  models.Project
+		newOwner primitive.ObjectID
+	}
+	var transfers []transfer
+	var solo []primitive.ObjectID
+	var blocked []gin.H
+	for _, p := range owned {
+		others := otherProjectMembers(p, oid)
+		if len(others) == 0 {
+			solo = append(solo, p.ID)
+			continue
+		}
+		target, ok := input.Transfers[p.ID.Hex()]
+		newOwner, err := primitive.ObjectIDFromHex(target)
+		if !ok || err != nil || newOwner == oid || !isProjectMember(p, newOwner) {
+			blocked = append(blocked, gin.H{"id": p.ID.Hex(), "name": p.Name, "members": others})
+			continue
+		}
+		transfers = append(transfers, transfer{project: p, newOwner: newOwner})
+	}
+	if len(blocked) > 0 {
+		apierror.Abort(c, apierror.ErrProjectTransferPending.With("projects", blocked))
+		return
+	}
+
+	for _, t := range transfers {
+		if err := transferProjectOwnership(ctx, t.project, t.newOwner); err != nil {
+			slog.ErrorContext(ctx, "DELETE_ACCOUNT: transfer project error", "err", err)
+			apierror.Abort(c, apierror.Internal("could not transfer project "+t.project.ID.Hex(), err))
+			return
+		}
+	}
+
+	if anonymize {
+		if err := anonymizeUserReferences(ctx, oid); err != nil {
+			slog.ErrorContext(ctx, "DELETE_ACCOUNT: anonymize error", "err", err)
+			apierror.Abort(c, apierror.Internal("could not anonymize account", err))
+			return
+		}
+	}
+
+	// ออกจากทุกโปรเจกต์ที่เป็นสมาชิก (รวมโปรเจกต์ที่เพิ่งโอนไป)
+	memberCur, err := projCol.Find(ctx, bson.M{"members.userId": oid, "ownerId": bson.M{"$ne": oid}})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	var memberOf []models.Project
+	if err := memberCur.All(ctx, &memberOf); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	for _, p := range memberOf {
+		if err := removeProjectMember(ctx, p.ID, oid); err != nil {
+			slog.ErrorContext(ctx, "DELETE_ACCOUNT: leave project error", "err", err)
+		}
+	}
+
+	// ลบ projects ที่ user นี้เป็นเจ้าของคนเดียว
+	if len(solo) > 0 {
+		if _, err := projCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": solo}, "ownerId": oid}); err != nil {
+			apierror.Abort(c, apierror.Internal("delete failed", err))
+			return
+		}
+		if err := deleteAttachments(ctx, bson.M{"projectId": bson.M{"$in": solo}}); err != nil {
+			slog.ErrorContext(ctx, "DELETE_ACCOUNT: delete attachments error", "err", err)
+		}
+	}
+
+	deletePersonalData(ctx, oid)
 
 	// ลบ user
 	userCol := db.Database.Collection("users")
 	result, err := userCol.DeleteOne(ctx, bson.M{"_id": oid})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		apierror.Abort(c, apierror.Internal("delete failed", err))
 		return
 	}
 	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		apierror.Abort(c, apierror.ErrUserNotFound)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "account deleted"})
 }
 
-// ForgotPassword generates a password reset token and returns it
-func ForgotPassword(c *gin.Context) {
-	var input struct {
-		Email string `json:"email" binding:"required,email"`
+// otherProjectMembers id ของสมาชิกโปรเจกต์ที่ไม่ใช่ userID
+func otherProjectMembers(p models.Project, userID primitive.ObjectID) []string {
+	var ids []string
+	for _, m := range p.Members {
+		if m.UserID != userID {
+			ids = append(ids, m.UserID.Hex())
+		}
 	}
+	return ids
+}
+
+// ForgotPassword สร้าง reset token แล้วส่งทางอีเมล
+// ตอบเหมือนกันทุกกรณีเพื่อไม่ให้ใช้เช็คว่ามีบัญชีของอีเมลนี้หรือไม่
+func ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
 	email := strings.ToLower(strings.TrimSpace(input.Email))
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
-	// Find user by email
-	usersColl := db.Database.Collection("users")
+	resp := ForgotPasswordResponse{Message: "if the email exists, a reset link has been sent"}
+
 	var user models.User
-	if err := usersColl.FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
-		// Don't reveal if user exists or not (security best practice)
-		c.JSON(http.StatusOK, gin.H{"message": "if email exists, reset link will be sent"})
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, "FORGOT_PASSWORD: find user error", "err", err)
+		}
+		c.JSON(http.StatusOK, resp)
 		return
 	}
 
-	// Generate reset token (JWT-based, 24-hour expiry)
-	resetToken, err := utils.SignResetToken(user.ID.Hex())
+	resetToken, err := issuePasswordResetToken(ctx, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not generate reset token"})
+		slog.ErrorContext(ctx, "FORGOT_PASSWORD: issue token error", "err", err)
+		apierror.Abort(c, apierror.Internal("could not generate reset token", err))
 		return
 	}
 
-	// Store reset token in database
-	resetTokensColl := db.Database.Collection("passwordResetTokens")
-	_, err = resetTokensColl.InsertOne(ctx, bson.M{
+	if err := notify.DefaultMailer.Send(ctx, user.Email, "Reset your password",
+		"Use this token to reset your password within 24 hours: "+resetToken); err != nil {
+		slog.ErrorContext(ctx, "FORGOT_PASSWORD: send mail error", "err", err)
+	}
+	if ExposeResetTokens {
+		resp.ResetToken = resetToken
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// issuePasswordResetToken creates a reset token (JWT-based, 24-hour expiry) and stores it
+func issuePasswordResetToken(ctx context.Context, user models.User) (string, error) {
+	resetToken, err := utils.SignResetToken(user.ID.Hex())
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Database.Collection("passwordResetTokens").InsertOne(ctx, bson.M{
 		"userId":    user.ID,
-		"email":     email,
+		"email":     user.Email,
 		"token":     resetToken,
 		"expiresAt": time.Now().Add(24 * time.Hour),
 		"createdAt": time.Now(),
 		"used":      false,
 	})
 	if err != nil {
-		log.Println("FORGOT_PASSWORD: insert token error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save reset token"})
-		return
+		return "", err
 	}
-
-	// In production, you would send this via email
-	// For now, return it for testing purposes
-	c.JSON(http.StatusOK, gin.H{
-		"message":    "password reset link sent to your email",
-		"resetToken": resetToken, // Remove in production, send via email instead
-	})
+	return resetToken, nil
 }
 
 // ResetPassword validates the reset token and updates password
 func ResetPassword(c *gin.Context) {
-	var input struct {
-		Token       string `json:"token" binding:"required"`
-		NewPassword string `json:"newPassword" binding:"required"`
-	}
+	var input ResetPasswordRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
 	if len(input.NewPassword) < 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "password must be at least 6 characters"})
+		apierror.Abort(c, apierror.Invalid("newPassword", "min", "password must be at least 6 characters"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	// Verify token
 	claims, err := utils.VerifyResetToken(input.Token)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired reset token"})
+		apierror.Abort(c, apierror.ErrInvalidResetToken)
 		return
 	}
 
 	userID, err := primitive.ObjectIDFromHex(claims.Sub)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token"})
+		apierror.Abort(c, apierror.ErrInvalidResetToken)
 		return
 	}
 
@@ -341,30 +663,32 @@ func ResetPassword(c *gin.Context) {
 		"token": input.Token,
 		"used":  false,
 	}).Decode(&resetToken); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or already used reset token"})
+		apierror.Abort(c, apierror.ErrInvalidResetToken.WithDetail("invalid or already used reset token"))
 		return
 	}
 
 	// Check if token has expired
 	if time.Now().After(resetToken.ExpiresAt) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "reset token has expired"})
+		apierror.Abort(c, apierror.ErrInvalidResetToken.WithDetail("reset token has expired"))
 		return
 	}
 
 	// Hash new password
 	newHash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), 10)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "hash error"})
+		apierror.Abort(c, apierror.Internal("hash error", err))
 		return
 	}
 
 	// Update user password
 	usersColl := db.Database.Collection("users")
+	// access token ที่ออกก่อนหน้านี้ใช้ไม่ได้อีก
 	_, err = usersColl.UpdateByID(ctx, userID, bson.M{
-		"$set": bson.M{"passwordHash": string(newHash)},
+		"$set":   bson.M{"passwordHash": string(newHash), "tokensValidAfter": time.Now()},
+		"$unset": bson.M{"mustResetPassword": ""},
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update password"})
+		apierror.Abort(c, apierror.Internal("could not update password", err))
 		return
 	}
 
@@ -373,8 +697,8 @@ func ResetPassword(c *gin.Context) {
 		"$set": bson.M{"used": true},
 	})
 	if err != nil {
-		log.Println("RESET_PASSWORD: mark token used error:", err)
+		slog.ErrorContext(ctx, "RESET_PASSWORD: mark token used error", "err", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "password reset successfully"})
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -10,22 +11,38 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"mini-taskmgr-backend/internal/apierror"
 	"mini-taskmgr-backend/internal/db"
 	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+	"mini-taskmgr-backend/internal/webhooks"
 )
 
+// CreateColumnRequest body ของ POST /columns (ส่ง boardId หรือ projectId อย่างใดอย่างหนึ่ง)
+type CreateColumnRequest struct {
+	BoardID   string `json:"boardId"`   // optional
+	ProjectID string `json:"projectId"` // optional
+	Name      string `json:"name" binding:"required"`
+	Category  string `json:"category"` // optional: backlog / active / done
+}
+
 func CreateColumn(c *gin.Context) {
-	var input struct {
-		BoardID   string `json:"boardId"`   // optional
-		ProjectID string `json:"projectId"` // optional
-		Name      string `json:"name" binding:"required"`
-	}
+	var input CreateColumnRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
+	category := models.ColumnBacklog
+	if input.Category != "" {
+		category = models.ColumnCategory(input.Category)
+		if !category.Valid() {
+			apierror.Abort(c, apierror.Invalid("category", "oneof", "category must be backlog, active or done"))
+			return
+		}
+	}
+
 	ctx := c.Request.Context()
 	boardsColl := db.Database.Collection("boards")
 	columnsColl := db.Database.Collection("columns")
@@ -36,13 +53,13 @@ func CreateColumn(c *gin.Context) {
 	if input.BoardID != "" {
 		boardID, err = primitive.ObjectIDFromHex(input.BoardID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid boardId"})
+			apierror.Abort(c, apierror.InvalidID("boardId"))
 			return
 		}
 	} else if input.ProjectID != "" {
 		pid, err := primitive.ObjectIDFromHex(input.ProjectID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid projectId"})
+			apierror.Abort(c, apierror.InvalidID("projectId"))
 			return
 		}
 
@@ -55,110 +72,295 @@ func CreateColumn(c *gin.Context) {
 				Name:      "Main board",
 			}
 			if _, err = boardsColl.InsertOne(ctx, board); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create board"})
+				apierror.Abort(c, apierror.Internal("could not create board", err))
 				return
 			}
 		} else if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not query board"})
+			apierror.Abort(c, apierror.Internal("could not query board", err))
 			return
 		}
 
 		boardID = board.ID
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "boardId or projectId is required"})
+		apierror.Abort(c, apierror.Invalid("projectId", "required", "boardId or projectId is required"))
 		return
 	}
 
 	// column struct ต้องตรงกับ models.Column
 	column := models.Column{
-		ID:      primitive.NewObjectID(),
-		BoardID: boardID,
-		Name:    input.Name,
+		ID:       primitive.NewObjectID(),
+		BoardID:  boardID,
+		Name:     input.Name,
+		Category: category,
+		Version:  1,
 	}
 
 	if _, err := columnsColl.InsertOne(ctx, column); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create column"})
+		apierror.Abort(c, apierror.Internal("could not create column", err))
 		return
 	}
 
+	publishBoardEvent(ctx, boardID, webhooks.EventColumnCreated, column)
+	setETag(c, column.Version)
 	c.JSON(http.StatusOK, column)
 }
 
+// CreateTaskRequest body ของ POST /tasks (dueDate เป็น RFC3339 หรือ YYYY-MM-DD)
+type CreateTaskRequest struct {
+	ColumnID    string `json:"columnId" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	DueDate     string `json:"dueDate"`
+}
+
+// CreateTaskResponse ผลของ POST /tasks
+type CreateTaskResponse struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
 func CreateTask(c *gin.Context) {
-	var input struct {
-		ColumnID    string `json:"columnId" binding:"required"`
-		Title       string `json:"title" binding:"required"`
-		Description string `json:"description"`
-		Priority    string `json:"priority"`
-		DueDate     string `json:"dueDate"`
-	}
+	var input CreateTaskRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 	colOID, err := primitive.ObjectIDFromHex(input.ColumnID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid columnId"})
+		apierror.Abort(c, apierror.InvalidID("columnId"))
+		return
+	}
+	if input.Priority != "" && !models.ValidTaskPriority(input.Priority) {
+		apierror.Abort(c, errInvalidPriority)
+		return
+	}
+	dueDate, err := parseDueDate(input.DueDate)
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("dueDate", "datetime", "invalid dueDate"))
 		return
 	}
 	colCol := db.Database.Collection("columns")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	var column models.Column
 	if err := colCol.FindOne(ctx, bson.M{"_id": colOID}).Decode(&column); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "column not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
 		return
 	}
+	userID, _ := primitive.ObjectIDFromHex(c.GetString("userSub"))
+	now := time.Now()
 	task := models.Task{
 		Title:       input.Title,
 		Description: input.Description,
 		Priority:    input.Priority,
+		DueDate:     dueDate,
 		ColumnID:    colOID,
 		BoardID:     column.BoardID,
 		Position:    0,
+		CreatedByID: userID,
+		CreatedAt:   now,
+		Version:     1,
+	}
+	// สร้าง task ใน column active/done ตรง ๆ ก็ต้องมี timestamp เหมือนย้ายเข้าไป
+	switch column.Category {
+	case models.ColumnActive:
+		task.StartedAt = &now
+	case models.ColumnDone:
+		task.StartedAt = &now
+		task.CompletedAt = &now
 	}
 	taskCol := db.Database.Collection("tasks")
 	res, err := taskCol.InsertOne(ctx, task)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "create failed"})
-		return
-	}
-	id := res.InsertedID.(primitive.ObjectID).Hex()
-	c.JSON(http.StatusCreated, gin.H{"id": id, "title": task.Title})
+		apierror.Abort(c, apierror.Internal("create failed", err))
+		return
+	}
+	taskOID := res.InsertedID.(primitive.ObjectID)
+	recordTaskEvent(ctx, models.TaskEvent{
+		TaskID:     taskOID,
+		BoardID:    task.BoardID,
+		Type:       models.TaskEventCreated,
+		ToColumnID: &colOID,
+		ActorID:    userID,
+		At:         now,
+	})
+	task.ID = taskOID
+	publishBoardEvent(ctx, task.BoardID, webhooks.EventTaskCreated, task)
+	setETag(c, task.Version)
+	c.JSON(http.StatusCreated, CreateTaskResponse{ID: taskOID.Hex(), Title: task.Title})
+}
+
+// MoveTaskRequest body ของ POST /tasks/move
+type MoveTaskRequest struct {
+	TaskID     string `json:"taskId" binding:"required"`
+	ToColumnID string `json:"toColumnId" binding:"required"`
+	// ถ้าเปิดไว้ จะไม่ยอมย้าย task ที่ยังถูก block เข้า column ที่เสร็จแล้ว
+	EnforceDependencies bool `json:"enforceDependencies"`
 }
 
+// MoveTask ย้าย task ไป column อื่นใน board เดียวกัน (สมาชิกโปรเจกต์ทำได้)
 func MoveTask(c *gin.Context) {
-	var input struct {
-		TaskID     string `json:"taskId" binding:"required"`
-		ToColumnID string `json:"toColumnId" binding:"required"`
-	}
+	var input MoveTaskRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 	taskOID, err := primitive.ObjectIDFromHex(input.TaskID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid taskId"})
+		apierror.Abort(c, apierror.InvalidID("taskId"))
 		return
 	}
 	colOID, err := primitive.ObjectIDFromHex(input.ToColumnID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid toColumnId"})
+		apierror.Abort(c, apierror.InvalidID("toColumnId"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 	taskCol := db.Database.Collection("tasks")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
-	_, err = taskCol.UpdateByID(ctx, taskOID, bson.M{"$set": bson.M{"columnId": colOID}})
+
+	userID, _ := primitive.ObjectIDFromHex(c.GetString("userSub"))
+	task, proj, err := projectForTask(ctx, taskOID)
+	if err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrTaskNotFound))
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you are not a member of this task's project"))
+		return
+	}
+	var column models.Column
+	if err := db.Database.Collection("columns").FindOne(ctx, bson.M{"_id": colOID}).Decode(&column); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
+		return
+	}
+	if column.BoardID != task.BoardID {
+		apierror.Abort(c, apierror.Invalid("toColumnId", "board", "column is on another board"))
+		return
+	}
+
+	if input.EnforceDependencies && column.Category == models.ColumnDone {
+		pending, err := unfinishedBlockers(ctx, taskOID)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("could not check dependencies", err))
+			return
+		}
+		if len(pending) > 0 {
+			apierror.Abort(c, apierror.ErrTaskBlocked.With("blockedBy", pending))
+			return
+		}
+	}
+
+	now := time.Now()
+	version, err := updateVersioned(ctx, taskCol, taskOID, want, moveTaskUpdate(task, column, now))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierror.Abort(c, versionError(ctx, taskCol, taskOID, err, apierror.ErrTaskNotFound))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+
+	afterTaskMoved(ctx, task, colOID, userID, now)
+	setETag(c, version)
+	c.JSON(http.StatusOK, OKResponse{OK: true})
 }
 
-// UpdateTask แก้ไข task (title, description, priority)
+// afterTaskMoved บันทึก event และส่ง webhook หลังย้าย task (ไม่ทำอะไรถ้าอยู่ column เดิม)
+func afterTaskMoved(ctx context.Context, task models.Task, toColumnID, actorID primitive.ObjectID, now time.Time) {
+	if task.ColumnID == toColumnID {
+		return
+	}
+	recordTaskEvent(ctx, models.TaskEvent{
+		TaskID:       task.ID,
+		BoardID:      task.BoardID,
+		Type:         models.TaskEventMoved,
+		FromColumnID: &task.ColumnID,
+		ToColumnID:   &toColumnID,
+		ActorID:      actorID,
+		At:           now,
+	})
+	publishBoardEvent(ctx, task.BoardID, webhooks.EventTaskMoved, gin.H{
+		"id":           task.ID.Hex(),
+		"fromColumnId": task.ColumnID.Hex(),
+		"toColumnId":   toColumnID.Hex(),
+	})
+}
+
+// moveTaskUpdate สร้าง update สำหรับย้าย task เข้า column พร้อม stamp startedAt / completedAt
+// ตาม category ของ column ปลายทาง
+func moveTaskUpdate(task models.Task, column models.Column, now time.Time) bson.M {
+	set := bson.M{"columnId": column.ID}
+	unset := bson.M{}
+
+	switch column.Category {
+	case models.ColumnActive:
+		if task.StartedAt == nil {
+			set["startedAt"] = now
+		}
+		unset["completedAt"] = ""
+	case models.ColumnDone:
+		if task.StartedAt == nil {
+			set["startedAt"] = now
+		}
+		if task.CompletedAt == nil {
+			set["completedAt"] = now
+		}
+	default:
+		// กลับไป backlog = ยังไม่เสร็จ
+		unset["completedAt"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update
+}
+
+// parseDueDate รับได้ทั้ง RFC3339 และ YYYY-MM-DD (ค่าว่าง = ไม่มี due date)
+func parseDueDate(v string) (*primitive.DateTime, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dt := primitive.NewDateTimeFromTime(t)
+	return &dt, nil
+}
+
+// setTaskUpdate สร้าง update จาก field ที่แก้ ถ้า dueDate เปลี่ยนให้ล้าง remindedAt เพื่อให้แจ้งเตือนใหม่ตาม dueDate ใหม่
+func setTaskUpdate(set bson.M) bson.M {
+	update := bson.M{"$set": set}
+	if _, ok := set["dueDate"]; ok {
+		update["$unset"] = bson.M{"remindedAt": ""}
+	}
+	return update
+}
+
+// errInvalidPriority priority ที่ไม่ใช่ LOW / MEDIUM / HIGH
+var errInvalidPriority = apierror.Invalid("priority", "oneof", "priority must be LOW, MEDIUM or HIGH")
+
+// UpdateTaskRequest body ของ PATCH /tasks/:id (field ว่าง = ไม่เปลี่ยน)
+type UpdateTaskRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+	DueDate     string `json:"dueDate"`
+	// ส่งมาเมื่อต้องการเปลี่ยนคนรับผิดชอบ (แทนที่ทั้ง list)
+	Assignees *[]string `json:"assignees"`
+}
+
+// UpdateTask แก้ไข task (title, description, priority, dueDate)
 func UpdateTask(c *gin.Context) {
 	userSub := c.MustGet("userSub").(string)
 	userID, _ := primitive.ObjectIDFromHex(userSub)
@@ -166,31 +368,39 @@ func UpdateTask(c *gin.Context) {
 	taskID := c.Param("id")
 	taskOID, err := primitive.ObjectIDFromHex(taskID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		apierror.Abort(c, apierror.InvalidID("taskId"))
 		return
 	}
 
-	var input struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Priority    string `json:"priority"`
-	}
+	var input UpdateTaskRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	dueDate, err := parseDueDate(input.DueDate)
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("dueDate", "datetime", "invalid dueDate"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	taskCol := db.Database.Collection("tasks")
 
 	// หา task และตรวจสอบว่า user เป็นเจ้าของ project นี้
 	var task struct {
-		ColumnID primitive.ObjectID `bson:"columnId"`
+		Title     string               `bson:"title"`
+		ColumnID  primitive.ObjectID   `bson:"columnId"`
+		Assignees []primitive.ObjectID `bson:"assignees"`
 	}
 	if err := taskCol.FindOne(ctx, bson.M{"_id": taskOID}).Decode(&task); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrTaskNotFound))
 		return
 	}
 
@@ -200,7 +410,7 @@ func UpdateTask(c *gin.Context) {
 		BoardID primitive.ObjectID `bson:"boardId"`
 	}
 	if err := colCol.FindOne(ctx, bson.M{"_id": task.ColumnID}).Decode(&column); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "column not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
 		return
 	}
 
@@ -210,21 +420,19 @@ func UpdateTask(c *gin.Context) {
 		ProjectID primitive.ObjectID `bson:"projectId"`
 	}
 	if err := boardCol.FindOne(ctx, bson.M{"_id": column.BoardID}).Decode(&board); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "board not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrBoardNotFound))
 		return
 	}
 
 	projCol := db.Database.Collection("projects")
-	var proj struct {
-		OwnerID primitive.ObjectID `bson:"ownerId"`
-	}
+	var proj models.Project
 	if err := projCol.FindOne(ctx, bson.M{"_id": board.ProjectID}).Decode(&proj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "project not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to update this task"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to update this task"))
 		return
 	}
 
@@ -236,15 +444,90 @@ func UpdateTask(c *gin.Context) {
 		updateDoc["description"] = input.Description
 	}
 	if input.Priority != "" {
+		if !models.ValidTaskPriority(input.Priority) {
+			apierror.Abort(c, errInvalidPriority)
+			return
+		}
 		updateDoc["priority"] = input.Priority
 	}
+	if dueDate != nil {
+		updateDoc["dueDate"] = *dueDate
+	}
 
-	_, err = taskCol.UpdateByID(ctx, taskOID, bson.M{"$set": updateDoc})
+	// assignees ต้องเป็นสมาชิกของโปรเจกต์
+	var newAssignees []primitive.ObjectID
+	if input.Assignees != nil {
+		assignees := []primitive.ObjectID{}
+		seen := map[primitive.ObjectID]bool{}
+		for _, a := range *input.Assignees {
+			oid, err := primitive.ObjectIDFromHex(a)
+			if err != nil {
+				apierror.Abort(c, apierror.InvalidID("assigneeId"))
+				return
+			}
+			if !isProjectMember(proj, oid) {
+				apierror.Abort(c, apierror.ErrNotProjectMember.WithDetail("assignee is not a project member"))
+				return
+			}
+			if seen[oid] {
+				continue
+			}
+			seen[oid] = true
+			assignees = append(assignees, oid)
+		}
+		updateDoc["assignees"] = assignees
+
+		previous := map[primitive.ObjectID]bool{}
+		for _, a := range task.Assignees {
+			previous[a] = true
+		}
+		for _, a := range assignees {
+			if !previous[a] && a != userID {
+				newAssignees = append(newAssignees, a)
+			}
+		}
+	}
+	if len(updateDoc) == 0 {
+		apierror.Abort(c, apierror.ErrNothingToDo)
+		return
+	}
+
+	version, err := updateVersioned(ctx, taskCol, taskOID, want, setTaskUpdate(updateDoc))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierror.Abort(c, versionError(ctx, taskCol, taskOID, err, apierror.ErrTaskNotFound))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "updated"})
+
+	if err := webhooks.Publish(ctx, board.ProjectID, webhooks.EventTaskUpdated, gin.H{
+		"id":      taskOID.Hex(),
+		"changes": updateDoc,
+	}); err != nil {
+		slog.ErrorContext(ctx, "UPDATE_TASK: webhook error", "err", err)
+	}
+
+	// แจ้งเตือนคนที่เพิ่งถูก assign
+	title := task.Title
+	if input.Title != "" {
+		title = input.Title
+	}
+	notifyAssigned(ctx, newAssignees, title, board.ProjectID, taskOID)
+	setETag(c, version)
+	c.JSON(http.StatusOK, VersionResponse{Message: "updated", Version: version})
+}
+
+// notifyAssigned แจ้งเตือนคนที่เพิ่งถูก assign ให้ task
+func notifyAssigned(ctx context.Context, assignees []primitive.ObjectID, title string, projectID, taskID primitive.ObjectID) {
+	for _, a := range assignees {
+		if _, err := notify.Send(ctx, models.Notification{
+			UserID:    a,
+			Type:      models.NotificationTaskAssigned,
+			Title:     "You were assigned to \"" + title + "\"",
+			ProjectID: &projectID,
+			TaskID:    &taskID,
+		}); err != nil {
+			slog.ErrorContext(ctx, "TASK: notify error", "err", err)
+		}
+	}
 }
 
 // DeleteTask ลบ task
@@ -255,11 +538,16 @@ func DeleteTask(c *gin.Context) {
 	taskID := c.Param("id")
 	taskOID, err := primitive.ObjectIDFromHex(taskID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	taskCol := db.Database.Collection("tasks")
@@ -267,9 +555,10 @@ func DeleteTask(c *gin.Context) {
 	// หา task และตรวจสอบ permission
 	var task struct {
 		ColumnID primitive.ObjectID `bson:"columnId"`
+		BoardID  primitive.ObjectID `bson:"boardId"`
 	}
 	if err := taskCol.FindOne(ctx, bson.M{"_id": taskOID}).Decode(&task); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrTaskNotFound))
 		return
 	}
 
@@ -279,7 +568,7 @@ func DeleteTask(c *gin.Context) {
 		BoardID primitive.ObjectID `bson:"boardId"`
 	}
 	if err := colCol.FindOne(ctx, bson.M{"_id": task.ColumnID}).Decode(&column); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "column not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
 		return
 	}
 
@@ -288,7 +577,7 @@ func DeleteTask(c *gin.Context) {
 		ProjectID primitive.ObjectID `bson:"projectId"`
 	}
 	if err := boardCol.FindOne(ctx, bson.M{"_id": column.BoardID}).Decode(&board); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "board not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrBoardNotFound))
 		return
 	}
 
@@ -297,28 +586,54 @@ func DeleteTask(c *gin.Context) {
 		OwnerID primitive.ObjectID `bson:"ownerId"`
 	}
 	if err := projCol.FindOne(ctx, bson.M{"_id": board.ProjectID}).Decode(&proj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "project not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to delete this task"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to delete this task"))
 		return
 	}
 
-	result, err := taskCol.DeleteOne(ctx, bson.M{"_id": taskOID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+	if err := deleteVersioned(ctx, taskCol, taskOID, want); err != nil {
+		apierror.Abort(c, versionError(ctx, taskCol, taskOID, err, apierror.ErrTaskNotFound))
 		return
 	}
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
-		return
+
+	afterTaskDeleted(ctx, taskOID, task.BoardID, task.ColumnID, board.ProjectID, userID)
+	c.JSON(http.StatusOK, MessageResponse{Message: "deleted"})
+}
+
+// afterTaskDeleted ลบ dependency / comment / ไฟล์แนบของ task ที่ถูกลบ แล้วบันทึก event และส่ง webhook
+func afterTaskDeleted(ctx context.Context, taskID, boardID, columnID, projectID, actorID primitive.ObjectID) {
+	if _, err := db.Database.Collection("taskDependencies").DeleteMany(ctx, bson.M{
+		"$or": []bson.M{{"blockerId": taskID}, {"blockedId": taskID}},
+	}); err != nil {
+		slog.ErrorContext(ctx, "DELETE_TASK: delete dependencies error", "task", taskID, "err", err)
+	}
+	db.Database.Collection("comments").DeleteMany(ctx, bson.M{"taskId": taskID})
+	if err := deleteAttachments(ctx, bson.M{"taskId": taskID}); err != nil {
+		slog.ErrorContext(ctx, "DELETE_TASK: delete attachments error", "err", err)
+	}
+	recordTaskEvent(ctx, models.TaskEvent{
+		TaskID:       taskID,
+		BoardID:      boardID,
+		Type:         models.TaskEventDeleted,
+		FromColumnID: &columnID,
+		ActorID:      actorID,
+	})
+	if err := webhooks.Publish(ctx, projectID, webhooks.EventTaskDeleted, gin.H{"id": taskID.Hex()}); err != nil {
+		slog.ErrorContext(ctx, "DELETE_TASK: webhook error", "err", err)
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
-// UpdateColumn แก้ไข column name
+// UpdateColumnRequest body ของ PATCH /columns/:id (field ว่าง = ไม่เปลี่ยน)
+type UpdateColumnRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// UpdateColumn แก้ไข column name / category
 func UpdateColumn(c *gin.Context) {
 	userSub := c.MustGet("userSub").(string)
 	userID, _ := primitive.ObjectIDFromHex(userSub)
@@ -326,19 +641,39 @@ func UpdateColumn(c *gin.Context) {
 	columnID := c.Param("id")
 	colOID, err := primitive.ObjectIDFromHex(columnID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid column id"})
+		apierror.Abort(c, apierror.InvalidID("columnId"))
 		return
 	}
 
-	var input struct {
-		Name string `json:"name" binding:"required"`
-	}
+	var input UpdateColumnRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	updateDoc := bson.M{}
+	if input.Name != "" {
+		updateDoc["name"] = input.Name
+	}
+	if input.Category != "" {
+		category := models.ColumnCategory(input.Category)
+		if !category.Valid() {
+			apierror.Abort(c, apierror.Invalid("category", "oneof", "category must be backlog, active or done"))
+			return
+		}
+		updateDoc["category"] = category
+	}
+	if len(updateDoc) == 0 {
+		apierror.Abort(c, apierror.Invalid("name", "required", "name or category is required"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	colCol := db.Database.Collection("columns")
@@ -348,7 +683,7 @@ func UpdateColumn(c *gin.Context) {
 		BoardID primitive.ObjectID `bson:"boardId"`
 	}
 	if err := colCol.FindOne(ctx, bson.M{"_id": colOID}).Decode(&column); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "column not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
 		return
 	}
 
@@ -358,7 +693,7 @@ func UpdateColumn(c *gin.Context) {
 		ProjectID primitive.ObjectID `bson:"projectId"`
 	}
 	if err := boardCol.FindOne(ctx, bson.M{"_id": column.BoardID}).Decode(&board); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "board not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrBoardNotFound))
 		return
 	}
 
@@ -367,21 +702,28 @@ func UpdateColumn(c *gin.Context) {
 		OwnerID primitive.ObjectID `bson:"ownerId"`
 	}
 	if err := projCol.FindOne(ctx, bson.M{"_id": board.ProjectID}).Decode(&proj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "project not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to update this column"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to update this column"))
 		return
 	}
 
-	_, err = colCol.UpdateByID(ctx, colOID, bson.M{"$set": bson.M{"name": input.Name}})
+	version, err := updateVersioned(ctx, colCol, colOID, want, bson.M{"$set": updateDoc})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		apierror.Abort(c, versionError(ctx, colCol, colOID, err, apierror.ErrColumnNotFound))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "updated"})
+	if err := webhooks.Publish(ctx, board.ProjectID, webhooks.EventColumnUpdated, gin.H{
+		"id":      colOID.Hex(),
+		"changes": updateDoc,
+	}); err != nil {
+		slog.ErrorContext(ctx, "UPDATE_COLUMN: webhook error", "err", err)
+	}
+	setETag(c, version)
+	c.JSON(http.StatusOK, VersionResponse{Message: "updated", Version: version})
 }
 
 // DeleteColumn ลบ column และงานทั้งหมดในนั้น
@@ -392,11 +734,16 @@ func DeleteColumn(c *gin.Context) {
 	columnID := c.Param("id")
 	colOID, err := primitive.ObjectIDFromHex(columnID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid column id"})
+		apierror.Abort(c, apierror.InvalidID("columnId"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	colCol := db.Database.Collection("columns")
@@ -406,7 +753,7 @@ func DeleteColumn(c *gin.Context) {
 		BoardID primitive.ObjectID `bson:"boardId"`
 	}
 	if err := colCol.FindOne(ctx, bson.M{"_id": colOID}).Decode(&column); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "column not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrColumnNotFound))
 		return
 	}
 
@@ -416,7 +763,7 @@ func DeleteColumn(c *gin.Context) {
 		ProjectID primitive.ObjectID `bson:"projectId"`
 	}
 	if err := boardCol.FindOne(ctx, bson.M{"_id": column.BoardID}).Decode(&board); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "board not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrBoardNotFound))
 		return
 	}
 
@@ -425,28 +772,57 @@ func DeleteColumn(c *gin.Context) {
 		OwnerID primitive.ObjectID `bson:"ownerId"`
 	}
 	if err := projCol.FindOne(ctx, bson.M{"_id": board.ProjectID}).Decode(&proj); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "project not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to delete this column"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to delete this column"))
 		return
 	}
 
-	// ลบ tasks ที่อยู่ใน column นี้
+	// ลบ column ก่อน (ถ้า version ไม่ตรงจะยังไม่มีอะไรถูกลบ)
+	if err := deleteVersioned(ctx, colCol, colOID, want); err != nil {
+		apierror.Abort(c, versionError(ctx, colCol, colOID, err, apierror.ErrColumnNotFound))
+		return
+	}
+
+	// ลบ tasks ที่อยู่ใน column นี้ (พร้อม dependency ของ task เหล่านั้น)
 	taskCol := db.Database.Collection("tasks")
+	var taskIDs []primitive.ObjectID
+	if cur, err := taskCol.Find(ctx, bson.M{"columnId": colOID}); err == nil {
+		var tasks []models.Task
+		if err := cur.All(ctx, &tasks); err == nil {
+			for _, t := range tasks {
+				taskIDs = append(taskIDs, t.ID)
+				recordTaskEvent(ctx, models.TaskEvent{
+					TaskID:       t.ID,
+					BoardID:      t.BoardID,
+					Type:         models.TaskEventDeleted,
+					FromColumnID: &colOID,
+					ActorID:      userID,
+				})
+			}
+		}
+	}
+	if len(taskIDs) > 0 {
+		if _, err := db.Database.Collection("taskDependencies").DeleteMany(ctx, bson.M{
+			"$or": []bson.M{
+				{"blockerId": bson.M{"$in": taskIDs}},
+				{"blockedId": bson.M{"$in": taskIDs}},
+			},
+		}); err != nil {
+			slog.ErrorContext(ctx, "DELETE_COLUMN: delete dependencies error", "err", err)
+		}
+		db.Database.Collection("comments").DeleteMany(ctx, bson.M{"taskId": bson.M{"$in": taskIDs}})
+		if err := deleteAttachments(ctx, bson.M{"taskId": bson.M{"$in": taskIDs}}); err != nil {
+			slog.ErrorContext(ctx, "DELETE_COLUMN: delete attachments error", "err", err)
+		}
+	}
 	taskCol.DeleteMany(ctx, bson.M{"columnId": colOID})
 
-	// ลบ column
-	result, err := colCol.DeleteOne(ctx, bson.M{"_id": colOID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
-		return
-	}
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "column not found"})
-		return
+	if err := webhooks.Publish(ctx, board.ProjectID, webhooks.EventColumnDeleted, gin.H{"id": colOID.Hex()}); err != nil {
+		slog.ErrorContext(ctx, "DELETE_COLUMN: webhook error", "err", err)
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "deleted"})
 }
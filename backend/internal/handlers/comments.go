@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+)
+
+// ListComments GET /tasks/:id/comments
+func ListComments(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	taskOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	_, proj, err := projectForTask(ctx, taskOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to view this task"))
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cur, err := db.Database.Collection("comments").Find(ctx, bson.M{"taskId": taskOID}, opts)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	comments := []models.Comment{}
+	if err := cur.All(ctx, &comments); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateCommentRequest body ของ POST /tasks/:id/comments
+type CreateCommentRequest struct {
+	Body     string   `json:"body" binding:"required"`
+	Mentions []string `json:"mentions"`
+}
+
+// CreateComment POST /tasks/:id/comments
+// mentions คือ user id ที่ถูก @ ถึงในความเห็น (ต้องเป็นสมาชิกโปรเจกต์)
+func CreateComment(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	taskOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+
+	var input CreateCommentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	if strings.TrimSpace(input.Body) == "" {
+		apierror.Abort(c, apierror.Invalid("body", "required", "body is required"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	task, proj, err := projectForTask(ctx, taskOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to comment on this task"))
+		return
+	}
+
+	mentions := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, m := range input.Mentions {
+		oid, err := primitive.ObjectIDFromHex(m)
+		if err != nil {
+			apierror.Abort(c, apierror.InvalidID("mentions"))
+			return
+		}
+		if !isProjectMember(proj, oid) {
+			apierror.Abort(c, apierror.ErrNotProjectMember.WithDetail("mentioned user is not a project member"))
+			return
+		}
+		if !seen[oid] {
+			seen[oid] = true
+			mentions = append(mentions, oid)
+		}
+	}
+
+	comment := models.Comment{
+		ID:        primitive.NewObjectID(),
+		TaskID:    taskOID,
+		AuthorID:  userID,
+		Body:      input.Body,
+		Mentions:  mentions,
+		CreatedAt: time.Now(),
+	}
+	if _, err := db.Database.Collection("comments").InsertOne(ctx, comment); err != nil {
+		apierror.Abort(c, apierror.Internal("create failed", err))
+		return
+	}
+
+	for _, m := range mentions {
+		if m == userID {
+			continue
+		}
+		if _, err := notify.Send(ctx, models.Notification{
+			UserID:    m,
+			Type:      models.NotificationCommentMention,
+			Title:     "You were mentioned on \"" + task.Title + "\"",
+			ProjectID: &proj.ID,
+			TaskID:    &taskOID,
+		}); err != nil {
+			slog.ErrorContext(ctx, "COMMENT: notify error", "err", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext context สำหรับงานใน handler ที่ยังพก request id / trace ของ request ไว้ (log และ span จึงต่อกันได้)
+// แต่ไม่ถูกยกเลิกเมื่อ client ตัด connection งานที่เขียนหลายขั้นจึงไม่ค้างอยู่ครึ่งทาง
+func requestContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(c.Request.Context()), timeout)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// AddDependencyRequest body ของ POST /tasks/:id/dependencies
+type AddDependencyRequest struct {
+	BlockerID string `json:"blockerId" binding:"required"`
+}
+
+// AddTaskDependency ทำให้ task :id ถูก block โดย blockerId
+func AddTaskDependency(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	blockedOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+
+	var input AddDependencyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	blockerOID, err := primitive.ObjectIDFromHex(input.BlockerID)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("blockerId"))
+		return
+	}
+	if blockerOID == blockedOID {
+		apierror.Abort(c, apierror.ErrDependencyInvalid.WithDetail("a task cannot block itself"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	_, proj, err := projectForTask(ctx, blockedOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if proj.OwnerID != userID {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to update this task"))
+		return
+	}
+
+	_, blockerProj, err := projectForTask(ctx, blockerOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound.WithDetail("blocker task not found"))
+		return
+	}
+	if blockerProj.ID != proj.ID {
+		apierror.Abort(c, apierror.ErrDependencyInvalid.WithDetail("tasks must belong to the same project"))
+		return
+	}
+
+	dep := models.TaskDependency{
+		ProjectID:   proj.ID,
+		BlockerID:   blockerOID,
+		BlockedID:   blockedOID,
+		CreatedByID: userID,
+		CreatedAt:   time.Now(),
+	}
+	if aerr := insertDependency(ctx, &dep); aerr != nil {
+		apierror.Abort(c, aerr)
+		return
+	}
+	c.JSON(http.StatusCreated, dep)
+}
+
+// dependencyRetries จำนวนครั้งที่ลองเพิ่ม dependency ใหม่เมื่อมีคนเพิ่มใน project เดียวกันพร้อมกัน
+const dependencyRetries = 5
+
+// insertDependency ตรวจซ้ำ / cycle กับ dependency ทั้งหมดของ project แล้ว insert
+// กันสอง request ที่เพิ่มพร้อมกันแล้วรวมกันเป็น cycle ด้วย dependencyVersion ของ project:
+// อ่าน version ก่อนโหลด dependency, insert แล้วเพิ่ม version เฉพาะเมื่อยังเป็นค่าเดิม
+// ถ้ามีคนเพิ่มตัวอื่นไปก่อน ลบตัวที่เพิ่งใส่แล้วตรวจใหม่กับข้อมูลล่าสุด
+func insertDependency(ctx context.Context, dep *models.TaskDependency) *apierror.Error {
+	projCol := db.Database.Collection("projects")
+	depCol := db.Database.Collection("taskDependencies")
+
+	for attempt := 0; attempt < dependencyRetries; attempt++ {
+		var p struct {
+			DependencyVersion int64 `bson:"dependencyVersion"`
+		}
+		err := projCol.FindOne(ctx, bson.M{"_id": dep.ProjectID},
+			options.FindOne().SetProjection(bson.M{"dependencyVersion": 1})).Decode(&p)
+		if err != nil {
+			return apierror.Lookup(err, apierror.ErrProjectNotFound)
+		}
+
+		cur, err := depCol.Find(ctx, bson.M{"projectId": dep.ProjectID})
+		if err != nil {
+			return apierror.Internal("db error", err)
+		}
+		var deps []models.TaskDependency
+		if err := cur.All(ctx, &deps); err != nil {
+			return apierror.Internal("decode error", err)
+		}
+		for _, d := range deps {
+			if d.BlockerID == dep.BlockerID && d.BlockedID == dep.BlockedID {
+				return apierror.ErrDependencyExists
+			}
+		}
+		if createsDependencyCycle(deps, dep.BlockerID, dep.BlockedID) {
+			return apierror.ErrDependencyCycle
+		}
+
+		dep.ID = primitive.NewObjectID()
+		if _, err := depCol.InsertOne(ctx, dep); err != nil {
+			return apierror.Internal("create failed", err)
+		}
+		version := bson.A{p.DependencyVersion}
+		if p.DependencyVersion == 0 {
+			version = append(version, nil)
+		}
+		res, err := projCol.UpdateOne(ctx,
+			bson.M{"_id": dep.ProjectID, "dependencyVersion": bson.M{"$in": version}},
+			bson.M{"$inc": bson.M{"dependencyVersion": 1}})
+		if err == nil && res.MatchedCount == 1 {
+			return nil
+		}
+		if _, derr := depCol.DeleteOne(ctx, bson.M{"_id": dep.ID}); derr != nil {
+			slog.ErrorContext(ctx, "ADD_DEPENDENCY: rollback error", "dependency", dep.ID, "err", derr)
+		}
+		if err != nil {
+			return apierror.Internal("create failed", err)
+		}
+	}
+	return apierror.ErrDependencyConflict
+}
+
+// RemoveTaskDependency ลบความสัมพันธ์ blockerId -> task :id
+func RemoveTaskDependency(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	blockedOID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("taskId"))
+		return
+	}
+	blockerOID, err := primitive.ObjectIDFromHex(c.Param("blockerId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("blockerId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	_, proj, err := projectForTask(ctx, blockedOID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTaskNotFound)
+		return
+	}
+	if proj.OwnerID != userID {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to update this task"))
+		return
+	}
+
+	res, err := db.Database.Collection("taskDependencies").DeleteOne(ctx, bson.M{
+		"blockerId": blockerOID,
+		"blockedId": blockedOID,
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("delete failed", err))
+		return
+	}
+	if res.DeletedCount == 0 {
+		apierror.Abort(c, apierror.ErrDependencyNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "deleted"})
+}
+
+// createsDependencyCycle เช็คว่าถ้าเพิ่ม blocker -> blocked แล้วจะเกิด cycle หรือไม่
+// คือ blocker ถูก block (ทางอ้อม) โดย blocked อยู่แล้ว
+func createsDependencyCycle(deps []models.TaskDependency, blocker, blocked primitive.ObjectID) bool {
+	blockersOf := map[primitive.ObjectID][]primitive.ObjectID{}
+	for _, d := range deps {
+		blockersOf[d.BlockedID] = append(blockersOf[d.BlockedID], d.BlockerID)
+	}
+
+	seen := map[primitive.ObjectID]bool{blocker: true}
+	queue := []primitive.ObjectID{blocker}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == blocked {
+			return true
+		}
+		for _, next := range blockersOf[cur] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// unfinishedBlockers คืน id ของ blocker ที่ยังไม่เสร็จ (ยังไม่มี completedAt)
+func unfinishedBlockers(ctx context.Context, taskID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cur, err := db.Database.Collection("taskDependencies").Find(ctx, bson.M{"blockedId": taskID})
+	if err != nil {
+		return nil, err
+	}
+	var deps []models.TaskDependency
+	if err := cur.All(ctx, &deps); err != nil {
+		return nil, err
+	}
+	if len(deps) == 0 {
+		return nil, nil
+	}
+
+	blockerIDs := make([]primitive.ObjectID, 0, len(deps))
+	for _, d := range deps {
+		blockerIDs = append(blockerIDs, d.BlockerID)
+	}
+
+	taskCur, err := db.Database.Collection("tasks").Find(ctx, bson.M{"_id": bson.M{"$in": blockerIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var blockers []models.Task
+	if err := taskCur.All(ctx, &blockers); err != nil {
+		return nil, err
+	}
+
+	var pending []primitive.ObjectID
+	for _, t := range blockers {
+		if t.CompletedAt == nil {
+			pending = append(pending, t.ID)
+		}
+	}
+	return pending, nil
+}
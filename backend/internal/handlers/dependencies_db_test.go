@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+func TestInsertDependency(t *testing.T) {
+	ctx := useTestDatabase(t)
+	project := primitive.NewObjectID()
+	seed(t, ctx, "projects", models.Project{ID: project, Name: "p"})
+
+	add := func(blocker, blocked primitive.ObjectID) *apierror.Error {
+		return insertDependency(ctx, &models.TaskDependency{ProjectID: project, BlockerID: blocker, BlockedID: blocked})
+	}
+	if err := add(taskA, taskB); err != nil {
+		t.Fatal(err)
+	}
+	if err := add(taskB, taskC); err != nil {
+		t.Fatal(err)
+	}
+	if err := add(taskA, taskB); err != apierror.ErrDependencyExists {
+		t.Errorf("duplicate = %v, want DEPENDENCY_EXISTS", err)
+	}
+	if err := add(taskC, taskA); err != apierror.ErrDependencyCycle {
+		t.Errorf("C -> A = %v, want DEPENDENCY_CYCLE", err)
+	}
+}
+
+// สอง request ที่เพิ่ม A -> B กับ B -> A พร้อมกัน ต้องสำเร็จได้แค่ตัวเดียว
+func TestInsertDependencyConcurrent(t *testing.T) {
+	ctx := useTestDatabase(t)
+
+	for round := 0; round < 20; round++ {
+		project := primitive.NewObjectID()
+		seed(t, ctx, "projects", models.Project{ID: project, Name: "p"})
+
+		var wg sync.WaitGroup
+		errs := make([]*apierror.Error, 2)
+		for i, pair := range [][2]primitive.ObjectID{{taskA, taskB}, {taskB, taskA}} {
+			wg.Add(1)
+			go func(i int, blocker, blocked primitive.ObjectID) {
+				defer wg.Done()
+				errs[i] = insertDependency(ctx, &models.TaskDependency{ProjectID: project, BlockerID: blocker, BlockedID: blocked})
+			}(i, pair[0], pair[1])
+		}
+		wg.Wait()
+
+		n, err := db.Database.Collection("taskDependencies").CountDocuments(ctx, bson.M{"projectId": project})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || (errs[0] == nil) == (errs[1] == nil) {
+			t.Fatalf("round %d: %d edges stored, errors %v / %v", round, n, errs[0], errs[1])
+		}
+	}
+}
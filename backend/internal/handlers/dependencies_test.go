@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/models"
+)
+
+func TestCreatesDependencyCycle(t *testing.T) {
+	edge := func(blocker, blocked primitive.ObjectID) models.TaskDependency {
+		return models.TaskDependency{BlockerID: blocker, BlockedID: blocked}
+	}
+	cases := []struct {
+		name             string
+		deps             []models.TaskDependency
+		blocker, blocked primitive.ObjectID
+		want             bool
+	}{
+		{"empty", nil, taskA, taskB, false},
+		{"reverse edge", []models.TaskDependency{edge(taskB, taskA)}, taskA, taskB, true},
+		{"indirect", []models.TaskDependency{edge(taskB, taskC), edge(taskC, taskA)}, taskA, taskB, true},
+		{"same direction", []models.TaskDependency{edge(taskA, taskC), edge(taskC, taskB)}, taskA, taskB, false},
+		// A, B ถูก block โดย C ทั้งคู่ (diamond) ไม่ใช่ cycle
+		{"shared blocker", []models.TaskDependency{edge(taskC, taskA), edge(taskC, taskB), edge(taskD, taskC)}, taskA, taskB, false},
+		{"cycle elsewhere", []models.TaskDependency{edge(taskC, taskD), edge(taskD, taskC)}, taskA, taskB, false},
+	}
+	for _, tc := range cases {
+		if got := createsDependencyCycle(tc.deps, tc.blocker, tc.blocked); got != tc.want {
+			t.Errorf("%s: createsDependencyCycle = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// task block ตัวเองไม่ได้ (ตอบก่อนแตะ database)
+func TestAddTaskDependencyRejectsSelf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(apierror.Middleware())
+	r.POST("/tasks/:id/dependencies", func(c *gin.Context) {
+		c.Set("userSub", primitive.NewObjectID().Hex())
+		AddTaskDependency(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks/"+taskA.Hex()+"/dependencies",
+		strings.NewReader(`{"blockerId":"`+taskA.Hex()+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "DEPENDENCY_INVALID") {
+		t.Fatalf("self dependency = %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/scheduler"
+)
+
+// BuildVersion เวอร์ชันของ binary (main ตั้งจาก version ที่ใส่ตอน build)
+var BuildVersion = "dev"
+
+// Workers background jobs ที่รายงานสถานะใน /readyz (main ตั้งหลัง Start)
+var Workers *scheduler.Scheduler
+
+// readyPingTimeout เวลาที่รอ MongoDB ตอบ ping ใน /readyz
+const readyPingTimeout = 2 * time.Second
+
+var (
+	startedAt    = time.Now()
+	shuttingDown atomic.Bool
+)
+
+// BeginShutdown ให้ /readyz ตอบ 503 เพื่อให้ load balancer เลิกส่ง request ใหม่มาระหว่าง drain
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// Livez GET /livez
+// process ยังตอบ request ได้ (ไม่ตรวจ dependency เพื่อไม่ให้ orchestrator restart เพราะ MongoDB ล่ม)
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz GET /readyz
+// พร้อมรับ traffic เมื่อ ping MongoDB ผ่าน, database อยู่ที่ schema version ที่ code นี้ต้องการ
+// และยังไม่เริ่มปิด server สถานะของ background jobs รายงานไว้ดูแต่ไม่ทำให้ไม่พร้อม
+// endpoint นี้ไม่ต้อง login จึงตอบแค่ชื่อ check กับสถานะ รายละเอียด error อยู่ใน log
+func Readyz(c *gin.Context) {
+	ready := true
+	checks := gin.H{}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := db.Client.Ping(ctx, nil); err != nil {
+		ready = false
+		slog.ErrorContext(ctx, "READYZ: mongo ping failed", "err", err)
+		checks["mongo"] = gin.H{"status": "error"}
+		checks["migrations"] = gin.H{"status": "unknown", "expected": db.SchemaVersion}
+	} else {
+		checks["mongo"] = gin.H{"status": "ok", "latencyMs": time.Since(start).Milliseconds()}
+
+		applied, err := db.AppliedSchemaVersion(ctx)
+		switch {
+		case err != nil:
+			ready = false
+			slog.ErrorContext(ctx, "READYZ: schema version check failed", "err", err)
+			checks["migrations"] = gin.H{"status": "error", "expected": db.SchemaVersion}
+		case applied < db.SchemaVersion:
+			ready = false
+			checks["migrations"] = gin.H{"status": "pending", "expected": db.SchemaVersion, "applied": applied}
+		default:
+			checks["migrations"] = gin.H{"status": "ok", "expected": db.SchemaVersion, "applied": applied}
+		}
+	}
+
+	if shuttingDown.Load() {
+		ready = false
+		checks["shutdown"] = gin.H{"status": "draining"}
+	}
+
+	workers := gin.H{"running": false, "jobs": []scheduler.JobStatus{}}
+	if Workers != nil {
+		running, jobs := Workers.Status()
+		workers = gin.H{"running": running, "jobs": jobs}
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, gin.H{
+		"status":  status,
+		"checks":  checks,
+		"workers": workers,
+		"build":   buildInfo(),
+	})
+}
+
+// buildInfo เวอร์ชัน, commit และ Go version ของ binary ที่กำลังรัน
+func buildInfo() gin.H {
+	info := gin.H{
+		"version":   BuildVersion,
+		"goVersion": runtime.Version(),
+		"startedAt": startedAt.UTC(),
+		"uptime":    time.Since(startedAt).Round(time.Second).String(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info["commit"] = s.Value
+			case "vcs.time":
+				info["commitTime"] = s.Value
+			case "vcs.modified":
+				info["dirty"] = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
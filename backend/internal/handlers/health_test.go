@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/db"
+)
+
+// /readyz ไม่ต้อง login ห้ามส่งข้อความ error ของ MongoDB ออกไป
+func TestReadyzHidesErrors(t *testing.T) {
+	client, err := mongo.Connect(context.Background(),
+		options.Client().ApplyURI("mongodb://127.0.0.1:1/?serverSelectionTimeoutMS=100&connectTimeoutMS=100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := db.Client
+	db.Client = client
+	t.Cleanup(func() {
+		db.Client = prev
+		client.Disconnect(context.Background())
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", Readyz)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+	var body struct {
+		Checks map[string]map[string]interface{} `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	mongoCheck := body.Checks["mongo"]
+	if mongoCheck["status"] != "error" {
+		t.Errorf("mongo check = %v", mongoCheck)
+	}
+	for name, check := range body.Checks {
+		if _, ok := check["error"]; ok {
+			t.Errorf("check %q exposes error: %v", name, check)
+		}
+	}
+}
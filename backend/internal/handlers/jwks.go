@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/utils"
+)
+
+// JWKS GET /.well-known/jwks.json
+// public key ที่ใช้ตรวจ token ของระบบ (รวม key เก่าที่ยังไม่หมดช่วง rotate)
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
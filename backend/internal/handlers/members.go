@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+)
+
+// InviteMemberRequest body ของ POST /projects/:id/members (role ไม่ใส่ = MEMBER)
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+// TransferProjectRequest body ของการโอนโปรเจกต์ (ทั้งเจ้าของและ admin)
+type TransferProjectRequest struct {
+	NewOwnerID string `json:"newOwnerId" binding:"required"`
+}
+
+// TransferProjectResponse ผลของการโอนโปรเจกต์
+type TransferProjectResponse struct {
+	Message string `json:"message"`
+	OwnerID string `json:"ownerId"`
+}
+
+// InviteProjectMember เชิญผู้ใช้ (ด้วย email) เข้าโปรเจกต์ เฉพาะเจ้าของโปรเจกต์เท่านั้น
+func InviteProjectMember(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	pid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+
+	var input InviteMemberRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	role := models.RoleMember
+	if input.Role != "" {
+		role = models.Role(input.Role)
+		if role != models.RoleAdmin && role != models.RoleMember && role != models.RoleViewer {
+			apierror.Abort(c, apierror.Invalid("role", "oneof", "role must be ADMIN, MEMBER or VIEWER"))
+			return
+		}
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	projCol := db.Database.Collection("projects")
+	var proj models.Project
+	if err := projCol.FindOne(ctx, bson.M{"_id": pid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return
+	}
+	if proj.OwnerID != userID {
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to invite members to this project"))
+		return
+	}
+
+	var invitee models.User
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"email": email}).Decode(&invitee); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	if isProjectMember(proj, invitee.ID) {
+		apierror.Abort(c, apierror.ErrAlreadyMember)
+		return
+	}
+
+	member := models.ProjectMember{UserID: invitee.ID, Role: role}
+	_, err = projCol.UpdateOne(ctx,
+		bson.M{"_id": pid, "members.userId": bson.M{"$ne": invitee.ID}},
+		bumpVersion(bson.M{
+			"$push": bson.M{"members": member},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		}),
+	)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+
+	if _, err := notify.Send(ctx, models.Notification{
+		UserID:    invitee.ID,
+		Type:      models.NotificationProjectInvite,
+		Title:     "You were added to project \"" + proj.Name + "\"",
+		ProjectID: &pid,
+	}); err != nil {
+		slog.ErrorContext(ctx, "INVITE: notify error", "err", err)
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// TransferProject POST /projects/:id/transfer
+// เจ้าของโอนโปรเจกต์ให้สมาชิกคนอื่น เจ้าของเดิมยังอยู่ในโปรเจกต์ในฐานะ ADMIN
+func TransferProject(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	pid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+	var input TransferProjectRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	newOwnerID, err := primitive.ObjectIDFromHex(input.NewOwnerID)
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("newOwnerId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": pid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return
+	}
+	if proj.OwnerID != userID {
+		apierror.Abort(c, apierror.ErrOwnerRequired.WithDetail("only the project owner can transfer ownership"))
+		return
+	}
+	if newOwnerID == userID {
+		apierror.Abort(c, apierror.ErrAlreadyOwner.WithDetail("you already own this project"))
+		return
+	}
+	if !isProjectMember(proj, newOwnerID) {
+		apierror.Abort(c, apierror.ErrNotProjectMember.WithDetail("new owner must be a member of the project"))
+		return
+	}
+	var newOwner models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": newOwnerID}).Decode(&newOwner); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound.WithDetail("new owner not found")))
+		return
+	}
+	if newOwner.Disabled {
+		apierror.Abort(c, apierror.ErrNewOwnerDisabled)
+		return
+	}
+
+	if err := transferProjectOwnership(ctx, proj, newOwnerID); err != nil {
+		if errors.Is(err, errOwnerChanged) {
+			apierror.Abort(c, apierror.ErrOwnerChanged)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("transfer failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, TransferProjectResponse{Message: "project transferred", OwnerID: newOwnerID.Hex()})
+}
+
+// LeaveProject POST /projects/:id/leave
+// สมาชิกออกจากโปรเจกต์เอง (เจ้าของต้องโอนโปรเจกต์ให้คนอื่นก่อน)
+func LeaveProject(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	pid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": pid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return
+	}
+	if !isProjectMember(proj, userID) {
+		apierror.Abort(c, apierror.ErrProjectNotFound)
+		return
+	}
+	if proj.OwnerID == userID {
+		apierror.Abort(c, apierror.ErrOwnerCannotLeave)
+		return
+	}
+
+	if err := removeProjectMember(ctx, proj.ID, userID); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "left project"})
+}
+
+// removeProjectMember เอา user ออกจากสมาชิก และออกจาก assignees ของ task ในโปรเจกต์
+func removeProjectMember(ctx context.Context, projectID, userID primitive.ObjectID) error {
+	if _, err := db.Database.Collection("projects").UpdateByID(ctx, projectID, bumpVersion(bson.M{
+		"$pull": bson.M{"members": bson.M{"userId": userID}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	})); err != nil {
+		return err
+	}
+
+	boardIDs, err := boardIDsForProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if len(boardIDs) == 0 {
+		return nil
+	}
+	_, err = db.Database.Collection("tasks").UpdateMany(ctx,
+		bson.M{"boardId": bson.M{"$in": boardIDs}, "assignees": userID},
+		bumpVersion(bson.M{"$pull": bson.M{"assignees": userID}}),
+	)
+	return err
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+)
+
+// inAppFilter คือ notification ของ user ที่แสดงในแอปได้
+// (notification ที่ถูกเก็บไว้แค่เพื่อกันส่งอีเมลซ้ำจะมี inApp = false)
+func inAppFilter(userID primitive.ObjectID) bson.M {
+	return bson.M{"userId": userID, "inApp": bson.M{"$ne": false}}
+}
+
+// ListNotifications GET /notifications?unread=true&limit=50
+func ListNotifications(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	limit := int64(50)
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 || n > 200 {
+			apierror.Abort(c, apierror.Invalid("limit", "range", "limit must be between 1 and 200"))
+			return
+		}
+		limit = n
+	}
+
+	filter := inAppFilter(userID)
+	if c.Query("unread") == "true" {
+		filter["read"] = false
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cur, err := db.Database.Collection("notifications").Find(ctx, filter, opts)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	notifications := []models.Notification{}
+	if err := cur.All(ctx, &notifications); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// UnreadCountResponse ผลของ GET /notifications/unread-count
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// MarkAllReadResponse ผลของ POST /notifications/read-all
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// UnreadNotificationCount GET /notifications/unread-count
+func UnreadNotificationCount(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	filter := inAppFilter(userID)
+	filter["read"] = false
+	count, err := db.Database.Collection("notifications").CountDocuments(ctx, filter)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("count error", err))
+		return
+	}
+	c.JSON(http.StatusOK, UnreadCountResponse{Unread: count})
+}
+
+// MarkNotificationRead PATCH /notifications/:id/read
+func MarkNotificationRead(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("notificationId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	// filter ด้วย userId ด้วย เพื่อไม่ให้ mark ของคนอื่นได้
+	res, err := db.Database.Collection("notifications").UpdateOne(ctx,
+		bson.M{"_id": oid, "userId": userID},
+		bson.M{"$set": bson.M{"read": true}},
+	)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	if res.MatchedCount == 0 {
+		apierror.Abort(c, apierror.ErrNotificationNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "updated"})
+}
+
+// MarkAllNotificationsRead POST /notifications/read-all
+func MarkAllNotificationsRead(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	res, err := db.Database.Collection("notifications").UpdateMany(ctx,
+		bson.M{"userId": userID, "read": false},
+		bson.M{"$set": bson.M{"read": true}},
+	)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, MarkAllReadResponse{Updated: res.ModifiedCount})
+}
+
+// GetNotificationPreferences GET /me/notification-preferences
+func GetNotificationPreferences(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	var u models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	c.JSON(http.StatusOK, notify.Preferences(u))
+}
+
+// UpdateNotificationPreferences PUT /me/notification-preferences
+// body: {"task_assigned": {"inApp": true, "email": false}, ...} ส่งมาเฉพาะ event ที่ต้องการเปลี่ยน
+func UpdateNotificationPreferences(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	var input map[models.NotificationType]models.NotificationChannels
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.ErrMalformedBody)
+		return
+	}
+
+	defaults := notify.DefaultPreferences()
+	set := bson.M{}
+	for t, ch := range input {
+		if _, ok := defaults[t]; !ok {
+			apierror.Abort(c, apierror.Invalid(string(t), "oneof", "unknown notification type: "+string(t)))
+			return
+		}
+		set["notificationPrefs."+string(t)] = ch
+	}
+	if len(set) == 0 {
+		apierror.Abort(c, apierror.ErrNothingToDo.WithDetail("no preferences given"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	usersColl := db.Database.Collection("users")
+	if _, err := usersColl.UpdateByID(ctx, userID, bson.M{"$set": set}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+
+	var u models.User
+	if err := usersColl.FindOne(ctx, bson.M{"_id": userID}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	c.JSON(http.StatusOK, notify.Preferences(u))
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/oidc"
+)
+
+// OIDCProviders provider ที่เปิดให้ login ผ่าน SSO (ตั้งค่าใน main จาก config)
+var OIDCProviders = map[string]*oidc.Provider{}
+
+// OIDCSuccessRedirect หน้า frontend ที่รับผล login ผ่าน URL fragment (ว่าง = ตอบเป็น JSON)
+var OIDCSuccessRedirect string
+
+// oidcStateTTL เวลาที่ user มีให้ login ที่ IdP ให้เสร็จ
+const oidcStateTTL = 10 * time.Minute
+
+// oidcLoginState เก็บ state, PKCE verifier และ nonce ระหว่างรอ callback
+type oidcLoginState struct {
+	State     string    `bson:"_id"`
+	Provider  string    `bson:"provider"`
+	Verifier  string    `bson:"verifier"`
+	Nonce     string    `bson:"nonce"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// OIDCProvidersResponse ผลของ GET /auth/oidc/providers
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// ListOIDCProviders GET /auth/oidc/providers
+func ListOIDCProviders(c *gin.Context) {
+	names := make([]string, 0, len(OIDCProviders))
+	for name := range OIDCProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.JSON(http.StatusOK, OIDCProvidersResponse{Providers: names})
+}
+
+// OIDCLogin GET /auth/oidc/:provider/login
+// redirect ไปหน้า login ของ IdP (authorization code + PKCE)
+func OIDCLogin(c *gin.Context) {
+	p, ok := OIDCProviders[c.Param("provider")]
+	if !ok {
+		apierror.Abort(c, apierror.ErrProviderNotFound)
+		return
+	}
+
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not start login", err))
+		return
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not start login", err))
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not start login", err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 10*time.Second)
+	defer cancel()
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		slog.ErrorContext(ctx, "OIDC: auth url error", "err", err)
+		apierror.Abort(c, apierror.ErrIdentityUnavailable)
+		return
+	}
+
+	_, err = db.Database.Collection("oidcLoginStates").InsertOne(ctx, oidcLoginState{
+		State:     state,
+		Provider:  p.Name,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not start login", err))
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback GET /auth/oidc/:provider/callback
+// แลก code เป็น ID token, หา/สร้าง user จาก sub หรือ email ที่ verify แล้ว แล้วออก token ตามปกติ
+func OIDCCallback(c *gin.Context) {
+	p, ok := OIDCProviders[c.Param("provider")]
+	if !ok {
+		apierror.Abort(c, apierror.ErrProviderNotFound)
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		oidcFail(c, apierror.ErrIdentityProvider.WithDetail("identity provider error: "+e))
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		oidcFail(c, apierror.Invalid("code", "required", "code and state are required"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 15*time.Second)
+	defer cancel()
+
+	// state ใช้ได้ครั้งเดียว
+	var st oidcLoginState
+	err := db.Database.Collection("oidcLoginStates").FindOneAndDelete(ctx, bson.M{
+		"_id":      state,
+		"provider": p.Name,
+	}).Decode(&st)
+	if err != nil || time.Now().After(st.ExpiresAt) {
+		oidcFail(c, apierror.ErrInvalidLoginState)
+		return
+	}
+
+	claims, err := p.Exchange(ctx, code, st.Verifier, st.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "OIDC: exchange error", "err", err)
+		oidcFail(c, apierror.ErrIdentityProvider)
+		return
+	}
+
+	u, err := oidcUser(ctx, p.Name, claims)
+	if err != nil {
+		if errors.Is(err, apierror.ErrEmailNotVerified) {
+			oidcFail(c, apierror.ErrEmailNotVerified)
+			return
+		}
+		slog.ErrorContext(ctx, "OIDC: link user error", "err", err)
+		oidcFail(c, apierror.Internal("could not link account", err))
+		return
+	}
+
+	if u.Disabled {
+		oidcFail(c, apierror.ErrAccountDisabled)
+		return
+	}
+
+	payload, err := loginPayload(u)
+	if err != nil {
+		slog.ErrorContext(ctx, "OIDC: sign token error", "err", err)
+		oidcFail(c, apierror.Internal("could not sign token", err))
+		return
+	}
+
+	// ตั้ง OIDCSuccessRedirect (เช่นหน้า frontend) เพื่อส่ง token กลับใน URL fragment
+	if target := OIDCSuccessRedirect; target != "" {
+		frag := url.Values{}
+		if payload.AccessToken != "" {
+			frag.Set("accessToken", payload.AccessToken)
+		}
+		if payload.TwoFactorRequired {
+			frag.Set("twoFactorRequired", "true")
+			frag.Set("challengeToken", payload.ChallengeToken)
+		}
+		c.Redirect(http.StatusFound, target+"#"+frag.Encode())
+		return
+	}
+	c.JSON(http.StatusOK, payload)
+}
+
+// oidcUser หา user ที่ผูกกับ (provider, sub) ไว้แล้ว ถ้าไม่มีจะผูกกับ user ที่มี email ตรงกัน
+// หรือสร้าง user ใหม่ (ต้องเป็น email ที่ IdP verify แล้วเท่านั้น)
+func oidcUser(ctx context.Context, provider string, claims *oidc.IDClaims) (models.User, error) {
+	col := db.Database.Collection("users")
+	identity := models.OIDCIdentity{Provider: provider, Subject: claims.Subject}
+
+	var u models.User
+	err := col.FindOne(ctx, bson.M{"oidcIdentities": bson.M{"$elemMatch": bson.M{
+		"provider": provider,
+		"subject":  claims.Subject,
+	}}}).Decode(&u)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return u, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return u, apierror.ErrEmailNotVerified
+	}
+
+	err = col.FindOne(ctx, bson.M{"email": email}).Decode(&u)
+	if err == nil {
+		_, err = col.UpdateByID(ctx, u.ID, bson.M{"$addToSet": bson.M{"oidcIdentities": identity}})
+		return u, err
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return u, err
+	}
+
+	name := claims.Name
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	u = models.User{
+		ID:             primitive.NewObjectID(),
+		Name:           name,
+		Email:          email,
+		Role:           models.RoleMember,
+		OIDCIdentities: []models.OIDCIdentity{identity},
+	}
+	// ไม่มี passwordHash: login ได้ทาง SSO หรือตั้งรหัสผ่านผ่าน forgot-password
+	_, err = col.InsertOne(ctx, bson.M{
+		"_id":            u.ID,
+		"name":           u.Name,
+		"email":          u.Email,
+		"passwordHash":   "",
+		"role":           u.Role,
+		"oidcIdentities": u.OIDCIdentities,
+		"createdAt":      time.Now(),
+	})
+	return u, err
+}
+
+// oidcFail ตอบ error ของ callback เป็น problem+json หรือ redirect กลับ frontend พร้อม error และ code
+func oidcFail(c *gin.Context, e *apierror.Error) {
+	if target := OIDCSuccessRedirect; target != "" {
+		c.Redirect(http.StatusFound, target+"#"+url.Values{"error": {e.Detail}, "code": {e.Code}}.Encode())
+		return
+	}
+	apierror.Abort(c, e)
+}
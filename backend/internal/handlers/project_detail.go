@@ -1,136 +1,192 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
+	"mini-taskmgr-backend/internal/apierror"
 	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
 )
 
+// ProjectDetailResponse ผลของ GET /projects/:id (ข้อมูลทั้งหมดที่หน้า board ใช้)
+// board เป็น null ถ้าโปรเจกต์ยังไม่มี board, header ETag คือ version ของโปรเจกต์ (column / task มี version ของตัวเองใน body)
+type ProjectDetailResponse struct {
+	Project ProjectSummary `json:"project"`
+	Board   *BoardSummary  `json:"board"`
+	Columns []BoardColumn  `json:"columns"`
+	Tasks   []BoardTask    `json:"tasks"`
+}
+
+// ProjectSummary ข้อมูลโปรเจกต์แบบย่อ
+type ProjectSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     int64  `json:"version"`
+}
+
+// BoardSummary ข้อมูล board แบบย่อ
+type BoardSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BoardColumn column บน board
+type BoardColumn struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Position int                   `json:"position"`
+	Category models.ColumnCategory `json:"category"`
+	Version  int64                 `json:"version"`
+}
+
+// BoardTask task บน board (blockedBy คือ id ของ task ที่ block task นี้อยู่)
+type BoardTask struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Priority    string              `json:"priority"`
+	DueDate     *primitive.DateTime `json:"dueDate"`
+	ColumnID    string              `json:"columnId"`
+	BlockedBy   []string            `json:"blockedBy"`
+	StartedAt   *time.Time          `json:"startedAt"`
+	CompletedAt *time.Time          `json:"completedAt"`
+	Version     int64               `json:"version"`
+}
+
 func GetProjectDetail(c *gin.Context) {
 	projectID := c.Param("id")
 
 	pid, err := primitive.ObjectIDFromHex(projectID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		apierror.Abort(c, apierror.InvalidID("projectId"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	// 1) project
-	var project bson.M
+	var project models.Project
 	if err := db.Database.Collection("projects").
 		FindOne(ctx, bson.M{"_id": pid}).
 		Decode(&project); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
+	out := ProjectDetailResponse{
+		Project: ProjectSummary{
+			ID:          project.ID.Hex(),
+			Name:        project.Name,
+			Description: project.Description,
+			Version:     project.Version,
+		},
+		Columns: []BoardColumn{},
+		Tasks:   []BoardTask{},
+	}
+	setETag(c, project.Version)
 
 	// 2) board (เอาบอร์ดแรกของโปรเจค)
-	var board bson.M
-	_ = db.Database.Collection("boards").
+	var board models.Board
+	if err := db.Database.Collection("boards").
 		FindOne(ctx, bson.M{"projectId": pid}).
-		Decode(&board)
-
-	// ถ้ายังไม่มี board => ส่งเปล่า ๆ กลับไป
-	if board == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"project": gin.H{
-				"id":          project["_id"].(primitive.ObjectID).Hex(),
-				"name":        project["name"],
-				"description": project["description"],
-			},
-			"board":   nil,
-			"columns": []gin.H{},
-			"tasks":   []gin.H{},
-		})
+		Decode(&board); err != nil {
+		// ถ้ายังไม่มี board => ส่งเปล่า ๆ กลับไป
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusOK, out)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("cannot load board", err))
 		return
 	}
-
-	boardID, _ := board["_id"].(primitive.ObjectID)
+	out.Board = &BoardSummary{ID: board.ID.Hex(), Name: board.Name}
 
 	// 3) columns
 	colCur, err := db.Database.Collection("columns").
-		Find(ctx, bson.M{"boardId": boardID})
+		Find(ctx, bson.M{"boardId": board.ID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot load columns"})
+		apierror.Abort(c, apierror.Internal("cannot load columns", err))
 		return
 	}
-	var columnsRaw []bson.M
-	if err := colCur.All(ctx, &columnsRaw); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot decode columns"})
+	var columns []models.Column
+	if err := colCur.All(ctx, &columns); err != nil {
+		apierror.Abort(c, apierror.Internal("cannot decode columns", err))
 		return
 	}
 
 	// 4) tasks (ทั้งหมดในบอร์ดนี้)
 	taskCur, err := db.Database.Collection("tasks").
-		Find(ctx, bson.M{"boardId": boardID})
+		Find(ctx, bson.M{"boardId": board.ID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot load tasks"})
+		apierror.Abort(c, apierror.Internal("cannot load tasks", err))
 		return
 	}
-	var tasksRaw []bson.M
-	if err := taskCur.All(ctx, &tasksRaw); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot decode tasks"})
+	var tasks []models.Task
+	if err := taskCur.All(ctx, &tasks); err != nil {
+		apierror.Abort(c, apierror.Internal("cannot decode tasks", err))
 		return
 	}
 
-	// map columns -> ส่งเป็น JSON ที่ frontend อ่านง่าย
-	columnsOut := []gin.H{}
-	for _, col := range columnsRaw {
-		idHex := ""
-		if oid, ok := col["_id"].(primitive.ObjectID); ok {
-			idHex = oid.Hex()
-		}
-		columnsOut = append(columnsOut, gin.H{
-			"id":       idHex,
-			"name":     col["name"],
-			"position": col["position"],
+	// 5) dependencies ของ project -> map task id -> blockedBy
+	depCur, err := db.Database.Collection("taskDependencies").
+		Find(ctx, bson.M{"projectId": pid})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("cannot load dependencies", err))
+		return
+	}
+	var deps []models.TaskDependency
+	if err := depCur.All(ctx, &deps); err != nil {
+		apierror.Abort(c, apierror.Internal("cannot decode dependencies", err))
+		return
+	}
+	blockedBy := map[primitive.ObjectID][]string{}
+	for _, d := range deps {
+		blockedBy[d.BlockedID] = append(blockedBy[d.BlockedID], d.BlockerID.Hex())
+	}
+
+	for _, col := range columns {
+		out.Columns = append(out.Columns, BoardColumn{
+			ID:       col.ID.Hex(),
+			Name:     col.Name,
+			Position: col.Position,
+			Category: columnCategoryOf(col.Category),
+			Version:  col.Version,
 		})
 	}
 
-	// map tasks -> ส่ง columnId เป็น hex string ด้วย
-	tasksOut := []gin.H{}
-	for _, t := range tasksRaw {
-		tid := ""
-		if oid, ok := t["_id"].(primitive.ObjectID); ok {
-			tid = oid.Hex()
-		}
-		colID := ""
-		if oid, ok := t["columnId"].(primitive.ObjectID); ok {
-			colID = oid.Hex()
+	for _, t := range tasks {
+		taskBlockedBy := blockedBy[t.ID]
+		if taskBlockedBy == nil {
+			taskBlockedBy = []string{}
 		}
-		tasksOut = append(tasksOut, gin.H{
-			"id":          tid,
-			"title":       t["title"],
-			"description": t["description"],
-			"priority":    t["priority"],
-			"columnId":    colID,
+		out.Tasks = append(out.Tasks, BoardTask{
+			ID:          t.ID.Hex(),
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			DueDate:     t.DueDate,
+			ColumnID:    t.ColumnID.Hex(),
+			BlockedBy:   taskBlockedBy,
+			StartedAt:   t.StartedAt,
+			CompletedAt: t.CompletedAt,
+			Version:     t.Version,
 		})
 	}
 
-	projectOut := gin.H{
-		"id":          project["_id"].(primitive.ObjectID).Hex(),
-		"name":        project["name"],
-		"description": project["description"],
-	}
+	c.JSON(http.StatusOK, out)
+}
 
-	boardOut := gin.H{
-		"id":   boardID.Hex(),
-		"name": board["name"],
+// columnCategoryOf column เก่าที่ยังไม่มี category ถือเป็น backlog
+func columnCategoryOf(cc models.ColumnCategory) models.ColumnCategory {
+	if cc.Valid() {
+		return cc
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"project": projectOut,
-		"board":   boardOut,
-		"columns": columnsOut,
-		"tasks":   tasksOut,
-	})
+	return models.ColumnBacklog
 }
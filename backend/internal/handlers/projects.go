@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -9,20 +9,36 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"mini-taskmgr-backend/internal/apierror"
 	"mini-taskmgr-backend/internal/db"
 	"mini-taskmgr-backend/internal/models"
 )
 
 // ProjectResponse คือ shape ที่ frontend จะใช้ในหน้า /projects
+// createdAt / updatedAt เป็น RFC 3339 ให้ frontend จัดรูปแบบเอง
 type ProjectResponse struct {
-	ID          string `json:"id"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Color       string     `json:"color,omitempty"`
+	TaskCount   int64      `json:"taskCount"`
+	OpenTasks   int64      `json:"openTaskCount"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	Version     int64      `json:"version"`
+}
+
+// CreateProjectRequest body ของ POST /projects (ไม่ส่ง color จะเลือกให้)
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+}
+
+// UpdateProjectRequest body ของ PATCH /projects/:id
+type UpdateProjectRequest struct {
 	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Color       string `json:"color,omitempty"`
-	TaskCount   int64  `json:"taskCount"`
-	TasksCount  int64  `json:"tasksCount"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-	UpdatedAt   string `json:"updatedAt,omitempty"`
+	Description string `json:"description"`
 }
 
 // ListProjects ดึงโปรเจกต์ทั้งหมดของผู้ใช้ แล้วรวม taskCount
@@ -31,33 +47,37 @@ func ListProjects(c *gin.Context) {
 	userSub := c.GetString("userSub")
 	// ถ้าไม่มี userSub ให้คืน 401
 	if userSub == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		apierror.Abort(c, apierror.ErrUnauthorized.WithDetail("missing token"))
 		return
 	}
 
 	// แปลงเป็น ObjectID เพื่อใช้ค้นหา ownerId ที่เก็บเป็น ObjectID ใน DB
 	uid, err := primitive.ObjectIDFromHex(userSub)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		apierror.Abort(c, apierror.InvalidID("userId"))
 		return
 	}
 
 	projColl := db.Database.Collection("projects")
 	taskColl := db.Database.Collection("tasks")
+	ctx := c.Request.Context()
 
-	// ดึงทุกโปรเจกต์ที่ user นี้เป็นเจ้าของ (ownerId เก็บเป็น ObjectID)
-	cur, err := projColl.Find(context.Background(), bson.M{
-		"ownerId": uid,
+	// ดึงทุกโปรเจกต์ที่ user นี้เป็นเจ้าของหรือเป็นสมาชิก (ownerId เก็บเป็น ObjectID)
+	cur, err := projColl.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"ownerId": uid},
+			{"members.userId": uid},
+		},
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		apierror.Abort(c, apierror.Internal("db error", err))
 		return
 	}
-	defer cur.Close(context.Background())
+	defer cur.Close(ctx)
 
-	var results []ProjectResponse
+	results := []ProjectResponse{}
 
-	for cur.Next(context.Background()) {
+	for cur.Next(ctx) {
 		var p struct {
 			ID          primitive.ObjectID `bson:"_id"`
 			Name        string             `bson:"name"`
@@ -66,20 +86,35 @@ func ListProjects(c *gin.Context) {
 			OwnerID     primitive.ObjectID `bson:"ownerId"`
 			CreatedAt   *time.Time         `bson:"createdAt,omitempty"`
 			UpdatedAt   *time.Time         `bson:"updatedAt,omitempty"`
+			Version     int64              `bson:"version"`
 		}
 
 		if err := cur.Decode(&p); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "decode error"})
+			apierror.Abort(c, apierror.Internal("decode error", err))
+			return
+		}
+
+		// นับจำนวน task ในโปรเจกต์นี้ (task ผูกกับ board ของโปรเจกต์)
+		boardIDs, err := boardIDsForProject(ctx, p.ID)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("db error", err))
+			return
+		}
+		taskCount, err := taskColl.CountDocuments(ctx, bson.M{
+			"boardId": bson.M{"$in": boardIDs},
+		})
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("count error", err))
 			return
 		}
 
-		// นับจำนวน task ในโปรเจกต์นี้
-		// สมมติว่าใน collection tasks เก็บ projectId แบบ ObjectID
-		taskCount, err := taskColl.CountDocuments(context.Background(), bson.M{
-			"projectId": p.ID,
+		// task ที่ยังเปิดอยู่ = ยังไม่มี completedAt
+		openCount, err := taskColl.CountDocuments(ctx, bson.M{
+			"boardId":     bson.M{"$in": boardIDs},
+			"completedAt": bson.M{"$exists": false},
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "count error"})
+			apierror.Abort(c, apierror.Internal("count error", err))
 			return
 		}
 
@@ -89,25 +124,17 @@ func ListProjects(c *gin.Context) {
 			Description: p.Description,
 			Color:       p.Color,
 			TaskCount:   taskCount,
-			TasksCount:  taskCount,
-		}
-
-		// ใส่ createdAt (string) ถ้ามี
-		if p.CreatedAt != nil {
-			resp.CreatedAt = p.CreatedAt.Format("2006-01-02T15:04:05Z")
-		}
-
-		// ใส่ updatedAt (string) ถ้ามี
-		if p.UpdatedAt != nil {
-			// format เป็น readable text เช่น "2025-10-27 14:32"
-			resp.UpdatedAt = p.UpdatedAt.Format("2006-01-02 15:04")
+			OpenTasks:   openCount,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Version:     p.Version,
 		}
 
 		results = append(results, resp)
 	}
 
 	if err := cur.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cursor error"})
+		apierror.Abort(c, apierror.Internal("cursor error", err))
 		return
 	}
 
@@ -118,13 +145,9 @@ func CreateProject(c *gin.Context) {
 	userSub := c.MustGet("userSub").(string)
 	uid, _ := primitive.ObjectIDFromHex(userSub)
 
-	var input struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-		Color       string `json:"color"`
-	}
+	var input CreateProjectRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err))
 		return
 	}
 
@@ -143,28 +166,32 @@ func CreateProject(c *gin.Context) {
 		Members:     []models.ProjectMember{{UserID: uid, Role: models.RoleAdmin}},
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     1,
 	}
 
 	col := db.Database.Collection("projects")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := requestContext(c, 5*time.Second)
 	defer cancel()
 
 	res, err := col.InsertOne(ctx, p)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "create failed"})
+		apierror.Abort(c, apierror.Internal("create failed", err))
 		return
 	}
 	oid := res.InsertedID.(primitive.ObjectID)
-	c.JSON(http.StatusCreated, gin.H{
-		"id":          oid.Hex(),
-		"name":        p.Name,
-		"description": p.Description,
-		"color":       p.Color,
-		"createdAt":   now.Format(time.RFC3339),
+	setETag(c, p.Version)
+	c.JSON(http.StatusCreated, ProjectResponse{
+		ID:          oid.Hex(),
+		Name:        p.Name,
+		Description: p.Description,
+		Color:       p.Color,
+		CreatedAt:   &now,
+		UpdatedAt:   &now,
+		Version:     p.Version,
 	})
 }
 
-// UpdateProject อนุญาตให้แก้ไขชื่อ / description
+// UpdateProject อนุญาตให้แก้ไขชื่อ / description (ส่ง If-Match เพื่อกันเขียนทับของคนอื่น)
 func UpdateProject(c *gin.Context) {
 	userSub := c.MustGet("userSub").(string)
 	userID, _ := primitive.ObjectIDFromHex(userSub)
@@ -173,36 +200,40 @@ func UpdateProject(c *gin.Context) {
 
 	oid, err := primitive.ObjectIDFromHex(projID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		apierror.Abort(c, apierror.InvalidID("projectId"))
 		return
 	}
 
 	// ดึงค่าใน body
-	var body struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	}
+	var body UpdateProjectRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		apierror.Abort(c, apierror.ErrMalformedBody)
 		return
 	}
 	if body.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		apierror.Abort(c, apierror.Invalid("name", "required", "name is required"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
 	coll := db.Database.Collection("projects")
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
 
 	// เช็ค permission: ต้องเป็นเจ้าของโปรเจกต์
 	var proj struct {
 		OwnerID primitive.ObjectID `bson:"ownerId"`
 	}
-	if err := coll.FindOne(context.Background(), bson.M{"_id": oid}).Decode(&proj); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	if err := coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to update this project"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to update this project"))
 		return
 	}
 
@@ -214,17 +245,14 @@ func UpdateProject(c *gin.Context) {
 		},
 	}
 
-	_, err = coll.UpdateOne(
-		context.Background(),
-		bson.M{"_id": oid},
-		update,
-	)
+	version, err := updateVersioned(ctx, coll, oid, want, update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		apierror.Abort(c, versionError(ctx, coll, oid, err, apierror.ErrProjectNotFound))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	setETag(c, version)
+	c.JSON(http.StatusOK, VersionResponse{Message: "updated", Version: version})
 }
 
 func DeleteProject(c *gin.Context) {
@@ -235,37 +263,42 @@ func DeleteProject(c *gin.Context) {
 
 	oid, err := primitive.ObjectIDFromHex(projID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return
+	}
+	want, verr := ifMatch(c)
+	if verr != nil {
+		apierror.Abort(c, verr)
 		return
 	}
 
 	coll := db.Database.Collection("projects")
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
 
 	// เช็ค permission: ต้องเป็นเจ้าของโปรเจกต์
 	var proj struct {
 		OwnerID primitive.ObjectID `bson:"ownerId"`
 	}
-	if err := coll.FindOne(context.Background(), bson.M{"_id": oid}).Decode(&proj); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	if err := coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
 		return
 	}
 	if proj.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "you don't have permission to delete this project"})
+		apierror.Abort(c, apierror.ErrForbidden.WithDetail("you don't have permission to delete this project"))
 		return
 	}
 
-	res, err := coll.DeleteOne(
-		context.Background(),
-		bson.M{"_id": oid},
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+	if err := deleteVersioned(ctx, coll, oid, want); err != nil {
+		apierror.Abort(c, versionError(ctx, coll, oid, err, apierror.ErrProjectNotFound))
 		return
 	}
-	if res.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
-		return
+	// ไฟล์แนบของทั้งโปรเจกต์ (record และไฟล์ใน storage) อาจมีหลายไฟล์ ให้เวลาแยกเท่ากับ DeleteAttachment
+	attCtx, attCancel := requestContext(c, 30*time.Second)
+	defer attCancel()
+	if err := deleteAttachments(attCtx, bson.M{"projectId": oid}); err != nil {
+		slog.ErrorContext(attCtx, "DELETE_PROJECT: delete attachments error", "err", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	c.JSON(http.StatusOK, OKResponse{OK: true})
 }
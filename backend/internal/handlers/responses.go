@@ -0,0 +1,17 @@
+package handlers
+
+// MessageResponse ผลของคำสั่งที่ตอบกลับแค่ข้อความ
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// OKResponse ผลของคำสั่งที่ตอบกลับแค่ว่าสำเร็จ
+type OKResponse struct {
+	OK bool `json:"ok"`
+}
+
+// VersionResponse ผลของ PATCH project / column / task (version ใหม่ ตรงกับ header ETag)
+type VersionResponse struct {
+	Message string `json:"message"`
+	Version int64  `json:"version"`
+}
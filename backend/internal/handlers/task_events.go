@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// recordTaskEvent บันทึกประวัติของ task ลง taskEvents
+// ถ้าบันทึกไม่ได้จะแค่ log ไว้ ไม่ทำให้ request หลักล้ม
+func recordTaskEvent(ctx context.Context, ev models.TaskEvent) {
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	if _, err := db.Database.Collection("taskEvents").InsertOne(ctx, ev); err != nil {
+		slog.ErrorContext(ctx, "TASK_EVENT: insert error", "err", err)
+	}
+}
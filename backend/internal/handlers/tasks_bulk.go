@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/webhooks"
+)
+
+// BulkTaskRequest body ของ POST /tasks/bulk
+// atomic = true ทำทุก operation ใน transaction เดียว (ล้มตัวเดียว = ไม่มีอะไรเปลี่ยน ต้องใช้ MongoDB แบบ replica set)
+// ไม่งั้นทำทีละตัวตามลำดับ ตัวที่ล้มไม่กระทบตัวอื่น
+type BulkTaskRequest struct {
+	Atomic     bool                `json:"atomic"`
+	Operations []BulkTaskOperation `json:"operations" binding:"required,min=1,max=200,dive"`
+}
+
+// BulkTaskOperation operation หนึ่งตัว field ที่ใช้ขึ้นกับ op:
+// move ใช้ toColumnId / enforceDependencies, update ใช้ title / description / priority / dueDate (ว่าง = ไม่เปลี่ยน),
+// assign ใช้ add / remove เป็น user id, label ใช้ add / remove เป็น label id, delete ไม่ใช้ field อื่น
+type BulkTaskOperation struct {
+	Op     string `json:"op" binding:"required,oneof=move update assign label delete"`
+	TaskID string `json:"taskId" binding:"required"`
+	// Version ทำเฉพาะเมื่อ task ยังเป็น version นี้ (เหมือน If-Match ของ endpoint เดี่ยว)
+	Version    *int64 `json:"version"`
+	ToColumnID string `json:"toColumnId"`
+	// EnforceDependencies ย้ายเข้า column done ไม่ได้ถ้ายังมี blocker ค้าง (เหมือน MoveTask)
+	EnforceDependencies bool     `json:"enforceDependencies"`
+	Title               string   `json:"title"`
+	Description         string   `json:"description"`
+	Priority            string   `json:"priority"`
+	DueDate             string   `json:"dueDate"`
+	Add                 []string `json:"add"`
+	Remove              []string `json:"remove"`
+}
+
+// BulkTaskResponse ผลของ POST /tasks/bulk (ตอบ 200 เสมอ ดูผลรายตัวใน results)
+type BulkTaskResponse struct {
+	Atomic  bool             `json:"atomic"`
+	Applied int              `json:"applied"`
+	Failed  int              `json:"failed"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// BulkTaskResult ผลของ operation ตามลำดับที่ส่งมา status / code เหมือนที่ endpoint เดี่ยวจะตอบ
+// (BULK_ABORTED = ไม่ได้ทำเพราะ operation อื่นใน atomic batch ล้ม) version คือ version ใหม่ของ task (ไม่มีเมื่อลบ)
+// blockedBy มีเมื่อ code = TASK_BLOCKED
+type BulkTaskResult struct {
+	Index     int                  `json:"index"`
+	Op        string               `json:"op"`
+	TaskID    string               `json:"taskId"`
+	OK        bool                 `json:"ok"`
+	Status    int                  `json:"status"`
+	Code      string               `json:"code,omitempty"`
+	Detail    string               `json:"detail,omitempty"`
+	Version   int64                `json:"version,omitempty"`
+	BlockedBy []primitive.ObjectID `json:"blockedBy,omitempty"`
+}
+
+// BulkTasks ทำ move / update / assign / label / delete กับหลาย task ใน request เดียว
+// สิทธิ์เช็ครายตัว: move ต้องเป็นสมาชิกโปรเจกต์ของ task นั้น อย่างอื่นต้องเป็นเจ้าของโปรเจกต์ (เหมือน UpdateTask / DeleteTask)
+// event / webhook / แจ้งเตือน ส่งหลังเขียนสำเร็จเท่านั้น (atomic = หลัง commit)
+func BulkTasks(c *gin.Context) {
+	userID, _ := primitive.ObjectIDFromHex(c.GetString("userSub"))
+
+	var input BulkTaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 30*time.Second)
+	defer cancel()
+
+	b := &bulkRun{userID: userID, now: time.Now(), projects: map[primitive.ObjectID]models.Project{}}
+	out := BulkTaskResponse{Atomic: input.Atomic, Results: make([]BulkTaskResult, len(input.Operations))}
+
+	if input.Atomic {
+		if err := b.atomic(ctx, input.Operations, out.Results); err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+	} else {
+		for i, op := range input.Operations {
+			version, err := b.run(ctx, op)
+			out.Results[i] = bulkResult(i, op, version, err)
+		}
+	}
+
+	for _, fn := range b.effects {
+		fn(ctx)
+	}
+	for _, r := range out.Results {
+		if r.OK {
+			out.Applied++
+		} else {
+			out.Failed++
+		}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// bulkRun สถานะของ bulk request หนึ่งตัว
+type bulkRun struct {
+	userID   primitive.ObjectID
+	now      time.Time
+	projects map[primitive.ObjectID]models.Project // board id -> project
+	effects  []func(context.Context)
+}
+
+// atomic ทำทุก operation ใน transaction เดียว หยุดที่ตัวแรกที่ล้ม
+// คืน error เฉพาะกรณีที่ทั้ง request ล้ม (เช่น MongoDB ไม่รองรับ transaction) ผลรายตัวเขียนลง results
+func (b *bulkRun) atomic(ctx context.Context, ops []BulkTaskOperation, results []BulkTaskResult) *apierror.Error {
+	sess, err := db.Client.StartSession()
+	if err != nil {
+		return apierror.Internal("could not start session", err)
+	}
+	defer sess.EndSession(ctx)
+
+	versions := make([]int64, len(ops))
+	failedAt := -1
+	var failure *apierror.Error
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// driver เรียกซ้ำได้เมื่อ transaction ชนกัน: เริ่มนับใหม่ทุกรอบ
+		b.effects = nil
+		b.projects = map[primitive.ObjectID]models.Project{}
+		failedAt, failure = -1, nil
+		for i, op := range ops {
+			version, err := b.run(sc, op)
+			if err != nil {
+				failedAt, failure = i, err
+				return nil, err
+			}
+			versions[i] = version
+		}
+		return nil, nil
+	})
+	if transactionsUnsupported(err) {
+		return apierror.ErrAtomicUnsupported
+	}
+	if err != nil && failure == nil {
+		return apierror.Internal("transaction failed", err)
+	}
+
+	for i, op := range ops {
+		switch {
+		case failure == nil:
+			results[i] = bulkResult(i, op, versions[i], nil)
+		case i == failedAt:
+			results[i] = bulkResult(i, op, 0, failure)
+		default:
+			results[i] = bulkResult(i, op, 0, apierror.ErrBulkAborted)
+		}
+	}
+	if failure != nil {
+		b.effects = nil
+	}
+	return nil
+}
+
+// transactionsUnsupported MongoDB แบบ standalone ไม่มี transaction (IllegalOperation)
+func transactionsUnsupported(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCode(20)
+}
+
+func bulkResult(i int, op BulkTaskOperation, version int64, err *apierror.Error) BulkTaskResult {
+	r := BulkTaskResult{Index: i, Op: op.Op, TaskID: op.TaskID}
+	if err != nil {
+		r.Status, r.Code, r.Detail = err.Status, err.Code, err.Detail
+		r.BlockedBy, _ = err.Extensions["blockedBy"].([]primitive.ObjectID)
+		return r
+	}
+	r.OK, r.Status, r.Version = true, http.StatusOK, version
+	return r
+}
+
+// project โหลด project ของ board (จำไว้ เพราะ task ใน batch เดียวกันมักอยู่ board เดียวกัน)
+func (b *bulkRun) project(ctx context.Context, boardID primitive.ObjectID) (models.Project, error) {
+	if p, ok := b.projects[boardID]; ok {
+		return p, nil
+	}
+	p, err := projectForBoard(ctx, boardID)
+	if err != nil {
+		return models.Project{}, err
+	}
+	b.projects[boardID] = p
+	return p, nil
+}
+
+// run ตรวจสิทธิ์แล้วเขียน operation หนึ่งตัว คืน version ใหม่ของ task
+func (b *bulkRun) run(ctx context.Context, op BulkTaskOperation) (int64, *apierror.Error) {
+	taskOID, err := primitive.ObjectIDFromHex(op.TaskID)
+	if err != nil {
+		return 0, apierror.InvalidID("taskId")
+	}
+	taskCol := db.Database.Collection("tasks")
+	var task models.Task
+	if err := taskCol.FindOne(ctx, bson.M{"_id": taskOID}).Decode(&task); err != nil {
+		return 0, apierror.Lookup(err, apierror.ErrTaskNotFound)
+	}
+	proj, err := b.project(ctx, task.BoardID)
+	if err != nil {
+		return 0, apierror.Lookup(err, apierror.ErrProjectNotFound)
+	}
+	if op.Op == "move" {
+		if !isProjectMember(proj, b.userID) {
+			return 0, apierror.ErrForbidden.WithDetail("you are not a member of this task's project")
+		}
+	} else if proj.OwnerID != b.userID {
+		return 0, apierror.ErrForbidden.WithDetail("you don't have permission to " + op.Op + " this task")
+	}
+
+	var want []int64
+	if op.Version != nil {
+		want = []int64{*op.Version}
+	}
+
+	var update bson.M
+	var after func(context.Context)
+	switch op.Op {
+	case "move":
+		colOID, err := primitive.ObjectIDFromHex(op.ToColumnID)
+		if err != nil {
+			return 0, apierror.InvalidID("toColumnId")
+		}
+		var column models.Column
+		if err := db.Database.Collection("columns").FindOne(ctx, bson.M{"_id": colOID}).Decode(&column); err != nil {
+			return 0, apierror.Lookup(err, apierror.ErrColumnNotFound)
+		}
+		if column.BoardID != task.BoardID {
+			return 0, apierror.Invalid("toColumnId", "board", "column is on another board")
+		}
+		if op.EnforceDependencies && column.Category == models.ColumnDone {
+			pending, err := unfinishedBlockers(ctx, task.ID)
+			if err != nil {
+				return 0, apierror.Internal("could not check dependencies", err)
+			}
+			if len(pending) > 0 {
+				return 0, apierror.ErrTaskBlocked.With("blockedBy", pending)
+			}
+		}
+		update = moveTaskUpdate(task, column, b.now)
+		after = func(ctx context.Context) { afterTaskMoved(ctx, task, colOID, b.userID, b.now) }
+
+	case "update":
+		set := bson.M{}
+		if op.Title != "" {
+			set["title"] = op.Title
+		}
+		if op.Description != "" {
+			set["description"] = op.Description
+		}
+		if op.Priority != "" {
+			if !models.ValidTaskPriority(op.Priority) {
+				return 0, errInvalidPriority
+			}
+			set["priority"] = op.Priority
+		}
+		dueDate, err := parseDueDate(op.DueDate)
+		if err != nil {
+			return 0, apierror.Invalid("dueDate", "datetime", "invalid dueDate")
+		}
+		if dueDate != nil {
+			set["dueDate"] = *dueDate
+		}
+		if len(set) == 0 {
+			return 0, apierror.ErrNothingToDo
+		}
+		update = setTaskUpdate(set)
+		after = func(ctx context.Context) { publishTaskUpdated(ctx, proj.ID, task.ID, set) }
+
+	case "assign":
+		add, remove, aerr := bulkIDs(op)
+		if aerr != nil {
+			return 0, aerr
+		}
+		for _, a := range add {
+			if !isProjectMember(proj, a) {
+				return 0, apierror.ErrNotProjectMember.WithDetail("assignee is not a project member")
+			}
+		}
+		assignees := applyIDChanges(task.Assignees, add, remove)
+		var added []primitive.ObjectID
+		for _, a := range assignees {
+			if !containsID(task.Assignees, a) && a != b.userID {
+				added = append(added, a)
+			}
+		}
+		set := bson.M{"assignees": assignees}
+		update = bson.M{"$set": set}
+		after = func(ctx context.Context) {
+			publishTaskUpdated(ctx, proj.ID, task.ID, set)
+			notifyAssigned(ctx, added, task.Title, proj.ID, task.ID)
+		}
+
+	case "label":
+		add, remove, aerr := bulkIDs(op)
+		if aerr != nil {
+			return 0, aerr
+		}
+		set := bson.M{"labels": applyIDChanges(task.Labels, add, remove)}
+		update = bson.M{"$set": set}
+		after = func(ctx context.Context) { publishTaskUpdated(ctx, proj.ID, task.ID, set) }
+
+	case "delete":
+		if err := deleteVersioned(ctx, taskCol, task.ID, want); err != nil {
+			return 0, versionError(ctx, taskCol, task.ID, err, apierror.ErrTaskNotFound)
+		}
+		b.effects = append(b.effects, func(ctx context.Context) {
+			afterTaskDeleted(ctx, task.ID, task.BoardID, task.ColumnID, proj.ID, b.userID)
+		})
+		return 0, nil
+	}
+
+	version, err := updateVersioned(ctx, taskCol, task.ID, want, update)
+	if err != nil {
+		return 0, versionError(ctx, taskCol, task.ID, err, apierror.ErrTaskNotFound)
+	}
+	b.effects = append(b.effects, after)
+	return version, nil
+}
+
+// bulkIDs แปลง add / remove ของ assign / label เป็น ObjectID (ต้องมีอย่างน้อยหนึ่งตัว)
+func bulkIDs(op BulkTaskOperation) (add, remove []primitive.ObjectID, err *apierror.Error) {
+	if len(op.Add) == 0 && len(op.Remove) == 0 {
+		return nil, nil, apierror.ErrNothingToDo.WithDetail("add or remove is required")
+	}
+	parse := func(field string, hexes []string) ([]primitive.ObjectID, *apierror.Error) {
+		ids := make([]primitive.ObjectID, 0, len(hexes))
+		for _, h := range hexes {
+			id, err := primitive.ObjectIDFromHex(h)
+			if err != nil {
+				return nil, apierror.InvalidID(field)
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+	if add, err = parse("add", op.Add); err != nil {
+		return nil, nil, err
+	}
+	if remove, err = parse("remove", op.Remove); err != nil {
+		return nil, nil, err
+	}
+	return add, remove, nil
+}
+
+// applyIDChanges เพิ่ม add (ที่ยังไม่มี) แล้วเอา remove ออก โดยคงลำดับเดิม
+func applyIDChanges(current, add, remove []primitive.ObjectID) []primitive.ObjectID {
+	out := []primitive.ObjectID{}
+	for _, id := range append(append([]primitive.ObjectID{}, current...), add...) {
+		if !containsID(out, id) && !containsID(remove, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// publishTaskUpdated ส่ง webhook task.updated ในรูปแบบเดียวกับ UpdateTask
+func publishTaskUpdated(ctx context.Context, projectID, taskID primitive.ObjectID, changes bson.M) {
+	if err := webhooks.Publish(ctx, projectID, webhooks.EventTaskUpdated, gin.H{
+		"id":      taskID.Hex(),
+		"changes": changes,
+	}); err != nil {
+		slog.ErrorContext(ctx, "BULK_TASKS: webhook error", "err", err)
+	}
+}
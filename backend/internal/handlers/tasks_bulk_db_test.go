@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/models"
+)
+
+// seedBulkBoard โปรเจกต์ที่ owner เป็นเจ้าของ มี column todo / done และ task ที่ blocker ยังไม่เสร็จ
+func seedBulkBoard(t *testing.T) (ctx context.Context, b *bulkRun, blocked, blocker, free primitive.ObjectID) {
+	ctx = useTestDatabase(t)
+	owner := primitive.NewObjectID()
+	project := primitive.NewObjectID()
+	blocked, blocker, free = primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	seed(t, ctx, "projects", models.Project{ID: project, Name: "p", OwnerID: owner})
+	seed(t, ctx, "boards", models.Board{ID: boardMain, Name: "b", ProjectID: project})
+	seed(t, ctx, "columns",
+		models.Column{ID: colTodo, Name: "todo", BoardID: boardMain, Category: models.ColumnBacklog},
+		models.Column{ID: colDone, Name: "done", Position: 1, BoardID: boardMain, Category: models.ColumnDone},
+	)
+	task := func(id primitive.ObjectID) interface{} {
+		return models.Task{ID: id, Title: "t", ColumnID: colTodo, BoardID: boardMain, Version: 1}
+	}
+	seed(t, ctx, "tasks", task(blocked), task(blocker), task(free))
+	seed(t, ctx, "taskDependencies", models.TaskDependency{
+		ID: primitive.NewObjectID(), ProjectID: project, BlockerID: blocker, BlockedID: blocked, CreatedByID: owner,
+	})
+	return ctx, &bulkRun{userID: owner, now: time.Now(), projects: map[primitive.ObjectID]models.Project{}}, blocked, blocker, free
+}
+
+func TestBulkMoveEnforcesDependencies(t *testing.T) {
+	ctx, b, blocked, blocker, free := seedBulkBoard(t)
+
+	ops := []BulkTaskOperation{
+		{Op: "move", TaskID: blocked.Hex(), ToColumnID: colDone.Hex(), EnforceDependencies: true},
+		{Op: "move", TaskID: free.Hex(), ToColumnID: colDone.Hex(), EnforceDependencies: true},
+		// ไม่ขอให้บังคับ ย้ายได้เหมือน MoveTask
+		{Op: "move", TaskID: blocked.Hex(), ToColumnID: colDone.Hex()},
+	}
+	var results []BulkTaskResult
+	for i, op := range ops {
+		version, err := b.run(ctx, op)
+		results = append(results, bulkResult(i, op, version, err))
+	}
+
+	if r := results[0]; r.OK || r.Status != http.StatusConflict || r.Code != "TASK_BLOCKED" {
+		t.Fatalf("blocked move = %+v, want 409 TASK_BLOCKED", r)
+	}
+	if got := results[0].BlockedBy; !reflect.DeepEqual(got, []primitive.ObjectID{blocker}) {
+		t.Errorf("blockedBy = %v, want [%v]", got, blocker)
+	}
+	if r := results[1]; !r.OK || r.Version != 2 {
+		t.Errorf("unblocked move = %+v", r)
+	}
+	if r := results[2]; !r.OK {
+		t.Errorf("move without enforceDependencies = %+v", r)
+	}
+}
+
+func TestBulkUpdateValidatesPriority(t *testing.T) {
+	ctx, b, _, _, free := seedBulkBoard(t)
+
+	op := BulkTaskOperation{Op: "update", TaskID: free.Hex(), Priority: "URGENT"}
+	_, err := b.run(ctx, op)
+	if r := bulkResult(0, op, 0, err); r.OK || r.Status != http.StatusBadRequest {
+		t.Fatalf("invalid priority = %+v, want 400", r)
+	}
+
+	op.Priority = models.TaskPriorityHigh
+	if _, err := b.run(ctx, op); err != nil {
+		t.Fatalf("valid priority: %v", err)
+	}
+}
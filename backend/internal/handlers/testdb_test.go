@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/db"
+)
+
+// useTestDatabase ต่อ MongoDB จาก MONGO_TEST_URI แล้วใช้ database ชั่วคราวแทน db.Database ระหว่าง test
+// (ไม่ได้ตั้ง MONGO_TEST_URI จะข้าม test ที่ต้องใช้ database)
+func useTestDatabase(t *testing.T) context.Context {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database("taskmgr_test_" + primitive.NewObjectID().Hex())
+
+	prev := db.Database
+	db.Database = database
+	t.Cleanup(func() {
+		db.Database = prev
+		database.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	return ctx
+}
+
+// seed ใส่เอกสารลง collection
+func seed(t *testing.T, ctx context.Context, coll string, docs ...interface{}) {
+	t.Helper()
+	if _, err := db.Database.Collection(coll).InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+}
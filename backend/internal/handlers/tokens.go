@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/middleware"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/utils"
+)
+
+const (
+	defaultTokenDays = 30
+	maxTokenDays     = 365
+)
+
+// CreateTokenRequest body ของ POST /me/tokens (expiresInDays 1-365, 0 = 30 วัน ทุก token มีวันหมดอายุ)
+type CreateTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expiresInDays" doc:"1-365 (default 30 when omitted or 0); tokens always expire"`
+}
+
+// CreateTokenResponse token จริง (แสดงครั้งเดียว) กับข้อมูลของ token
+type CreateTokenResponse struct {
+	Token               string                     `json:"token"`
+	PersonalAccessToken models.PersonalAccessToken `json:"personalAccessToken"`
+}
+
+// CreatePersonalAccessToken POST /me/tokens
+// token จริงจะถูกส่งกลับแค่ครั้งนี้ครั้งเดียว ใน DB เก็บแค่ hash
+func CreatePersonalAccessToken(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	var input CreateTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	if len(input.Scopes) == 0 {
+		apierror.Abort(c, apierror.Invalid("scopes", "required", "at least one scope is required").With("scopes", middleware.Scopes))
+		return
+	}
+	for _, s := range input.Scopes {
+		if !validScope(s) {
+			apierror.Abort(c, apierror.Invalid("scopes", "oneof", "unknown scope: "+s).With("scopes", middleware.Scopes))
+			return
+		}
+	}
+
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultTokenDays
+	}
+	if days < 1 || days > maxTokenDays {
+		apierror.Abort(c, apierror.Invalid("expiresInDays", "range", "expiresInDays must be between 1 and 365"))
+		return
+	}
+
+	token, hash, err := utils.NewPersonalAccessToken()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not generate token", err))
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, days)
+	pat := models.PersonalAccessToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      input.Name,
+		TokenHash: hash,
+		Prefix:    token[:len(utils.PATPrefix)+6],
+		Scopes:    input.Scopes,
+		ExpiresAt: &expiresAt,
+		CreatedAt: now,
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	if _, err := db.Database.Collection("personalAccessTokens").InsertOne(ctx, pat); err != nil {
+		apierror.Abort(c, apierror.Internal("create failed", err))
+		return
+	}
+	c.JSON(http.StatusCreated, CreateTokenResponse{Token: token, PersonalAccessToken: pat})
+}
+
+// ListPersonalAccessTokens GET /me/tokens
+func ListPersonalAccessTokens(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cur, err := db.Database.Collection("personalAccessTokens").Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	tokens := []models.PersonalAccessToken{}
+	if err := cur.All(ctx, &tokens); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokePersonalAccessToken DELETE /me/tokens/:id
+func RevokePersonalAccessToken(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("tokenId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	res, err := db.Database.Collection("personalAccessTokens").UpdateOne(ctx,
+		bson.M{"_id": oid, "userId": userID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now()}},
+	)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	if res.MatchedCount == 0 {
+		apierror.Abort(c, apierror.ErrTokenNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "revoked"})
+}
+
+func validScope(scope string) bool {
+	for _, s := range middleware.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/ratelimit"
+	"mini-taskmgr-backend/internal/utils"
+)
+
+const (
+	totpIssuer        = "Mini Task Manager"
+	recoveryCodeCount = 10
+)
+
+// EnrollTwoFactorResponse secret ที่ต้องเอาไปใส่ใน authenticator app
+type EnrollTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// ConfirmTwoFactorRequest body ของ POST /auth/2fa/confirm
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ConfirmTwoFactorResponse recovery code (แสดงครั้งเดียว)
+type ConfirmTwoFactorResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// DisableTwoFactorRequest body ของ POST /auth/2fa/disable (ส่ง code หรือ recoveryCode อย่างใดอย่างหนึ่ง)
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// LoginTwoFactorRequest body ของ POST /auth/login/2fa
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
+
+// EnrollTwoFactor POST /auth/2fa/enroll
+// สร้าง secret ใหม่ (ยังไม่เปิดใช้จนกว่าจะ confirm ด้วย code จาก app)
+func EnrollTwoFactor(c *gin.Context) {
+	sub := c.MustGet("userSub").(string)
+	oid, _ := primitive.ObjectIDFromHex(sub)
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	col := db.Database.Collection("users")
+	var u models.User
+	if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	if u.TOTPEnabled {
+		apierror.Abort(c, apierror.ErrTwoFactorEnabled)
+		return
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not generate secret", err))
+		return
+	}
+	if _, err := col.UpdateByID(ctx, oid, bson.M{"$set": bson.M{"totpPendingSecret": secret}}); err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, EnrollTwoFactorResponse{
+		Secret:     secret,
+		OtpauthURI: utils.TOTPURI(totpIssuer, u.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor POST /auth/2fa/confirm
+// ยืนยัน code จาก secret ที่รออยู่ แล้วเปิดใช้ 2FA พร้อมคืน recovery code (แสดงครั้งเดียว)
+func ConfirmTwoFactor(c *gin.Context) {
+	sub := c.MustGet("userSub").(string)
+	oid, _ := primitive.ObjectIDFromHex(sub)
+
+	var input ConfirmTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	col := db.Database.Collection("users")
+	var u models.User
+	if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	if u.TOTPEnabled {
+		apierror.Abort(c, apierror.ErrTwoFactorEnabled)
+		return
+	}
+	if u.TOTPPendingSecret == "" {
+		apierror.Abort(c, apierror.ErrTwoFactorNotEnrolled)
+		return
+	}
+
+	step, ok := utils.VerifyTOTP(u.TOTPPendingSecret, input.Code, time.Now())
+	if !ok {
+		apierror.Abort(c, apierror.ErrInvalidTwoFactorCode)
+		return
+	}
+
+	codes, err := utils.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not generate recovery codes", err))
+		return
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+
+	_, err = col.UpdateOne(ctx,
+		bson.M{"_id": oid, "totpPendingSecret": u.TOTPPendingSecret},
+		bson.M{
+			"$set": bson.M{
+				"totpEnabled":   true,
+				"totpSecret":    u.TOTPPendingSecret,
+				"totpLastStep":  step,
+				"recoveryCodes": hashes,
+			},
+			"$unset": bson.M{"totpPendingSecret": ""},
+		})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfirmTwoFactorResponse{
+		Message:       "two-factor authentication enabled",
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor POST /auth/2fa/disable
+// ต้องยืนยันรหัสผ่าน และ code จาก app หรือ recovery code อีกครั้ง
+func DisableTwoFactor(c *gin.Context) {
+	sub := c.MustGet("userSub").(string)
+	oid, _ := primitive.ObjectIDFromHex(sub)
+
+	var input DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	col := db.Database.Collection("users")
+	var u models.User
+	if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUserNotFound))
+		return
+	}
+	if !u.TOTPEnabled {
+		apierror.Abort(c, apierror.ErrTwoFactorNotEnabled)
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(input.Password)); err != nil {
+		apierror.Abort(c, apierror.ErrInvalidPassword)
+		return
+	}
+
+	ok, err := verifySecondFactor(ctx, u, input.Code, input.RecoveryCode)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	if !ok {
+		apierror.Abort(c, apierror.ErrInvalidTwoFactorCode)
+		return
+	}
+
+	_, err = col.UpdateByID(ctx, oid, bson.M{"$unset": bson.M{
+		"totpEnabled":       "",
+		"totpSecret":        "",
+		"totpPendingSecret": "",
+		"totpLastStep":      "",
+		"recoveryCodes":     "",
+	}})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("update failed", err))
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "two-factor authentication disabled"})
+}
+
+// LoginTwoFactor POST /auth/login/2fa
+// ขั้นที่ 2 ของ login: รับ challengeToken จาก /auth/login และ code หรือ recovery code
+func LoginTwoFactor(c *gin.Context) {
+	var input LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	claims, err := utils.VerifyChallengeToken(input.ChallengeToken)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidChallenge)
+		return
+	}
+	oid, err := primitive.ObjectIDFromHex(claims.Sub)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidChallenge)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var u models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.ErrInvalidChallenge)
+		return
+	}
+	if !u.TOTPEnabled {
+		apierror.Abort(c, apierror.ErrInvalidChallenge)
+		return
+	}
+	if u.Disabled {
+		apierror.Abort(c, apierror.ErrAccountDisabled)
+		return
+	}
+
+	// code ผิดนับรวมกับ login ผิด ใช้ lockout เดียวกัน
+	if wait, err := loginLockRemaining(ctx, u.Email); err != nil {
+		slog.ErrorContext(ctx, "LOGIN_2FA: check lock error", "err", err)
+	} else if wait > 0 {
+		ratelimit.TooManyRequests(c, wait, apierror.ErrAccountLocked)
+		return
+	}
+
+	ok, err := verifySecondFactor(ctx, u, input.Code, input.RecoveryCode)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	if !ok {
+		if err := registerLoginFailure(ctx, u.Email); err != nil {
+			slog.ErrorContext(ctx, "LOGIN_2FA: record failure error", "err", err)
+		}
+		apierror.Abort(c, apierror.ErrInvalidTwoFactorCode)
+		return
+	}
+
+	clearLoginFailures(ctx, u.Email)
+
+	payload, err := accessTokenPayload(u)
+	if err != nil {
+		slog.ErrorContext(ctx, "LOGIN_2FA: sign token error", "err", err)
+		apierror.Abort(c, apierror.Internal("could not sign token", err))
+		return
+	}
+	c.JSON(http.StatusOK, payload)
+}
+
+// verifySecondFactor ตรวจ TOTP code หรือ recovery code (ต้องส่งมาอย่างใดอย่างหนึ่ง)
+// code ที่ผ่านแล้วจะใช้ซ้ำไม่ได้: TOTP ต้องมี step ใหม่กว่าครั้งล่าสุด, recovery code ถูกลบออก
+func verifySecondFactor(ctx context.Context, u models.User, code, recoveryCode string) (bool, error) {
+	col := db.Database.Collection("users")
+
+	switch {
+	case code != "" && recoveryCode != "":
+		return false, apierror.Invalid("recoveryCode", "excluded_with", "send either code or recoveryCode, not both")
+
+	case code != "":
+		step, ok := utils.VerifyTOTP(u.TOTPSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		res, err := col.UpdateOne(ctx, bson.M{
+			"_id": u.ID,
+			"$or": bson.A{
+				bson.M{"totpLastStep": bson.M{"$exists": false}},
+				bson.M{"totpLastStep": bson.M{"$lt": step}},
+			},
+		}, bson.M{"$set": bson.M{"totpLastStep": step}})
+		if err != nil {
+			slog.ErrorContext(ctx, "2FA: update last step error", "err", err)
+			return false, nil
+		}
+		return res.ModifiedCount == 1, nil
+
+	case recoveryCode != "":
+		hash := utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode))
+		res, err := col.UpdateOne(ctx,
+			bson.M{"_id": u.ID, "recoveryCodes": hash},
+			bson.M{"$pull": bson.M{"recoveryCodes": hash}},
+		)
+		if err != nil {
+			slog.ErrorContext(ctx, "2FA: consume recovery code error", "err", err)
+			return false, nil
+		}
+		return res.ModifiedCount == 1, nil
+	}
+
+	return false, apierror.Invalid("code", "required", "code or recoveryCode is required")
+}
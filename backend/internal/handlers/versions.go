@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+// optimistic concurrency ของ project / column / task:
+// ทุกครั้งที่แก้เอกสารจะ $inc version ใน update เดียวกัน และตอบ version ใหม่เป็น ETag ("3")
+// client ส่ง If-Match กลับมากับ PATCH / DELETE ถ้ามีคนแก้ไปก่อน filter จะไม่ match แล้วได้ 412
+// ไม่ส่ง If-Match = เขียนทับได้เลยเหมือนเดิม
+
+// setETag ใส่ header ETag ของ version
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatch อ่าน header If-Match เป็น list ของ version ที่ยอมรับ
+// ไม่ได้ส่งมาหรือเป็น * คืน nil (ไม่ต้องเช็ค) ส่วน weak ETag (W/"3") ไม่นับว่าตรงเพราะ If-Match ใช้ strong comparison
+func ifMatch(c *gin.Context) ([]int64, *apierror.Error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	want := []int64{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, apierror.ErrInvalidIfMatch
+		}
+		v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			return nil, apierror.ErrInvalidIfMatch
+		}
+		if !weak {
+			want = append(want, v)
+		}
+	}
+	return want, nil
+}
+
+// matchVersion เพิ่มเงื่อนไข version ลงใน filter (want เป็น nil = ไม่เช็ค)
+// เอกสารเก่าที่ยังไม่มี field version ถือเป็น version 0
+func matchVersion(filter bson.M, want []int64) bson.M {
+	if want == nil {
+		return filter
+	}
+	in := bson.A{}
+	for _, v := range want {
+		in = append(in, v)
+		if v == 0 {
+			in = append(in, nil)
+		}
+	}
+	filter["version"] = bson.M{"$in": in}
+	return filter
+}
+
+// bumpVersion เพิ่ม $inc version ลงใน update
+func bumpVersion(update bson.M) bson.M {
+	inc, _ := update["$inc"].(bson.M)
+	if inc == nil {
+		inc = bson.M{}
+		update["$inc"] = inc
+	}
+	inc["version"] = 1
+	return update
+}
+
+// updateVersioned แก้เอกสารตาม id ถ้า version ยังตรงกับ want แล้วคืน version ใหม่
+// ไม่มีเอกสารที่ตรงเงื่อนไขคืน mongo.ErrNoDocuments (ใช้ versionError แปลงต่อ)
+func updateVersioned(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, want []int64, update bson.M) (int64, error) {
+	var doc struct {
+		Version int64 `bson:"version"`
+	}
+	err := coll.FindOneAndUpdate(ctx,
+		matchVersion(bson.M{"_id": id}, want),
+		bumpVersion(update),
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"version": 1}),
+	).Decode(&doc)
+	return doc.Version, err
+}
+
+// deleteVersioned ลบเอกสารตาม id ถ้า version ยังตรงกับ want
+func deleteVersioned(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, want []int64) error {
+	res, err := coll.DeleteOne(ctx, matchVersion(bson.M{"_id": id}, want))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// versionError แปลง error จาก updateVersioned / deleteVersioned เป็น error ของ API:
+// เอกสารหายไปแล้ว = notFound, ยังอยู่แต่ version ไม่ตรง = 412 พร้อม currentVersion
+func versionError(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, err error, notFound *apierror.Error) *apierror.Error {
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return apierror.Internal("db error", err)
+	}
+	var doc struct {
+		Version int64 `bson:"version"`
+	}
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return apierror.Lookup(err, notFound)
+	}
+	return apierror.ErrVersionConflict.With("currentVersion", doc.Version)
+}
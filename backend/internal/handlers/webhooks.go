@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/webhooks"
+)
+
+// loadAdminProject โหลดโปรเจกต์จาก :id แล้วเช็คว่า user เป็น admin
+// ถ้าไม่ผ่านจะเขียน response ให้แล้ว และคืน ok = false
+func loadAdminProject(ctx context.Context, c *gin.Context) (models.Project, bool) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	pid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("projectId"))
+		return models.Project{}, false
+	}
+	var proj models.Project
+	if err := db.Database.Collection("projects").FindOne(ctx, bson.M{"_id": pid}).Decode(&proj); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrProjectNotFound))
+		return models.Project{}, false
+	}
+	if !isProjectAdmin(proj, userID) {
+		apierror.Abort(c, apierror.ErrProjectAdminRequired.WithDetail("only project admins can manage webhooks"))
+		return models.Project{}, false
+	}
+	return proj, true
+}
+
+// validWebhookURL URL ต้องเป็น http(s) ที่ host resolve ได้เป็น address สาธารณะเท่านั้น (กัน SSRF)
+func validWebhookURL(ctx context.Context, raw string) *apierror.Error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := webhooks.CheckURL(ctx, raw); err != nil {
+		return apierror.Invalid("url", "url", err.Error())
+	}
+	return nil
+}
+
+func validWebhookEvents(events []string) bool {
+	if len(events) == 0 {
+		return false
+	}
+	for _, e := range events {
+		if !webhooks.ValidEvent(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListWebhooks GET /projects/:id/webhooks
+func ListWebhooks(c *gin.Context) {
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+
+	cur, err := db.Database.Collection("webhooks").Find(ctx, bson.M{"projectId": proj.ID})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	hooks := []models.Webhook{}
+	if err := cur.All(ctx, &hooks); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// CreateWebhookRequest body ของ POST /projects/:id/webhooks
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"required"`
+}
+
+// CreateWebhookResponse webhook ที่สร้าง กับ secret (แสดงครั้งเดียว)
+type CreateWebhookResponse struct {
+	Webhook models.Webhook `json:"webhook"`
+	Secret  string         `json:"secret"`
+}
+
+// UpdateWebhookRequest body ของ PATCH /projects/:id/webhooks/:hookId (field ว่าง = ไม่เปลี่ยน)
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// CreateWebhook POST /projects/:id/webhooks
+// ถ้าไม่ส่ง secret มาจะสุ่มให้ และส่ง secret กลับไปแค่ครั้งนี้ครั้งเดียว
+func CreateWebhook(c *gin.Context) {
+	userSub := c.MustGet("userSub").(string)
+	userID, _ := primitive.ObjectIDFromHex(userSub)
+
+	var input CreateWebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+	if !validWebhookEvents(input.Events) {
+		apierror.Abort(c, apierror.Invalid("events", "oneof", "events must be a non-empty list of known events").With("events", webhooks.Events))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+	// resolve DNS หลังเช็คสิทธิ์แล้วเท่านั้น ไม่ให้คนนอกใช้เป็นเครื่องมือสำรวจ address ภายใน
+	if err := validWebhookURL(ctx, input.URL); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			apierror.Abort(c, apierror.Internal("could not generate secret", err))
+			return
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	hook := models.Webhook{
+		ID:          primitive.NewObjectID(),
+		ProjectID:   proj.ID,
+		URL:         input.URL,
+		Secret:      secret,
+		Events:      input.Events,
+		Active:      true,
+		CreatedByID: userID,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := db.Database.Collection("webhooks").InsertOne(ctx, hook); err != nil {
+		apierror.Abort(c, apierror.Internal("create failed", err))
+		return
+	}
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// UpdateWebhook PATCH /projects/:id/webhooks/:hookId
+func UpdateWebhook(c *gin.Context) {
+	hookID, err := primitive.ObjectIDFromHex(c.Param("hookId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("webhookId"))
+		return
+	}
+
+	var input UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.Abort(c, apierror.Validation(err))
+		return
+	}
+
+	updateDoc := bson.M{}
+	if input.URL != "" {
+		updateDoc["url"] = input.URL
+	}
+	if input.Secret != "" {
+		updateDoc["secret"] = input.Secret
+	}
+	if input.Events != nil {
+		if !validWebhookEvents(input.Events) {
+			apierror.Abort(c, apierror.Invalid("events", "oneof", "events must be a non-empty list of known events").With("events", webhooks.Events))
+			return
+		}
+		updateDoc["events"] = input.Events
+	}
+	if input.Active != nil {
+		updateDoc["active"] = *input.Active
+	}
+	if len(updateDoc) == 0 {
+		apierror.Abort(c, apierror.ErrNothingToDo)
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+	if input.URL != "" {
+		if err := validWebhookURL(ctx, input.URL); err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+	}
+
+	var hook models.Webhook
+	err = db.Database.Collection("webhooks").FindOneAndUpdate(ctx,
+		bson.M{"_id": hookID, "projectId": proj.ID},
+		bson.M{"$set": updateDoc},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&hook)
+	if err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrWebhookNotFound))
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook DELETE /projects/:id/webhooks/:hookId
+func DeleteWebhook(c *gin.Context) {
+	hookID, err := primitive.ObjectIDFromHex(c.Param("hookId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("webhookId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+
+	res, err := db.Database.Collection("webhooks").DeleteOne(ctx, bson.M{"_id": hookID, "projectId": proj.ID})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("delete failed", err))
+		return
+	}
+	if res.DeletedCount == 0 {
+		apierror.Abort(c, apierror.ErrWebhookNotFound)
+		return
+	}
+	if _, err := db.Database.Collection("webhookDeliveries").DeleteMany(ctx, bson.M{"webhookId": hookID}); err != nil {
+		slog.ErrorContext(ctx, "DELETE_WEBHOOK: delete deliveries error", "err", err)
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "deleted"})
+}
+
+// ListWebhookDeliveries GET /projects/:id/webhooks/:hookId/deliveries
+func ListWebhookDeliveries(c *gin.Context) {
+	hookID, err := primitive.ObjectIDFromHex(c.Param("hookId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("webhookId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(100)
+	cur, err := db.Database.Collection("webhookDeliveries").Find(ctx,
+		bson.M{"webhookId": hookID, "projectId": proj.ID}, opts)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("db error", err))
+		return
+	}
+	deliveries := []models.WebhookDelivery{}
+	if err := cur.All(ctx, &deliveries); err != nil {
+		apierror.Abort(c, apierror.Internal("decode error", err))
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook POST /projects/:id/webhooks/:hookId/deliveries/:deliveryId/redeliver
+func RedeliverWebhook(c *gin.Context) {
+	hookID, err := primitive.ObjectIDFromHex(c.Param("hookId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("webhookId"))
+		return
+	}
+	deliveryID, err := primitive.ObjectIDFromHex(c.Param("deliveryId"))
+	if err != nil {
+		apierror.Abort(c, apierror.InvalidID("deliveryId"))
+		return
+	}
+
+	ctx, cancel := requestContext(c, 5*time.Second)
+	defer cancel()
+
+	proj, ok := loadAdminProject(ctx, c)
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := db.Database.Collection("webhookDeliveries").FindOne(ctx, bson.M{
+		"_id":       deliveryID,
+		"webhookId": hookID,
+		"projectId": proj.ID,
+	}).Decode(&original); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrDeliveryNotFound))
+		return
+	}
+
+	d, err := webhooks.Redeliver(ctx, original)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("could not schedule redelivery", err))
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
+
+// publishBoardEvent ส่ง webhook event ของโปรเจกต์ที่ board นี้สังกัดอยู่
+// ถ้าส่งไม่ได้จะแค่ log ไว้ ไม่ทำให้ request หลักล้ม
+func publishBoardEvent(ctx context.Context, boardID primitive.ObjectID, event string, data interface{}) {
+	proj, err := projectForBoard(ctx, boardID)
+	if err != nil {
+		slog.ErrorContext(ctx, "WEBHOOK: project lookup error", "err", err)
+		return
+	}
+	if err := webhooks.Publish(ctx, proj.ID, event, data); err != nil {
+		slog.ErrorContext(ctx, "WEBHOOK: publish error", "err", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package idempotency ให้ client ส่ง POST / PATCH ซ้ำได้อย่างปลอดภัยด้วย header Idempotency-Key
+// request แรกของ key จะทำงานจริงแล้วเก็บ response ไว้ (TTL) request ซ้ำที่ body เหมือนเดิมจะได้ response เดิมกลับไป
+// โดยไม่ทำงานซ้ำ ส่วน key เดิมที่ body ต่างออกไปได้ 422 และระหว่างที่ request แรกยังทำอยู่ได้ 409
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+// Header ชื่อ header ที่ client ส่ง key มา
+const Header = "Idempotency-Key"
+
+// ReplayedHeader ใส่ใน response ที่ตอบซ้ำจากของที่เก็บไว้
+const ReplayedHeader = "Idempotent-Replayed"
+
+// header ของ response ที่เก็บไว้ตอบซ้ำด้วย
+var storedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Options ตั้งค่าของ Middleware
+type Options struct {
+	// MaxBody ขนาดสูงสุดของ body ทั่วไปที่อ่านเก็บไว้ก่อนเข้า handler
+	MaxBody int64
+	// MaxUpload ขนาดสูงสุดของไฟล์ใน multipart (เผื่อ header ของ multipart ให้อีก 1MB เหมือน UploadAttachment)
+	MaxUpload int64
+	// Skip route ("POST /api/v1/me/tokens") ที่ไม่ทำ idempotency เพราะ response มีความลับที่ห้ามเก็บไว้
+	Skip []string
+}
+
+// Middleware ใช้หลัง RequireAuth (key แยกตาม user) มีผลกับ POST / PATCH ที่ส่ง Idempotency-Key มาเท่านั้น
+// ถ้า store มีปัญหาจะให้ request ผ่านไปแบบไม่มี idempotency ดีกว่าใช้งานไม่ได้ทั้งหมด
+func Middleware(store Store, opts Options) gin.HandlerFunc {
+	skip := map[string]bool{}
+	for _, route := range opts.Skip {
+		skip[route] = true
+	}
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) ||
+			skip[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+		if !validKey(key) {
+			apierror.Abort(c, apierror.ErrInvalidIdempotencyKey)
+			return
+		}
+
+		// ต้องอ่าน body ทั้งก้อนก่อน handler จึงต้องจำกัดขนาดตรงนี้ (handler ยังจำกัดของตัวเองซ้ำได้)
+		limit, tooLarge := opts.MaxBody, apierror.ErrBodyTooLarge.With("maxBytes", opts.MaxBody)
+		if isMultipart(c.Request) {
+			limit, tooLarge = opts.MaxUpload+1<<20, apierror.ErrFileTooLarge.With("maxBytes", opts.MaxUpload)
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				apierror.Abort(c, tooLarge)
+				return
+			}
+			apierror.Abort(c, apierror.ErrMalformedBody.WithDetail("could not read request body").Wrap(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		id := c.GetString("userSub") + ":" + key
+		hash := fingerprint(c.Request, body)
+
+		owner, existing, err := store.Acquire(ctx, id, hash)
+		if err != nil {
+			slog.ErrorContext(ctx, "IDEMPOTENCY: store error", "err", err)
+			c.Next()
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != hash:
+				apierror.Abort(c, apierror.ErrIdempotencyKeyReused)
+			case !existing.Completed:
+				c.Header("Retry-After", "1")
+				apierror.Abort(c, apierror.ErrIdempotencyInProgress)
+			default:
+				replay(c, existing)
+			}
+			return
+		}
+
+		// งานหลัง handler ต้องทำแม้ client ตัด connection ไปแล้ว
+		storeCtx := context.WithoutCancel(ctx)
+		rec := &recorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		done := false
+		defer func() {
+			// panic: ปล่อย key ให้ retry ทำใหม่ (recovery ชั้นนอกเป็นคนตอบ 500)
+			if !done {
+				if err := store.Release(storeCtx, id, owner); err != nil {
+					slog.ErrorContext(ctx, "IDEMPOTENCY: release error", "err", err)
+				}
+			}
+		}()
+
+		c.Next()
+		apierror.Flush(c)
+		done = true
+
+		status := rec.Status()
+		if status >= 500 {
+			// server ผิดพลาด: ไม่จำผลไว้ retry จะได้ทำงานใหม่
+			if err := store.Release(storeCtx, id, owner); err != nil {
+				slog.ErrorContext(ctx, "IDEMPOTENCY: release error", "err", err)
+			}
+			return
+		}
+		header := map[string]string{}
+		for _, h := range storedHeaders {
+			if v := rec.Header().Get(h); v != "" {
+				header[h] = v
+			}
+		}
+		if err := store.Complete(storeCtx, id, owner, status, header, rec.body.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "IDEMPOTENCY: save response error", "err", err)
+		}
+	}
+}
+
+// validKey key ยาว 1-255 ตัวอักษร ASCII ที่พิมพ์ได้ (เช่น UUID)
+func validKey(key string) bool {
+	if len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return strings.HasPrefix(mediaType, "multipart/")
+}
+
+// fingerprint hash ของ method, path และ body ใช้เช็คว่า key เดิมถูกใช้กับ request อื่นหรือไม่
+// boundary ของ multipart ถูกตัดออกเพราะ client สุ่มใหม่ทุกครั้งที่ส่ง
+func fingerprint(r *http.Request, body []byte) string {
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(c *gin.Context, rec *Record) {
+	for k, v := range rec.Header {
+		c.Header(k, v)
+	}
+	c.Header(ReplayedHeader, "true")
+	c.Data(rec.Status, rec.Header["Content-Type"], rec.Body)
+	c.Abort()
+}
+
+// recorder เขียน response ตามปกติพร้อมเก็บ body ไว้
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,182 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+// newTestRouter router ที่มี POST /items นับจำนวนครั้งที่ handler ทำงานจริง
+// block (ถ้าไม่ใช่ nil) ทำให้ handler ค้างจนกว่าจะปิด channel
+func newTestRouter(t *testing.T, store Store, calls *int32, block chan struct{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(apierror.Middleware(), func(c *gin.Context) {
+		c.Set("userSub", c.GetHeader("X-Test-User"))
+		c.Next()
+	})
+	r.Use(Middleware(store, Options{MaxBody: 64, MaxUpload: 1 << 10, Skip: []string{"POST /secrets"}}))
+	r.POST("/items", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		if block != nil {
+			<-block
+		}
+		c.Header("Location", "/items/"+strconv.Itoa(int(n)))
+		c.JSON(http.StatusCreated, gin.H{"n": n})
+	})
+	r.POST("/secrets", func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		c.JSON(http.StatusCreated, gin.H{"secret": "shown-once"})
+	})
+	r.POST("/fail", func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		apierror.Abort(c, apierror.Internal("boom", nil))
+	})
+	return r
+}
+
+func post(r http.Handler, path, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", user)
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReplay(t *testing.T) {
+	var calls int32
+	r := newTestRouter(t, NewMemoryStore(time.Hour), &calls, nil)
+
+	first := post(r, "/items", "u1", "key-1", `{"title":"a"}`)
+	second := post(r, "/items", "u1", "key-1", `{"title":"a"}`)
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(ReplayedHeader) != "true" || first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("%s header: first %q, second %q", ReplayedHeader, first.Header().Get(ReplayedHeader), second.Header().Get(ReplayedHeader))
+	}
+	if second.Header().Get("Location") != "/items/1" {
+		t.Errorf("replayed Location = %q", second.Header().Get("Location"))
+	}
+
+	// key เดียวกันของอีก user เป็นคนละ key
+	if w := post(r, "/items", "u2", "key-1", `{"title":"a"}`); w.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("other user = %d, calls %d", w.Code, calls)
+	}
+}
+
+func TestKeyReusedWithDifferentPayload(t *testing.T) {
+	var calls int32
+	r := newTestRouter(t, NewMemoryStore(time.Hour), &calls, nil)
+
+	post(r, "/items", "u1", "key-1", `{"title":"a"}`)
+	w := post(r, "/items", "u1", "key-1", `{"title":"b"}`)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Fatalf("different body = %d %s", w.Code, w.Body)
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestConcurrentDuplicateInProgress(t *testing.T) {
+	var calls int32
+	block := make(chan struct{})
+	r := newTestRouter(t, NewMemoryStore(time.Hour), &calls, block)
+
+	var wg sync.WaitGroup
+	var first *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		first = post(r, "/items", "u1", "key-1", `{"title":"a"}`)
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	w := post(r, "/items", "u1", "key-1", `{"title":"a"}`)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "IDEMPOTENCY_IN_PROGRESS") {
+		t.Fatalf("duplicate while running = %d %s", w.Code, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+
+	close(block)
+	wg.Wait()
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request = %d", first.Code)
+	}
+	if w := post(r, "/items", "u1", "key-1", `{"title":"a"}`); w.Header().Get(ReplayedHeader) != "true" {
+		t.Fatal("retry after completion was not replayed")
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestServerErrorReleasesKey(t *testing.T) {
+	var calls int32
+	r := newTestRouter(t, NewMemoryStore(time.Hour), &calls, nil)
+
+	for i := 0; i < 2; i++ {
+		if w := post(r, "/fail", "u1", "key-1", `{}`); w.Code != http.StatusInternalServerError || w.Header().Get(ReplayedHeader) != "" {
+			t.Fatalf("attempt %d = %d replayed=%q", i, w.Code, w.Header().Get(ReplayedHeader))
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("5xx must not be replayed: handler ran %d times", calls)
+	}
+}
+
+func TestSkippedRouteIsNotStored(t *testing.T) {
+	var calls int32
+	store := NewMemoryStore(time.Hour)
+	r := newTestRouter(t, store, &calls, nil)
+
+	post(r, "/secrets", "u1", "key-1", `{}`)
+	post(r, "/secrets", "u1", "key-1", `{}`)
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+	if len(store.records) != 0 {
+		t.Fatalf("secret response stored: %v", store.records)
+	}
+}
+
+func TestBodyLimitAndKeyValidation(t *testing.T) {
+	var calls int32
+	r := newTestRouter(t, NewMemoryStore(time.Hour), &calls, nil)
+
+	if w := post(r, "/items", "u1", "key-1", `{"title":"`+strings.Repeat("x", 100)+`"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("large body = %d %s", w.Code, w.Body)
+	}
+	if w := post(r, "/items", "u1", "bad\nkey", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid key = %d", w.Code)
+	}
+	// ไม่มี key: ทำงานตามปกติทุกครั้ง
+	post(r, "/items", "u1", "", `{}`)
+	post(r, "/items", "u1", "", `{}`)
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore เก็บ key ไว้ใน memory ของ process (ใช้ได้เมื่อรัน replica เดียว)
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore สร้าง store แบบ in-memory ที่เก็บ response ไว้ ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, records: map[string]*Record{}}
+}
+
+func (s *MemoryStore) Acquire(_ context.Context, id, hash string) (string, *Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.records[id]; ok {
+		// กติกาเดียวกับ MongoStore: หมดอายุแล้ว หรือ request เดิมตายไปโดยไม่ปล่อย key เขียนทับได้
+		stale := !existing.Completed && existing.RequestHash == hash && !existing.LockedUntil.After(now)
+		if existing.ExpiresAt.After(now) && !stale {
+			copied := *existing
+			return "", &copied, nil
+		}
+	}
+	fresh := newRecord(id, hash, now, s.ttl)
+	s.records[id] = &fresh
+	return fresh.Owner, nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, id, owner string, status int, header map[string]string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[id]; ok && r.Owner == owner {
+		r.Completed = true
+		r.Status = status
+		r.Header = header
+		r.Body = append([]byte(nil), body...)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[id]; ok && r.Owner == owner && !r.Completed {
+		delete(s.records, id)
+	}
+	return nil
+}
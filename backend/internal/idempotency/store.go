@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// lockTimeout เวลาที่ request แรกถือ key ไว้ได้ (นานกว่า writeTimeout ของ server)
+// เกินนี้แล้วยังไม่เสร็จถือว่า process ตายไประหว่างทาง request ถัดไปทำแทนได้
+const lockTimeout = 3 * time.Minute
+
+// Record สถานะของ key หนึ่งตัวต่อ user + key
+type Record struct {
+	ID          string            `bson:"_id"`
+	RequestHash string            `bson:"requestHash"`
+	Owner       string            `bson:"owner"` // สุ่มใหม่ทุกครั้งที่จอง กันไม่ให้ request ที่หมดเวลาไปแล้วเขียนทับคนที่ทำแทน
+	Completed   bool              `bson:"completed"`
+	LockedUntil time.Time         `bson:"lockedUntil"`
+	Status      int               `bson:"status,omitempty"`
+	Header      map[string]string `bson:"header,omitempty"`
+	Body        []byte            `bson:"body,omitempty"`
+	CreatedAt   time.Time         `bson:"createdAt"`
+	ExpiresAt   time.Time         `bson:"expiresAt"`
+}
+
+// Store เก็บสถานะและ response ของแต่ละ key
+type Store interface {
+	// Acquire จอง key ให้ request นี้ (มีผู้ชนะแค่คนเดียวแม้ส่งมาพร้อมกัน)
+	// จองได้คืน owner ไว้ใช้กับ Complete / Release ถ้าจองไม่ได้คืน record ที่มีอยู่แล้ว
+	// ให้ผู้เรียกตัดสินว่าจะตอบซ้ำ / 409 / 422
+	Acquire(ctx context.Context, id, hash string) (owner string, existing *Record, err error)
+	// Complete เก็บ response ไว้ตอบซ้ำ
+	Complete(ctx context.Context, id, owner string, status int, header map[string]string, body []byte) error
+	// Release ปล่อย key ที่ยังไม่เสร็จ (request ล้มเหลวด้วย 5xx / panic) ให้ retry ทำใหม่ได้
+	Release(ctx context.Context, id, owner string) error
+}
+
+// newRecord record ที่จองใหม่ของ key
+func newRecord(id, hash string, now time.Time, ttl time.Duration) Record {
+	return Record{
+		ID:          id,
+		RequestHash: hash,
+		Owner:       primitive.NewObjectID().Hex(),
+		LockedUntil: now.Add(lockTimeout),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// MongoStore เก็บ key ไว้ใน collection idempotencyKeys (ทุก replica เห็นเหมือนกัน)
+type MongoStore struct {
+	coll *mongo.Collection
+	ttl  time.Duration
+}
+
+// NewMongoStore สร้าง store ที่ใช้ collection idempotencyKeys โดยเก็บ response ไว้ ttl
+func NewMongoStore(database *mongo.Database, ttl time.Duration) *MongoStore {
+	return &MongoStore{coll: database.Collection("idempotencyKeys"), ttl: ttl}
+}
+
+// EnsureIndexes สร้าง TTL index ให้ key ที่หมดอายุถูกลบเอง
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Acquire ใช้ unique _id ตัดสินผู้ชนะ
+func (s *MongoStore) Acquire(ctx context.Context, id, hash string) (string, *Record, error) {
+	now := time.Now()
+	fresh := newRecord(id, hash, now, s.ttl)
+	_, err := s.coll.InsertOne(ctx, fresh)
+	if err == nil {
+		return fresh.Owner, nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return "", nil, err
+	}
+
+	// เอกสารเดิมหมดอายุแล้วแต่ TTL monitor ยังไม่ลบ หรือ request เดิมตายไปโดยไม่ปล่อย key: เขียนทับได้
+	res, err := s.coll.ReplaceOne(ctx, bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+			bson.M{"completed": false, "requestHash": hash, "lockedUntil": bson.M{"$lte": now}},
+		},
+	}, fresh)
+	if err != nil {
+		return "", nil, err
+	}
+	if res.MatchedCount == 1 {
+		return fresh.Owner, nil, nil
+	}
+
+	var existing Record
+	err = s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&existing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// ถูกปล่อย (5xx) ไประหว่างนี้ ลองจองใหม่
+		return s.Acquire(ctx, id, hash)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return "", &existing, nil
+}
+
+func (s *MongoStore) Complete(ctx context.Context, id, owner string, status int, header map[string]string, body []byte) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": id, "owner": owner}, bson.M{"$set": bson.M{
+		"completed": true,
+		"status":    status,
+		"header":    header,
+		"body":      body,
+	}})
+	return err
+}
+
+func (s *MongoStore) Release(ctx context.Context, id, owner string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": id, "owner": owner, "completed": false})
+	return err
+}
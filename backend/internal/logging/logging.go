@@ -0,0 +1,115 @@
+// Package logging ตั้งค่า log/slog ของทั้ง server: JSON (หรือ text ตอน dev), ระดับ log ที่ตั้งได้,
+// ใส่ request id / trace id จาก context ให้ทุกบรรทัด และปิดบังข้อมูลส่วนตัว (PII) ก่อนเขียน
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"mini-taskmgr-backend/internal/tracing"
+)
+
+// Setup สร้าง logger แล้วตั้งเป็น slog.Default (log.Println เดิมจะออกผ่าน logger นี้ด้วย)
+// level: debug | info | warn | error, format: json | text
+func Setup(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("logging: invalid level %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+
+	var h slog.Handler
+	switch format {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("logging: invalid format %q (use json or text)", format)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID ใส่ request id ลง ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID request id ของ ctx ("" ถ้าไม่มี)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler เติม request_id, trace_id และ span_id จาก ctx ให้ทุก record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		r.AddAttrs(slog.String("trace_id", span.TraceIDHex()), slog.String("span_id", span.SpanIDHex()))
+	}
+	// ข้อความที่ประกอบเอง (เช่นจาก log.Println เดิม) อาจมี email ปนมา
+	r.Message = redactString(r.Message)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// key ที่ค่าต้องไม่ลง log เลย (เทียบแบบไม่สนตัวพิมพ์ และเป็นส่วนหนึ่งของชื่อก็นับ)
+var secretKeys = []string{"password", "secret", "token", "authorization", "cookie", "recoverycode"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redactAttr ใช้เป็น ReplaceAttr ของ handler
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	// รหัส TOTP
+	if key == "code" {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	for _, k := range secretKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, "[REDACTED]")
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactString(a.Value.String()))
+	case slog.KindAny:
+		// เช่น error ที่ข้อความมี email ของ user
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, redactString(err.Error()))
+		}
+	}
+	return a
+}
+
+// redactString ปิด email ในข้อความ เหลือตัวแรกกับ domain ไว้พอให้ debug ได้ เช่น j***@example.com
+func redactString(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		at := strings.LastIndexByte(email, '@')
+		return email[:1] + "***" + email[at:]
+	})
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader header ที่รับ / ส่ง request id
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware ใช้ X-Request-ID ที่ proxy / client ส่งมา (ถ้ารูปแบบปลอดภัย) หรือสร้างใหม่
+// แล้วใส่ไว้ใน context ของ request และส่งกลับใน response header
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Set("requestId", id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog เขียน log หนึ่งบรรทัดต่อ request (ไม่เขียน query string เพราะอาจมี token เช่นลิงก์ดาวน์โหลด)
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if sub, ok := c.Get("userSub"); ok {
+			attrs = append(attrs, slog.Any("user_id", sub))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// Recovery แทน gin.Recovery: log panic พร้อม stack ผ่าน slog (มี request id ด้วย)
+// แล้วใส่ error ไว้ใน c.Errors ให้ middleware ของ apierror ตอบ 500
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// client ตัด connection ไประหว่างเขียน response: ไม่ต้องตอบอะไรแล้ว
+			if err, ok := rec.(error); ok && isBrokenPipe(err) {
+				slog.WarnContext(c.Request.Context(), "connection closed by client", "err", err)
+				c.Abort()
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "panic recovered",
+				"panic", rec,
+				"stack", string(debug.Stack()),
+			)
+			_ = c.Error(fmt.Errorf("panic: %v", rec))
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if !errors.As(opErr.Err, &sysErr) {
+		return false
+	}
+	msg := strings.ToLower(sysErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
+
+// validRequestID รับเฉพาะ id ที่สั้นและมีแต่ตัวอักษรที่ปลอดภัย (กัน log injection)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
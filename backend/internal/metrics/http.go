@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+var (
+	httpRequests = NewCounterVec("http_requests_total",
+		"Number of HTTP requests by method, route template and status code.",
+		"method", "route", "status")
+	httpDuration = NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method, route template and status code.",
+		DefBuckets, "method", "route", "status")
+	httpInFlight = NewGauge("http_requests_in_flight",
+		"Number of HTTP requests currently being served.")
+)
+
+// Middleware นับ request และจับเวลา โดยใช้ route template (เช่น /api/v1/tasks/:id) เป็น label
+// path ที่ไม่ตรงกับ route ไหนรวมเป็น "unmatched" เพื่อไม่ให้จำนวน series โตตาม URL ที่ถูกยิงเข้ามา
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.Inc(c.Request.Method, route, status)
+		httpDuration.Observe(Since(start), c.Request.Method, route, status)
+	}
+}
+
+// Handler GET /metrics
+// ถ้าตั้ง token ไว้ ต้องส่ง Authorization: Bearer <token> มาด้วย (ตัวเลข business อย่างจำนวน task ไม่ควรเปิด public)
+func Handler(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" {
+			got := c.GetHeader("Authorization")
+			if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) != 1 {
+				apierror.Abort(c, apierror.ErrUnauthorized)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		_ = WriteAll(ctx, c.Writer)
+	}
+}
@@ -0,0 +1,269 @@
+// Package metrics เก็บ metric ของ server แล้วตอบเป็น Prometheus text format (version 0.0.4) ที่ /metrics
+// เขียนเองแบบง่าย ๆ: counter, gauge และ histogram ที่มี label
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefBuckets bucket (วินาที) ของ latency ของ HTTP request
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(ctx context.Context, w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	registry = append(registry, c)
+	registryMu.Unlock()
+}
+
+// WriteAll เขียนทุก metric ที่ลงทะเบียนไว้
+func WriteAll(ctx context.Context, w io.Writer) error {
+	registryMu.Lock()
+	cs := append([]collector(nil), registry...)
+	registryMu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range cs {
+		c.write(ctx, bw)
+	}
+	return bw.Flush()
+}
+
+// CounterVec counter ที่แยกค่าตาม label
+type CounterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounterVec สร้างและลงทะเบียน counter
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, series: map[string]*counterSeries{}}
+	register(c)
+	return c
+}
+
+// Add เพิ่มค่า (values เรียงตาม label ที่ประกาศไว้)
+func (c *CounterVec) Add(v float64, values ...string) {
+	key := seriesKey(values)
+	c.mu.Lock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: values}
+		c.series[key] = s
+	}
+	s.value += v
+	c.mu.Unlock()
+}
+
+// Inc เพิ่มค่า 1
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *CounterVec) write(_ context.Context, w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, s.values), formatFloat(s.value))
+	}
+}
+
+// HistogramVec histogram ที่แยกตาม label
+type HistogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // ต่อ bucket (ยังไม่สะสม)
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec สร้างและลงทะเบียน histogram (buckets เรียงจากน้อยไปมาก)
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	register(h)
+	return h
+}
+
+// Observe บันทึกค่าหนึ่งครั้ง
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	key := seriesKey(values)
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+	h.mu.Unlock()
+}
+
+func (h *HistogramVec) write(_ context.Context, w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(labels, append(append([]string(nil), s.values...), formatFloat(le))), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(labels, append(append([]string(nil), s.values...), "+Inf")), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelPairs(h.labels, s.values), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelPairs(h.labels, s.values), s.count)
+	}
+}
+
+// Gauge ค่าที่ขึ้นลงได้ (เช่นจำนวน connection ที่เปิดอยู่)
+type Gauge struct {
+	name, help string
+	value      atomic.Int64
+}
+
+// NewGauge สร้างและลงทะเบียน gauge
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Inc()        { g.value.Add(1) }
+func (g *Gauge) Dec()        { g.value.Add(-1) }
+func (g *Gauge) Set(v int64) { g.value.Store(v) }
+
+func (g *Gauge) write(_ context.Context, w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %d\n", g.name, g.value.Load())
+}
+
+// gaugeFunc gauge ที่อ่านค่าตอนมีคน scrape
+type gaugeFunc struct {
+	name, help string
+	fn         func(ctx context.Context) (float64, error)
+}
+
+// NewGaugeFunc ลงทะเบียน gauge ที่เรียก fn ทุกครั้งที่ scrape
+// ถ้า fn คืน error จะข้าม metric นั้นไปในรอบนั้น
+func NewGaugeFunc(name, help string, fn func(ctx context.Context) (float64, error)) {
+	register(&gaugeFunc{name: name, help: help, fn: fn})
+}
+
+func (g *gaugeFunc) write(ctx context.Context, w io.Writer) {
+	v, err := g.fn(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "METRICS: gauge error", "metric", g.name, "err", err)
+		return
+	}
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(v))
+}
+
+// RegisterGoRuntime ลงทะเบียน go_goroutines (ชื่อเดียวกับของ Prometheus Go client)
+func RegisterGoRuntime() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func(context.Context) (float64, error) {
+		return float64(runtime.NumGoroutine()), nil
+	})
+}
+
+// Since วินาทีตั้งแต่ start (ใช้กับ Observe)
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help), name, typ)
+}
+
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(v))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func render(c collector) string {
+	var b bytes.Buffer
+	c.write(context.Background(), &b)
+	return b.String()
+}
+
+func TestCounterExposition(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "Requests with a \\ and\na newline.", "path", "code")
+	c.Inc(`/b`, "200")
+	c.Add(2.5, `/a"quoted"`, "200")
+	c.Inc(`C:\dir`+"\nnext", "500")
+	c.Inc(`/b`, "200")
+
+	want := `# HELP test_requests_total Requests with a \\ and\na newline.
+# TYPE test_requests_total counter
+test_requests_total{path="/a\"quoted\"",code="200"} 2.5
+test_requests_total{path="/b",code="200"} 2
+test_requests_total{path="C:\\dir\nnext",code="500"} 1
+`
+	if got := render(c); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogramVec("test_duration_seconds", "Latency.", []float64{.5, 1, 2}, "route")
+	for _, v := range []float64{.25, .5, .75, 4} { // .5 อยู่ใน bucket le="0.5" พอดี
+		h.Observe(v, "/x")
+	}
+	h.Observe(1, "/y")
+
+	want := `# HELP test_duration_seconds Latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/x",le="0.5"} 2
+test_duration_seconds_bucket{route="/x",le="1"} 3
+test_duration_seconds_bucket{route="/x",le="2"} 3
+test_duration_seconds_bucket{route="/x",le="+Inf"} 4
+test_duration_seconds_sum{route="/x"} 5.5
+test_duration_seconds_count{route="/x"} 4
+test_duration_seconds_bucket{route="/y",le="0.5"} 0
+test_duration_seconds_bucket{route="/y",le="1"} 1
+test_duration_seconds_bucket{route="/y",le="2"} 1
+test_duration_seconds_bucket{route="/y",le="+Inf"} 1
+test_duration_seconds_sum{route="/y"} 1
+test_duration_seconds_count{route="/y"} 1
+`
+	if got := render(h); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGauges(t *testing.T) {
+	g := NewGauge("test_in_flight", "In flight.")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	if got, want := render(g), "# HELP test_in_flight In flight.\n# TYPE test_in_flight gauge\ntest_in_flight 1\n"; got != want {
+		t.Errorf("gauge = %q, want %q", got, want)
+	}
+
+	ok := &gaugeFunc{name: "test_items", help: "Items.", fn: func(context.Context) (float64, error) { return 1e6, nil }}
+	if got, want := render(ok), "# HELP test_items Items.\n# TYPE test_items gauge\ntest_items 1e+06\n"; got != want {
+		t.Errorf("gauge func = %q, want %q", got, want)
+	}
+
+	// error: ไม่เขียนอะไรเลยในรอบนั้น (แม้แต่ HELP / TYPE)
+	failing := &gaugeFunc{name: "test_broken", help: "Broken.", fn: func(context.Context) (float64, error) { return 0, errors.New("down") }}
+	if got := render(failing); got != "" {
+		t.Errorf("failing gauge func wrote %q", got)
+	}
+}
+
+func TestGoRuntime(t *testing.T) {
+	RegisterGoRuntime()
+	var b bytes.Buffer
+	if err := WriteAll(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "# TYPE go_goroutines gauge\n") {
+		t.Fatalf("go_goroutines missing:\n%s", b.String())
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if v, ok := strings.CutPrefix(line, "go_goroutines "); ok {
+			if n, err := strconv.Atoi(v); err != nil || n < 1 {
+				t.Errorf("go_goroutines = %q", v)
+			}
+			return
+		}
+	}
+	t.Error("no go_goroutines sample")
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoBuckets bucket (วินาที) ของ latency ของคำสั่ง MongoDB
+var MongoBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+var (
+	mongoDuration = NewHistogramVec("mongodb_command_duration_seconds",
+		"MongoDB command latency by collection and command.",
+		MongoBuckets, "collection", "command")
+	mongoErrors = NewCounterVec("mongodb_command_errors_total",
+		"Number of failed MongoDB commands by collection and command.",
+		"collection", "command")
+)
+
+// MongoMonitor command monitor ที่จับเวลาทุกคำสั่งแยกตาม collection (collection ดึงชื่อ collection จากคำสั่ง)
+func MongoMonitor(collection func(e *event.CommandStartedEvent) string) *event.CommandMonitor {
+	// จำ collection ของคำสั่งที่กำลังรันไว้ เพราะ event ตอนจบไม่มีตัวคำสั่งมาด้วย
+	var pending sync.Map // requestID -> collection
+
+	finish := func(requestID int64, command string, seconds float64, failed bool) {
+		v, ok := pending.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		coll := v.(string)
+		mongoDuration.Observe(seconds, coll, command)
+		if failed {
+			mongoErrors.Inc(coll, command)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			pending.Store(e.RequestID, collection(e))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, e.CommandName, e.Duration.Seconds(), false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.CommandName, e.Duration.Seconds(), true)
+		},
+	}
+}
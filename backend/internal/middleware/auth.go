@@ -1,32 +1,171 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
+	"context"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/apierror"
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/utils"
+)
+
+// scope ที่กำหนดให้ personal access token ได้
+const (
+	ScopeProfileRead        = "profile:read"
+	ScopeProfileWrite       = "profile:write"
+	ScopeProjectsRead       = "projects:read"
+	ScopeProjectsWrite      = "projects:write"
+	ScopeTasksRead          = "tasks:read"
+	ScopeTasksWrite         = "tasks:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
 )
 
+// Scopes คือ scope ทั้งหมดที่รู้จัก
+var Scopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+}
+
+// RequireAuth รับได้ทั้ง JWT จาก Login และ personal access token (mtm_pat_...)
+// ถ้าเป็น PAT จะเซ็ต "tokenScopes" ไว้ให้ RequireScope ตรวจต่อ
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth := c.GetHeader("Authorization")
 		if !strings.HasPrefix(auth, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+			apierror.Abort(c, apierror.ErrUnauthorized.WithDetail("missing token"))
 			return
 		}
 		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		claims := jwt.MapClaims{}
-		tk, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-		if err != nil || !tk.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+
+		if utils.IsPersonalAccessToken(tokenStr) {
+			requirePersonalAccessToken(c, tokenStr)
+			return
+		}
+
+		// ตรวจลายเซ็น (RS256/EdDSA ตาม kid), iss, aud, iat, exp, jti และต้องเป็น access token
+		// reset token หรือ challenge token ของ 2FA จึงใช้แทนไม่ได้
+		claims, err := utils.VerifyAccessToken(tokenStr)
+		if err != nil {
+			apierror.Abort(c, apierror.ErrUnauthorized)
+			return
+		}
+
+		// โหลด user ทุก request: บัญชีที่ถูกปิด หรือ token ที่ออกก่อนถูกเพิกถอน จะใช้ไม่ได้ทันที
+		// และ role ที่ใช้คือ role ปัจจุบันใน DB ไม่ใช่ role ตอนออก token
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+		oid, _ := primitive.ObjectIDFromHex(claims.Sub)
+		var u models.User
+		if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": oid},
+			options.FindOne().SetProjection(bson.M{"role": 1, "disabled": 1, "tokensValidAfter": 1}),
+		).Decode(&u); err != nil {
+			apierror.Abort(c, apierror.Lookup(err, apierror.ErrUnauthorized))
+			return
+		}
+		if u.Disabled {
+			apierror.Abort(c, apierror.ErrAccountDisabled)
+			return
+		}
+		if u.TokensValidAfter != nil && claims.IssuedAt.Time.Before(*u.TokensValidAfter) {
+			apierror.Abort(c, apierror.ErrTokenRevoked)
+			return
+		}
+
+		c.Set("userSub", claims.Sub)
+		c.Set("userRole", string(u.Role))
+		c.Next()
+	}
+}
+
+func requirePersonalAccessToken(c *gin.Context, tokenStr string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	tokens := db.Database.Collection("personalAccessTokens")
+	var pat models.PersonalAccessToken
+	if err := tokens.FindOne(ctx, bson.M{
+		"tokenHash": utils.HashToken(tokenStr),
+		"revokedAt": bson.M{"$exists": false},
+	}).Decode(&pat); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUnauthorized))
+		return
+	}
+	if pat.ExpiresAt != nil && now.After(*pat.ExpiresAt) {
+		apierror.Abort(c, apierror.ErrTokenExpired)
+		return
+	}
+
+	var u models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": pat.UserID}).Decode(&u); err != nil {
+		apierror.Abort(c, apierror.Lookup(err, apierror.ErrUnauthorized))
+		return
+	}
+
+	if u.Disabled {
+		apierror.Abort(c, apierror.ErrAccountDisabled)
+		return
+	}
+
+	_, _ = tokens.UpdateByID(ctx, pat.ID, bson.M{"$set": bson.M{"lastUsedAt": now}})
+
+	c.Set("userSub", u.ID.Hex())
+	c.Set("userRole", string(u.Role))
+	c.Set("tokenScopes", pat.Scopes)
+	c.Next()
+}
+
+// RequireScope ให้ผ่านเมื่อ login ด้วย JWT หรือ PAT ที่มี scope นี้
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, ok := c.Get("tokenScopes")
+		if !ok {
+			c.Next()
+			return
+		}
+		for _, s := range v.([]string) {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+		apierror.Abort(c, apierror.ErrInsufficientScope.WithDetail("token is missing scope "+scope).With("scope", scope))
+	}
+}
+
+// RequireSession ใช้กับ route ที่อ่อนไหว (เปลี่ยนรหัสผ่าน, ลบบัญชี, จัดการ token)
+// ให้ผ่านเฉพาะ JWT จากการ login เท่านั้น
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("tokenScopes"); ok {
+			apierror.Abort(c, apierror.ErrSessionRequired)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin ให้ผ่านเฉพาะ user ที่มี role ADMIN ของทั้งระบบ (ใช้หลัง RequireAuth)
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("userRole") != string(models.RoleAdmin) {
+			apierror.Abort(c, apierror.ErrAdminRequired)
 			return
 		}
-		c.Set("userSub", claims["sub"])
-		c.Set("userRole", claims["role"])
 		c.Next()
 	}
 }
@@ -20,6 +20,36 @@ type User struct {
 	Email        string             `bson:"email" json:"email"`
 	PasswordHash string             `bson:"passwordHash" json:"-"`
 	Role         Role               `bson:"role" json:"role"`
+	CreatedAt    time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+	// Disabled บัญชีถูกปิดโดย admin (login / ใช้ token ไม่ได้)
+	Disabled bool `bson:"disabled,omitempty" json:"disabled,omitempty"`
+	// MustResetPassword admin สั่งให้ตั้งรหัสผ่านใหม่ก่อน login ด้วยรหัสผ่านได้อีกครั้ง
+	MustResetPassword bool `bson:"mustResetPassword,omitempty" json:"-"`
+	// TokensValidAfter access token ที่ออกก่อนเวลานี้ใช้ไม่ได้แล้ว (เช่นหลังถูกสั่ง reset รหัสผ่าน)
+	TokensValidAfter *time.Time `bson:"tokensValidAfter,omitempty" json:"-"`
+	// TOTP 2FA: secret ที่ยืนยันแล้ว, secret ที่รอยืนยัน และ hash ของ recovery code ที่ยังไม่ถูกใช้
+	TOTPEnabled       bool     `bson:"totpEnabled,omitempty" json:"twoFactorEnabled"`
+	TOTPSecret        string   `bson:"totpSecret,omitempty" json:"-"`
+	TOTPPendingSecret string   `bson:"totpPendingSecret,omitempty" json:"-"`
+	TOTPLastStep      int64    `bson:"totpLastStep,omitempty" json:"-"`
+	RecoveryCodes     []string `bson:"recoveryCodes,omitempty" json:"-"`
+	// OIDCIdentities บัญชีจาก identity provider ที่ผูกไว้ (login ผ่าน SSO)
+	OIDCIdentities []OIDCIdentity `bson:"oidcIdentities,omitempty" json:"-"`
+	// NotificationPrefs ตั้งค่าว่าแต่ละ event จะแจ้งเตือนในแอป / ทางอีเมลหรือไม่
+	NotificationPrefs map[NotificationType]NotificationChannels `bson:"notificationPrefs,omitempty" json:"notificationPrefs,omitempty"`
+}
+
+// DeletedUserID user กลางที่ใช้แทนบัญชีที่ลบแบบ anonymize
+// task / comment / activity ที่เคยอ้างถึงบัญชีนั้นจะชี้มาที่ user นี้ ("Deleted user") แทน
+var DeletedUserID, _ = primitive.ObjectIDFromHex("000000000000000000000de1")
+
+// DeletedUserName ชื่อที่แสดงแทนบัญชีที่ถูกลบแบบ anonymize
+const DeletedUserName = "Deleted user"
+
+// OIDCIdentity ระบุบัญชีที่ IdP ด้วยชื่อ provider และ sub ของ ID token
+type OIDCIdentity struct {
+	Provider string `bson:"provider" json:"provider"`
+	Subject  string `bson:"subject" json:"subject"`
 }
 
 type Project struct {
@@ -31,6 +61,10 @@ type Project struct {
 	Members     []ProjectMember    `bson:"members" json:"members"`
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// AttachmentBytes ขนาดรวมของไฟล์แนบทั้งโปรเจกต์ (ใช้เช็ค quota)
+	AttachmentBytes int64 `bson:"attachmentBytes,omitempty" json:"attachmentBytes"`
+	// Version เพิ่มทีละ 1 ทุกครั้งที่แก้ (ใช้เป็น ETag) เอกสารเก่าที่ไม่มี field นี้ถือเป็น 0
+	Version int64 `bson:"version" json:"version"`
 }
 
 type ProjectMember struct {
@@ -44,12 +78,48 @@ type Board struct {
 	ProjectID primitive.ObjectID `bson:"projectId" json:"projectId"`
 }
 
+// ColumnCategory บอกว่า column อยู่ช่วงไหนของ workflow
+type ColumnCategory string
+
+const (
+	ColumnBacklog ColumnCategory = "backlog"
+	ColumnActive  ColumnCategory = "active"
+	ColumnDone    ColumnCategory = "done"
+)
+
+// Valid คืน true ถ้าเป็น category ที่รู้จัก
+func (cc ColumnCategory) Valid() bool {
+	switch cc {
+	case ColumnBacklog, ColumnActive, ColumnDone:
+		return true
+	}
+	return false
+}
+
 type Column struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name     string             `bson:"name" json:"name"`
 	Position int                `bson:"position" json:"position"`
 	WipLimit *int               `bson:"wipLimit,omitempty" json:"wipLimit,omitempty"`
+	Category ColumnCategory     `bson:"category,omitempty" json:"category"`
 	BoardID  primitive.ObjectID `bson:"boardId" json:"boardId"`
+	Version  int64              `bson:"version" json:"version"`
+}
+
+// ค่า priority ของ task
+const (
+	TaskPriorityLow    = "LOW"
+	TaskPriorityMedium = "MEDIUM"
+	TaskPriorityHigh   = "HIGH"
+)
+
+// ValidTaskPriority คืน true ถ้าเป็น priority ที่รู้จัก
+func ValidTaskPriority(p string) bool {
+	switch p {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh:
+		return true
+	}
+	return false
 }
 
 type Task struct {
@@ -65,6 +135,142 @@ type Task struct {
 	CreatedByID primitive.ObjectID   `bson:"createdById" json:"createdById"`
 	Assignees   []primitive.ObjectID `bson:"assignees" json:"assignees"`
 	Labels      []primitive.ObjectID `bson:"labels" json:"labels"`
+	CreatedAt   time.Time            `bson:"createdAt" json:"createdAt"`
+	StartedAt   *time.Time           `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	CompletedAt *time.Time           `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+	// RemindedAt เวลาที่ส่ง due reminder แต่ละชนิดของ dueDate ปัจจุบัน (ล้างเมื่อ dueDate เปลี่ยน)
+	RemindedAt map[NotificationType]time.Time `bson:"remindedAt,omitempty" json:"-"`
+	Version    int64                          `bson:"version" json:"version"`
+}
+
+type TaskEventType string
+
+const (
+	TaskEventCreated TaskEventType = "created"
+	TaskEventMoved   TaskEventType = "moved"
+	TaskEventDeleted TaskEventType = "deleted"
+)
+
+// TaskEvent เก็บประวัติการเคลื่อนที่ของ task ระหว่าง column (ใช้ทำ analytics)
+type TaskEvent struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TaskID       primitive.ObjectID  `bson:"taskId" json:"taskId"`
+	BoardID      primitive.ObjectID  `bson:"boardId" json:"boardId"`
+	Type         TaskEventType       `bson:"type" json:"type"`
+	FromColumnID *primitive.ObjectID `bson:"fromColumnId,omitempty" json:"fromColumnId,omitempty"`
+	ToColumnID   *primitive.ObjectID `bson:"toColumnId,omitempty" json:"toColumnId,omitempty"`
+	ActorID      primitive.ObjectID  `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	At           time.Time           `bson:"at" json:"at"`
+}
+
+// TaskDependency บอกว่า BlockerID ต้องเสร็จก่อน BlockedID (ทั้งสอง task อยู่ใน project เดียวกัน)
+type TaskDependency struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID   primitive.ObjectID `bson:"projectId" json:"projectId"`
+	BlockerID   primitive.ObjectID `bson:"blockerId" json:"blockerId"`
+	BlockedID   primitive.ObjectID `bson:"blockedId" json:"blockedId"`
+	CreatedByID primitive.ObjectID `bson:"createdById" json:"createdById"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+type NotificationType string
+
+const (
+	NotificationTaskAssigned   NotificationType = "task_assigned"
+	NotificationCommentMention NotificationType = "comment_mention"
+	NotificationProjectInvite  NotificationType = "project_invite"
+	NotificationTaskDueSoon    NotificationType = "task_due_soon"
+	NotificationTaskOverdue    NotificationType = "task_overdue"
+)
+
+// NotificationTypes คือ event ทั้งหมดที่ผู้ใช้ตั้งค่าได้
+var NotificationTypes = []NotificationType{
+	NotificationTaskAssigned,
+	NotificationCommentMention,
+	NotificationProjectInvite,
+	NotificationTaskDueSoon,
+	NotificationTaskOverdue,
+}
+
+// NotificationChannels ช่องทางที่ผู้ใช้ต้องการรับแจ้งเตือน
+type NotificationChannels struct {
+	InApp bool `bson:"inApp" json:"inApp"`
+	Email bool `bson:"email" json:"email"`
+}
+
+// Notification แจ้งเตือนในแอปของผู้ใช้
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"userId" json:"userId"`
+	Type      NotificationType    `bson:"type" json:"type"`
+	Title     string              `bson:"title" json:"title"`
+	ProjectID *primitive.ObjectID `bson:"projectId,omitempty" json:"projectId,omitempty"`
+	TaskID    *primitive.ObjectID `bson:"taskId,omitempty" json:"taskId,omitempty"`
+	Read      bool                `bson:"read" json:"read"`
+	InApp     bool                `bson:"inApp" json:"-"`
+	DedupeKey string              `bson:"dedupeKey,omitempty" json:"-"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// Comment ความเห็นใน task, Mentions คือ user ที่ถูก @ ถึง
+type Comment struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	TaskID    primitive.ObjectID   `bson:"taskId" json:"taskId"`
+	AuthorID  primitive.ObjectID   `bson:"authorId" json:"authorId"`
+	Body      string               `bson:"body" json:"body"`
+	Mentions  []primitive.ObjectID `bson:"mentions" json:"mentions"`
+	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
+}
+
+// Webhook การสมัครรับ event ของโปรเจกต์ไปยัง URL ภายนอก
+type Webhook struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID   primitive.ObjectID `bson:"projectId" json:"projectId"`
+	URL         string             `bson:"url" json:"url"`
+	Secret      string             `bson:"secret" json:"-"`
+	Events      []string           `bson:"events" json:"events"`
+	Active      bool               `bson:"active" json:"active"`
+	CreatedByID primitive.ObjectID `bson:"createdById" json:"createdById"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery log ของการส่ง event หนึ่งครั้ง (รวม retry)
+type WebhookDelivery struct {
+	ID             primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	WebhookID      primitive.ObjectID    `bson:"webhookId" json:"webhookId"`
+	ProjectID      primitive.ObjectID    `bson:"projectId" json:"projectId"`
+	Event          string                `bson:"event" json:"event"`
+	Payload        string                `bson:"payload" json:"payload"`
+	Status         WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts       int                   `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time             `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LastStatusCode int                   `bson:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string                `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	RedeliveryOf   *primitive.ObjectID   `bson:"redeliveryOf,omitempty" json:"redeliveryOf,omitempty"`
+	CreatedAt      time.Time             `bson:"createdAt" json:"createdAt"`
+	DeliveredAt    *time.Time            `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
+
+// PersonalAccessToken token สำหรับ script / CI เก็บเฉพาะ hash ของ token
+type PersonalAccessToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Name       string             `bson:"name" json:"name"`
+	TokenHash  string             `bson:"tokenHash" json:"-"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	Scopes     []string           `bson:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
 }
 
 // PasswordResetToken stores reset tokens for password recovery
@@ -77,3 +283,16 @@ type PasswordResetToken struct {
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 	Used      bool               `bson:"used" json:"used"`
 }
+
+// Attachment ไฟล์แนบของ task ตัวไฟล์อยู่ใน storage ที่ StorageKey
+type Attachment struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID       primitive.ObjectID `bson:"taskId" json:"taskId"`
+	ProjectID    primitive.ObjectID `bson:"projectId" json:"projectId"`
+	FileName     string             `bson:"fileName" json:"fileName"`
+	ContentType  string             `bson:"contentType" json:"contentType"`
+	Size         int64              `bson:"size" json:"size"`
+	StorageKey   string             `bson:"storageKey" json:"-"`
+	UploadedByID primitive.ObjectID `bson:"uploadedById" json:"uploadedById"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+}
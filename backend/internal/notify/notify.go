@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// Mailer ส่งอีเมลแจ้งเตือน
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LogMailer แค่ log อีเมลออกมา (ใช้ตอน dev ที่ยังไม่มี SMTP)
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, to, subject, _ string) error {
+	slog.InfoContext(ctx, "MAIL: send", "to", to, "subject", subject)
+	return nil
+}
+
+// DefaultMailer ใช้ส่งอีเมลของทุก notification
+var DefaultMailer Mailer = LogMailer{}
+
+func collection() *mongo.Collection {
+	return db.Database.Collection("notifications")
+}
+
+// EnsureIndexes สร้าง index ที่ notifications ต้องใช้ (เรียกตอน start server)
+func EnsureIndexes(ctx context.Context) error {
+	_, err := collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "read", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{
+			// กันแจ้งเตือนซ้ำ เช่นหลาย replica หรือ scheduler รอบถัดไป
+			Keys: bson.D{{Key: "dedupeKey", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"dedupeKey": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+// DefaultPreferences ค่าเริ่มต้น: แจ้งในแอปทุก event, ไม่ส่งอีเมล
+func DefaultPreferences() map[models.NotificationType]models.NotificationChannels {
+	prefs := map[models.NotificationType]models.NotificationChannels{}
+	for _, t := range models.NotificationTypes {
+		prefs[t] = models.NotificationChannels{InApp: true}
+	}
+	return prefs
+}
+
+// Preferences รวมค่าที่ผู้ใช้ตั้งไว้เข้ากับค่าเริ่มต้น
+func Preferences(u models.User) map[models.NotificationType]models.NotificationChannels {
+	prefs := DefaultPreferences()
+	for t, ch := range u.NotificationPrefs {
+		if _, ok := prefs[t]; ok {
+			prefs[t] = ch
+		}
+	}
+	return prefs
+}
+
+// Send ส่ง notification ให้ผู้ใช้ตาม preference (ในแอป และ/หรือ อีเมล)
+// ถ้ามี DedupeKey ซ้ำกับที่เคยส่งแล้วจะไม่ส่งซ้ำ และคืน sent = false
+func Send(ctx context.Context, n models.Notification) (sent bool, err error) {
+	var user models.User
+	if err := db.Database.Collection("users").FindOne(ctx, bson.M{"_id": n.UserID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	ch := Preferences(user)[n.Type]
+	if !ch.InApp && !ch.Email {
+		return false, nil
+	}
+
+	// เก็บลง collection เสมอ (แม้ปิดในแอป) เพื่อให้ DedupeKey กันส่งอีเมลซ้ำได้ด้วย
+	n.InApp = ch.InApp
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	if _, err := collection().InsertOne(ctx, n); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if ch.Email && user.Email != "" {
+		if err := DefaultMailer.Send(ctx, user.Email, n.Title, n.Title); err != nil {
+			slog.ErrorContext(ctx, "NOTIFY: email error", "err", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,263 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP identity provider จำลอง: discovery, JWKS และ token endpoint ที่ตรวจ PKCE
+type mockIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu sync.Mutex
+	// code -> สิ่งที่ IdP จำไว้ตอน user login
+	codes map[string]authRequest
+}
+
+type authRequest struct {
+	clientID, redirectURI, challenge, nonce string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{t: t, key: key, codes: map[string]authRequest{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.srv.URL,
+		"authorization_endpoint": idp.srv.URL + "/authorize",
+		"token_endpoint":         idp.srv.URL + "/token",
+		"jwks_uri":               idp.srv.URL + "/jwks",
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test-key",
+		"use": "sig",
+		"n":   b64(idp.key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(idp.key.E)).Bytes()),
+	}}})
+}
+
+// authorize แทนหน้า login ของ IdP: รับ query จาก AuthCodeURL แล้วคืน code
+func (idp *mockIdP) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+		idp.t.Fatalf("unexpected auth request %s", authURL)
+	}
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	code := "code-" + q.Get("state")
+	idp.codes[code] = authRequest{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	idp.mu.Lock()
+	req, ok := idp.codes[r.Form.Get("code")]
+	delete(idp.codes, r.Form.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || req.clientID != r.Form.Get("client_id") || req.redirectURI != r.Form.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	idToken := idp.sign(IDClaims{
+		Email:         "ann@example.com",
+		EmailVerified: true,
+		Name:          "Ann",
+		Nonce:         req.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idp.srv.URL,
+			Subject:   "user-123",
+			Audience:  jwt.ClaimStrings{req.clientID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "opaque", "token_type": "Bearer", "id_token": idToken})
+}
+
+func (idp *mockIdP) sign(claims IDClaims) string {
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "test-key"
+	s, err := tok.SignedString(idp.key)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return s
+}
+
+func newTestProvider(idp *mockIdP) *Provider {
+	p := NewProvider(Config{
+		Name:        "mock",
+		Issuer:      idp.srv.URL + "/",
+		ClientID:    "taskmgr",
+		RedirectURL: "https://tasks.example.com/api/v1/auth/oidc/mock/callback",
+	})
+	p.HTTPClient = idp.srv.Client()
+	return p
+}
+
+// login เริ่ม flow แบบที่ OIDCLogin ทำ แล้วให้ IdP ออก code
+func login(t *testing.T, idp *mockIdP, p *Provider) (code, verifier, nonce string) {
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ := RandomString(16)
+	nonce, _ = RandomString(16)
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, idp.srv.URL+"/authorize?") {
+		t.Fatalf("auth URL = %s", authURL)
+	}
+	return idp.authorize(authURL), verifier, nonce
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	code, verifier, nonce := login(t, idp, p)
+	claims, err := p.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "ann@example.com" || !claims.EmailVerified || claims.Name != "Ann" {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	code, _, nonce := login(t, idp, p)
+	other, _, _ := NewPKCE()
+	_, err := p.Exchange(context.Background(), code, other, nonce)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange with wrong verifier = %v", err)
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	code, verifier, _ := login(t, idp, p)
+	_, err := p.Exchange(context.Background(), code, verifier, "nonce-from-another-login")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("Exchange with wrong nonce = %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+	valid := func() IDClaims {
+		return IDClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idp.srv.URL,
+			Subject:   "user-123",
+			Audience:  jwt.ClaimStrings{"taskmgr"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}}
+	}
+	if _, err := p.VerifyIDToken(context.Background(), idp.sign(valid()), ""); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	wrongAudience := valid()
+	wrongAudience.Audience = jwt.ClaimStrings{"someone-else"}
+	wrongIssuer := valid()
+	wrongIssuer.Issuer = "https://evil.example.com"
+	expired := valid()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noSubject := valid()
+	noSubject.Subject = ""
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, valid())
+	forged.Header["kid"] = "test-key"
+	forgedToken, _ := forged.SignedString(otherKey)
+
+	for name, raw := range map[string]string{
+		"audience":  idp.sign(wrongAudience),
+		"issuer":    idp.sign(wrongIssuer),
+		"expired":   idp.sign(expired),
+		"subject":   idp.sign(noSubject),
+		"signature": forgedToken,
+	} {
+		if _, err := p.VerifyIDToken(context.Background(), raw, ""); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 "https://other-idp.example.com",
+			"authorization_endpoint": "https://other-idp.example.com/authorize",
+			"token_endpoint":         "https://other-idp.example.com/token",
+			"jwks_uri":               "https://other-idp.example.com/jwks",
+		})
+	}))
+	defer srv.Close()
+
+	p := NewProvider(Config{Issuer: srv.URL, ClientID: "taskmgr"})
+	p.HTTPClient = srv.Client()
+	_, err := p.AuthCodeURL(context.Background(), "s", "n", "c")
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("AuthCodeURL = %v, want issuer mismatch", err)
+	}
+}
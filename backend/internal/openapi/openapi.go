@@ -0,0 +1,422 @@
+// Package openapi เอกสาร OpenAPI 3 ของ API สร้างจากตาราง operation (operations.go)
+// กับ type ของ request / response ใน handlers ด้วย reflection จึงไม่ต้องเขียน schema เอง
+// ตอนเริ่ม server จะเทียบตารางกับ route จริงด้วย Check เพื่อไม่ให้เอกสารกับโค้ดเลื่อนออกจากกัน
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+// Version เวอร์ชันของเอกสาร (info.version)
+var Version = "dev"
+
+// Auth วิธียืนยันตัวตนของ operation
+type Auth int
+
+const (
+	// AuthNone ไม่ต้องส่ง token
+	AuthNone Auth = iota
+	// AuthBearer access token จาก login หรือ personal access token ที่มี Scope
+	AuthBearer
+	// AuthSession access token จาก login เท่านั้น (PAT ใช้ไม่ได้)
+	AuthSession
+	// AuthAdmin เหมือน AuthSession และ user ต้องเป็น ADMIN
+	AuthAdmin
+)
+
+// Param query parameter
+type Param struct {
+	Name        string
+	Type        string // string | integer | boolean
+	Description string
+	Required    bool
+}
+
+// Operation route หนึ่งตัวของ API
+type Operation struct {
+	Method  string
+	Path    string // แบบ gin เช่น /api/v1/projects/:id
+	ID      string // operationId (ชื่อ method ของ client)
+	Tag     string
+	Summary string
+	Auth    Auth
+	Scope   string // scope ที่ PAT ต้องมี (AuthBearer)
+	Query   []Param
+
+	// Request ค่าศูนย์ของ body (nil = ไม่มี body) ถ้า Upload เป็น multipart ที่มีไฟล์ใน field "file"
+	Request         interface{}
+	Upload          bool
+	RequestOptional bool
+
+	// Status status ที่ตอบเมื่อสำเร็จ, Response ค่าศูนย์ของ body (nil = ไม่มี body)
+	// ResponseType ค่าอื่นที่ไม่ใช่ JSON เช่น application/zip (body เป็นไฟล์)
+	Status       int
+	Response     interface{}
+	ResponseType string
+
+	// Browser ใช้ผ่าน browser เท่านั้น (redirect ไป identity provider) client ที่ generate จะข้ามไป
+	Browser bool
+
+	// IfMatch รับ header If-Match (version ไม่ตรงได้ 412), ETag response ที่สำเร็จมี header ETag
+	IfMatch bool
+	ETag    bool
+
+	// Secret response มีความลับ (access / reset token, PAT, TOTP secret, recovery code, webhook secret)
+	// ไม่รับ Idempotency-Key เพราะจะต้องเก็บ response ไว้ตอบซ้ำ
+	Secret bool
+}
+
+// OpenAPIPath path แบบ OpenAPI เช่น /api/v1/projects/{id}
+func (op Operation) OpenAPIPath() string {
+	parts := strings.Split(op.Path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// PathParams ชื่อ path parameter ตามลำดับใน path
+func (op Operation) PathParams() []string {
+	var names []string
+	for _, p := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			names = append(names, p[1:])
+		}
+	}
+	return names
+}
+
+// ---- เอกสาร ----
+
+// Document root ของเอกสาร OpenAPI
+type Document struct {
+	OpenAPI    string                              `json:"openapi"`
+	Info       Info                                `json:"info"`
+	Paths      map[string]map[string]*OperationDoc `json:"paths"`
+	Components Components                          `json:"components"`
+}
+
+// Info ข้อมูลของ API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OperationDoc operation หนึ่งตัวในเอกสาร (key ใน Paths คือ path แล้วตามด้วย method ตัวเล็ก)
+type OperationDoc struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Security    []map[string][]string  `json:"security"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]Response    `json:"responses"`
+	Extensions  map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON ใส่ x-* extension ไว้ระดับเดียวกับ field อื่น
+func (p OperationDoc) MarshalJSON() ([]byte, error) {
+	type plain OperationDoc
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range p.Extensions {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = raw
+	}
+	return json.Marshal(m)
+}
+
+// Parameter path / query / header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody body ของ request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response response ตาม status
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header header ของ response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType schema ของ content type หนึ่ง
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components schema และ security scheme ที่ใช้ร่วมกัน
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme วิธียืนยันตัวตน
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Problem body ของ error ทุกตัว (ดู apierror.Middleware)
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail"`
+	Instance  string                `json:"instance"`
+	Code      string                `json:"code"`
+	RequestID string                `json:"requestId,omitempty"`
+	Errors    []apierror.FieldError `json:"errors,omitempty"`
+	Error     string                `json:"error"`
+}
+
+// Build สร้างเอกสารจาก Operations
+func Build() *Document {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Mini Task Manager API",
+			Version: Version,
+			Description: "Errors are application/problem+json (RFC 7807). " +
+				"Branch on the stable code field (e.g. TASK_NOT_FOUND), not on the message.",
+		},
+		Paths: map[string]map[string]*OperationDoc{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "Access token from /auth/login, or a personal access token (mtm_pat_...)",
+				},
+			},
+		},
+	}
+
+	problemRef := s.of(Problem{}, false)
+
+	for _, op := range Operations {
+		item := &OperationDoc{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Tags:        []string{op.Tag},
+			Security:    []map[string][]string{},
+			Responses:   map[string]Response{},
+		}
+		switch op.Auth {
+		case AuthBearer:
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+			if op.Scope != "" {
+				item.Description = "Personal access tokens need the " + op.Scope + " scope."
+				item.Extensions = map[string]interface{}{"x-scope": op.Scope}
+			}
+		case AuthSession:
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+			item.Description = "Requires an access token from login; personal access tokens are rejected."
+		case AuthAdmin:
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+			item.Description = "Admins only. Requires an access token from login; personal access tokens are rejected."
+		}
+		if op.Browser {
+			if item.Extensions == nil {
+				item.Extensions = map[string]interface{}{}
+			}
+			item.Extensions["x-browser-only"] = true
+		}
+
+		for _, name := range op.PathParams() {
+			item.Parameters = append(item.Parameters, Parameter{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+		for _, q := range op.Query {
+			item.Parameters = append(item.Parameters, Parameter{
+				Name: q.Name, In: "query", Required: q.Required, Description: q.Description,
+				Schema: &Schema{Type: q.Type},
+			})
+		}
+		if op.Auth != AuthNone && !op.Secret && (op.Method == http.MethodPost || op.Method == http.MethodPatch) {
+			item.Parameters = append(item.Parameters, Parameter{
+				Name: "Idempotency-Key", In: "header",
+				Description: "Unique key per logical request (e.g. a UUID). Retries with the same key and body replay the first " +
+					"response with Idempotent-Replayed: true; a different body gives 422 IDEMPOTENCY_KEY_REUSED and a retry " +
+					"while the first request is still running gives 409 IDEMPOTENCY_IN_PROGRESS",
+				Schema: &Schema{Type: "string"},
+			})
+		}
+		if op.IfMatch {
+			item.Parameters = append(item.Parameters, Parameter{
+				Name: "If-Match", In: "header",
+				Description: "ETag from a previous response; the request fails with 412 VERSION_CONFLICT if the item changed since",
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+
+		switch {
+		case op.Upload:
+			item.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"multipart/form-data": {Schema: &Schema{
+					Type:       "object",
+					Properties: &Properties{{Name: "file", Schema: &Schema{Type: "string", Format: "binary"}}},
+					Required:   []string{"file"},
+				}},
+			}}
+		case op.Request != nil:
+			item.RequestBody = &RequestBody{Required: !op.RequestOptional, Content: map[string]MediaType{
+				"application/json": {Schema: s.of(op.Request, true)},
+			}}
+		}
+
+		ok := Response{Description: http.StatusText(op.Status)}
+		switch {
+		case op.ResponseType != "":
+			ok.Content = map[string]MediaType{op.ResponseType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case op.Response != nil:
+			ok.Content = map[string]MediaType{"application/json": {Schema: s.of(op.Response, false)}}
+		}
+		if op.ETag {
+			ok.Headers = map[string]Header{"ETag": {
+				Description: "Current version, send it back as If-Match",
+				Schema:      &Schema{Type: "string"},
+			}}
+		}
+		item.Responses[strconv.Itoa(op.Status)] = ok
+		if op.IfMatch {
+			item.Responses[strconv.Itoa(http.StatusPreconditionFailed)] = Response{
+				Description: "VERSION_CONFLICT: changed by someone else (currentVersion holds the latest version)",
+				Content:     map[string]MediaType{apierror.ProblemContentType: {Schema: problemRef}},
+			}
+		}
+		item.Responses["default"] = Response{
+			Description: "error",
+			Content:     map[string]MediaType{apierror.ProblemContentType: {Schema: problemRef}},
+		}
+
+		path := op.OpenAPIPath()
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*OperationDoc{}
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = item
+	}
+
+	doc.Components.Schemas = s.components
+	return doc
+}
+
+var (
+	docOnce sync.Once
+	docJSON []byte
+	docErr  error
+)
+
+// Handler GET /openapi.json
+func Handler(c *gin.Context) {
+	docOnce.Do(func() {
+		docJSON, docErr = json.Marshal(Build())
+	})
+	if docErr != nil {
+		apierror.Abort(c, apierror.Internal("cannot build openapi document", docErr))
+		return
+	}
+	c.Data(http.StatusOK, "application/json", docJSON)
+}
+
+// Ignored route ที่ไม่อยู่ในเอกสาร (probe / metrics / ตัวเอกสารเอง)
+var Ignored = map[string]bool{
+	"GET /livez":        true,
+	"GET /readyz":       true,
+	"GET /health":       true,
+	"GET /metrics":      true,
+	"GET /openapi.json": true,
+}
+
+// Check เทียบ route ที่ลงทะเบียนกับ gin กับ Operations
+// คืน error ที่บอกทุก route ที่ขาดหายไปจากฝั่งใดฝั่งหนึ่ง (เช่นเพิ่ม route แล้วลืมใส่ในเอกสาร)
+func Check(routes gin.RoutesInfo) error {
+	registered := map[string]bool{}
+	for _, r := range routes {
+		key := r.Method + " " + r.Path
+		if !Ignored[key] {
+			registered[key] = true
+		}
+	}
+
+	documented := map[string]bool{}
+	ids := map[string]string{}
+	var problems []string
+	for _, op := range Operations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			problems = append(problems, "duplicate operation "+key)
+		}
+		documented[key] = true
+		if prev, ok := ids[op.ID]; ok {
+			problems = append(problems, fmt.Sprintf("operationId %s used by %s and %s", op.ID, prev, key))
+		}
+		ids[op.ID] = key
+		if !registered[key] {
+			problems = append(problems, "documented but not registered: "+key)
+		}
+	}
+	for key := range registered {
+		if !documented[key] {
+			problems = append(problems, "registered but not documented: "+key)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("openapi: routes and spec differ:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// SecretRoutes route ที่ตั้ง Secret ไว้ ("POST /api/v1/me/tokens") ใช้เป็นรายการยกเว้นของ idempotency middleware
+func SecretRoutes() []string {
+	var routes []string
+	for _, op := range Operations {
+		if op.Secret {
+			routes = append(routes, op.Method+" "+op.Path)
+		}
+	}
+	return routes
+}
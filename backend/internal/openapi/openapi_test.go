@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ชื่อ field ใน JSON ที่ถือเป็นความลับ
+var secretFields = map[string]bool{
+	"accessToken":    true,
+	"challengeToken": true,
+	"resetToken":     true,
+	"token":          true,
+	"secret":         true,
+	"recoveryCodes":  true,
+}
+
+// hasSecretField หา field ที่เป็นความลับใน type (รวม struct ที่ฝังอยู่)
+func hasSecretField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if secretFields[name] || (f.Anonymous && hasSecretField(f.Type)) {
+			return true
+		}
+	}
+	return false
+}
+
+// response ที่มี token / secret ต้องตั้ง Secret ไม่อย่างนั้นจะถูกเก็บไว้ใน idempotencyKeys
+func TestSecretResponsesAreMarked(t *testing.T) {
+	for _, op := range Operations {
+		if op.Response == nil || op.Secret {
+			continue
+		}
+		if hasSecretField(reflect.TypeOf(op.Response)) {
+			t.Errorf("%s %s returns %T with a credential but is not marked Secret", op.Method, op.Path, op.Response)
+		}
+	}
+}
+
+func TestSecretRoutes(t *testing.T) {
+	routes := map[string]bool{}
+	for _, r := range SecretRoutes() {
+		routes[r] = true
+	}
+	for _, want := range []string{
+		"POST /api/v1/me/tokens",
+		"POST /api/v1/projects/:id/webhooks",
+		"POST /api/v1/auth/2fa/confirm",
+		"POST /api/v1/admin/users/:id/force-password-reset",
+	} {
+		if !routes[want] {
+			t.Errorf("SecretRoutes() is missing %s", want)
+		}
+	}
+}
+
+func TestFieldDescriptionFromDocTag(t *testing.T) {
+	schema := Build().Components.Schemas["CreateTokenRequest"]
+	if schema == nil || schema.Properties == nil {
+		t.Fatal("CreateTokenRequest schema missing")
+	}
+	for _, p := range *schema.Properties {
+		if p.Name == "expiresInDays" {
+			if !strings.Contains(p.Schema.Description, "default 30") {
+				t.Errorf("expiresInDays description = %q", p.Schema.Description)
+			}
+			return
+		}
+	}
+	t.Error("expiresInDays not in schema")
+}
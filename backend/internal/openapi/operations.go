@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"net/http"
+
+	"mini-taskmgr-backend/internal/handlers"
+	"mini-taskmgr-backend/internal/middleware"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/utils"
+)
+
+const v1 = "/api/v1"
+
+var listLimit = Param{Name: "limit", Type: "integer", Description: "1-200 (default 50)"}
+
+// Operations ทุก route ของ API (เพิ่ม route ใน main แล้วต้องเพิ่มที่นี่ด้วย ไม่งั้น server จะไม่ยอม start)
+var Operations = []Operation{
+	{Method: http.MethodGet, Path: "/.well-known/jwks.json", ID: "GetJWKS", Tag: "auth",
+		Summary: "Public keys for verifying access tokens",
+		Status:  http.StatusOK, Response: utils.JWKSet{}},
+
+	// ---- auth ----
+	{Method: http.MethodPost, Path: v1 + "/auth/register", ID: "Register", Tag: "auth",
+		Summary: "Create an account",
+		Request: handlers.RegisterRequest{}, Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/auth/login", ID: "Login", Tag: "auth",
+		Summary: "Log in with email and password (may require a second factor)",
+		Request: handlers.LoginRequest{}, Status: http.StatusOK, Response: handlers.LoginResponse{}, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/auth/login/2fa", ID: "LoginTwoFactor", Tag: "auth",
+		Summary: "Finish a login with a TOTP or recovery code",
+		Request: handlers.LoginTwoFactorRequest{}, Status: http.StatusOK, Response: handlers.LoginResponse{}, Secret: true},
+	{Method: http.MethodGet, Path: v1 + "/auth/oidc/providers", ID: "ListOIDCProviders", Tag: "auth",
+		Summary: "Configured single sign-on providers",
+		Status:  http.StatusOK, Response: handlers.OIDCProvidersResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/auth/oidc/:provider/login", ID: "OIDCLogin", Tag: "auth",
+		Summary: "Redirect to the identity provider",
+		Status:  http.StatusFound, Browser: true},
+	{Method: http.MethodGet, Path: v1 + "/auth/oidc/:provider/callback", ID: "OIDCCallback", Tag: "auth",
+		Summary: "Identity provider callback (redirects to the frontend when configured)",
+		Query: []Param{
+			{Name: "code", Type: "string"},
+			{Name: "state", Type: "string"},
+			{Name: "error", Type: "string"},
+		},
+		Status: http.StatusOK, Response: handlers.LoginResponse{}, Browser: true, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/auth/forgot-password", ID: "ForgotPassword", Tag: "auth",
+		Summary: "Request a password reset link",
+		Request: handlers.ForgotPasswordRequest{}, Status: http.StatusOK, Response: handlers.ForgotPasswordResponse{}, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/auth/reset-password", ID: "ResetPassword", Tag: "auth",
+		Summary: "Set a new password with a reset token",
+		Request: handlers.ResetPasswordRequest{}, Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/auth/change-password", ID: "ChangePassword", Tag: "auth",
+		Summary: "Change the current user's password", Auth: AuthSession,
+		Request: handlers.ChangePasswordRequest{}, Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/auth/2fa/enroll", ID: "EnrollTwoFactor", Tag: "auth",
+		Summary: "Start two-factor enrollment", Auth: AuthSession,
+		Status: http.StatusOK, Response: handlers.EnrollTwoFactorResponse{}, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/auth/2fa/confirm", ID: "ConfirmTwoFactor", Tag: "auth",
+		Summary: "Confirm two-factor enrollment and get recovery codes", Auth: AuthSession,
+		Request: handlers.ConfirmTwoFactorRequest{}, Status: http.StatusOK, Response: handlers.ConfirmTwoFactorResponse{}, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/auth/2fa/disable", ID: "DisableTwoFactor", Tag: "auth",
+		Summary: "Turn off two-factor authentication", Auth: AuthSession,
+		Request: handlers.DisableTwoFactorRequest{}, Status: http.StatusOK, Response: handlers.MessageResponse{}},
+
+	// ---- users ----
+	{Method: http.MethodGet, Path: v1 + "/me", ID: "Me", Tag: "users",
+		Summary: "Current user", Auth: AuthBearer, Scope: middleware.ScopeProfileRead,
+		Status: http.StatusOK, Response: handlers.MeResponse{}},
+	{Method: http.MethodPatch, Path: v1 + "/users/:id", ID: "UpdateProfile", Tag: "users",
+		Summary: "Update the current user's profile", Auth: AuthBearer, Scope: middleware.ScopeProfileWrite,
+		Request: handlers.UpdateProfileRequest{}, Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodDelete, Path: v1 + "/users/:id", ID: "DeleteAccount", Tag: "users",
+		Summary: "Delete or anonymize the current user's account", Auth: AuthSession,
+		Query:   []Param{{Name: "mode", Type: "string", Description: "delete (default) or anonymize"}},
+		Request: handlers.DeleteAccountRequest{}, RequestOptional: true,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/me/export", ID: "ExportMyData", Tag: "users",
+		Summary: "Download all personal data as a ZIP", Auth: AuthSession,
+		Status: http.StatusOK, ResponseType: "application/zip"},
+
+	// ---- projects ----
+	{Method: http.MethodGet, Path: v1 + "/projects", ID: "ListProjects", Tag: "projects",
+		Summary: "Projects the user owns or belongs to", Auth: AuthBearer, Scope: middleware.ScopeProjectsRead,
+		Status: http.StatusOK, Response: []handlers.ProjectResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/projects", ID: "CreateProject", Tag: "projects",
+		Summary: "Create a project", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.CreateProjectRequest{}, Status: http.StatusCreated, Response: handlers.ProjectResponse{}, ETag: true},
+	{Method: http.MethodGet, Path: v1 + "/projects/:id", ID: "GetProjectDetail", Tag: "projects",
+		Summary: "Project with its board, columns and tasks", Auth: AuthBearer, Scope: middleware.ScopeProjectsRead,
+		Status: http.StatusOK, Response: handlers.ProjectDetailResponse{}, ETag: true},
+	{Method: http.MethodPatch, Path: v1 + "/projects/:id", ID: "UpdateProject", Tag: "projects",
+		Summary: "Rename a project", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.UpdateProjectRequest{}, Status: http.StatusOK, Response: handlers.VersionResponse{},
+		IfMatch: true, ETag: true},
+	{Method: http.MethodDelete, Path: v1 + "/projects/:id", ID: "DeleteProject", Tag: "projects",
+		Summary: "Delete a project and everything in it", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: handlers.OKResponse{}, IfMatch: true},
+	{Method: http.MethodGet, Path: v1 + "/projects/:id/analytics", ID: "GetProjectAnalytics", Tag: "projects",
+		Summary: "Cumulative flow, cycle time, throughput and WIP breaches", Auth: AuthBearer, Scope: middleware.ScopeProjectsRead,
+		Query: []Param{
+			{Name: "from", Type: "string", Description: "YYYY-MM-DD (default: 30 days ago)"},
+			{Name: "to", Type: "string", Description: "YYYY-MM-DD (default: today)"},
+		},
+		Status: http.StatusOK, Response: handlers.ProjectAnalyticsResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/projects/:id/members", ID: "InviteProjectMember", Tag: "projects",
+		Summary: "Add a user to a project by email", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.InviteMemberRequest{}, Status: http.StatusCreated, Response: models.ProjectMember{}},
+	{Method: http.MethodPost, Path: v1 + "/projects/:id/leave", ID: "LeaveProject", Tag: "projects",
+		Summary: "Leave a project", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/projects/:id/transfer", ID: "TransferProject", Tag: "projects",
+		Summary: "Transfer ownership to another member", Auth: AuthSession,
+		Request: handlers.TransferProjectRequest{}, Status: http.StatusOK, Response: handlers.TransferProjectResponse{}},
+
+	// ---- webhooks ----
+	{Method: http.MethodGet, Path: v1 + "/projects/:id/webhooks", ID: "ListWebhooks", Tag: "webhooks",
+		Summary: "Project webhooks", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: []models.Webhook{}},
+	{Method: http.MethodPost, Path: v1 + "/projects/:id/webhooks", ID: "CreateWebhook", Tag: "webhooks",
+		Summary: "Create a webhook (the secret is only returned here)", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.CreateWebhookRequest{}, Status: http.StatusCreated, Response: handlers.CreateWebhookResponse{}, Secret: true},
+	{Method: http.MethodPatch, Path: v1 + "/projects/:id/webhooks/:hookId", ID: "UpdateWebhook", Tag: "webhooks",
+		Summary: "Update a webhook", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.UpdateWebhookRequest{}, Status: http.StatusOK, Response: models.Webhook{}},
+	{Method: http.MethodDelete, Path: v1 + "/projects/:id/webhooks/:hookId", ID: "DeleteWebhook", Tag: "webhooks",
+		Summary: "Delete a webhook", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/projects/:id/webhooks/:hookId/deliveries", ID: "ListWebhookDeliveries", Tag: "webhooks",
+		Summary: "Recent deliveries of a webhook", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: []models.WebhookDelivery{}},
+	{Method: http.MethodPost, Path: v1 + "/projects/:id/webhooks/:hookId/deliveries/:deliveryId/redeliver", ID: "RedeliverWebhook", Tag: "webhooks",
+		Summary: "Queue a delivery again", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusAccepted, Response: models.WebhookDelivery{}},
+
+	// ---- columns ----
+	{Method: http.MethodPost, Path: v1 + "/columns", ID: "CreateColumn", Tag: "columns",
+		Summary: "Add a column to a board", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.CreateColumnRequest{}, Status: http.StatusOK, Response: models.Column{}, ETag: true},
+	{Method: http.MethodPatch, Path: v1 + "/columns/:id", ID: "UpdateColumn", Tag: "columns",
+		Summary: "Rename a column or change its category", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Request: handlers.UpdateColumnRequest{}, Status: http.StatusOK, Response: handlers.VersionResponse{},
+		IfMatch: true, ETag: true},
+	{Method: http.MethodDelete, Path: v1 + "/columns/:id", ID: "DeleteColumn", Tag: "columns",
+		Summary: "Delete a column and its tasks", Auth: AuthBearer, Scope: middleware.ScopeProjectsWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}, IfMatch: true},
+
+	// ---- tasks ----
+	{Method: http.MethodPost, Path: v1 + "/tasks", ID: "CreateTask", Tag: "tasks",
+		Summary: "Create a task", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.CreateTaskRequest{}, Status: http.StatusCreated, Response: handlers.CreateTaskResponse{}, ETag: true},
+	{Method: http.MethodPatch, Path: v1 + "/tasks/:id", ID: "UpdateTask", Tag: "tasks",
+		Summary: "Update a task", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.UpdateTaskRequest{}, Status: http.StatusOK, Response: handlers.VersionResponse{},
+		IfMatch: true, ETag: true},
+	{Method: http.MethodDelete, Path: v1 + "/tasks/:id", ID: "DeleteTask", Tag: "tasks",
+		Summary: "Delete a task", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}, IfMatch: true},
+	{Method: http.MethodPatch, Path: v1 + "/tasks/move", ID: "MoveTask", Tag: "tasks",
+		Summary: "Move a task to another column", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.MoveTaskRequest{}, Status: http.StatusOK, Response: handlers.OKResponse{},
+		IfMatch: true, ETag: true},
+	{Method: http.MethodPost, Path: v1 + "/tasks/bulk", ID: "BulkTasks", Tag: "tasks",
+		Summary: "Move, update, assign, label or delete many tasks (atomic or best-effort)", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.BulkTaskRequest{}, Status: http.StatusOK, Response: handlers.BulkTaskResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/tasks/:id/dependencies", ID: "AddTaskDependency", Tag: "tasks",
+		Summary: "Mark the task as blocked by another task", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.AddDependencyRequest{}, Status: http.StatusCreated, Response: models.TaskDependency{}},
+	{Method: http.MethodDelete, Path: v1 + "/tasks/:id/dependencies/:blockerId", ID: "RemoveTaskDependency", Tag: "tasks",
+		Summary: "Remove a dependency", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/tasks/:id/comments", ID: "ListComments", Tag: "tasks",
+		Summary: "Comments on a task", Auth: AuthBearer, Scope: middleware.ScopeTasksRead,
+		Status: http.StatusOK, Response: []models.Comment{}},
+	{Method: http.MethodPost, Path: v1 + "/tasks/:id/comments", ID: "CreateComment", Tag: "tasks",
+		Summary: "Comment on a task", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Request: handlers.CreateCommentRequest{}, Status: http.StatusCreated, Response: models.Comment{}},
+
+	// ---- attachments ----
+	{Method: http.MethodGet, Path: v1 + "/tasks/:id/attachments", ID: "ListAttachments", Tag: "attachments",
+		Summary: "Files attached to a task", Auth: AuthBearer, Scope: middleware.ScopeTasksRead,
+		Status: http.StatusOK, Response: []models.Attachment{}},
+	{Method: http.MethodPost, Path: v1 + "/tasks/:id/attachments", ID: "UploadAttachment", Tag: "attachments",
+		Summary: "Upload a file", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Upload: true, Status: http.StatusCreated, Response: models.Attachment{}},
+	{Method: http.MethodGet, Path: v1 + "/attachments/:id/link", ID: "GetAttachmentLink", Tag: "attachments",
+		Summary: "Short-lived download link", Auth: AuthBearer, Scope: middleware.ScopeTasksRead,
+		Status: http.StatusOK, Response: handlers.AttachmentLinkResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/attachments/download", ID: "DownloadAttachment", Tag: "attachments",
+		Summary: "Download a file with a link token",
+		Query:   []Param{{Name: "token", Type: "string", Required: true}},
+		Status:  http.StatusOK, ResponseType: "application/octet-stream"},
+	{Method: http.MethodDelete, Path: v1 + "/attachments/:id", ID: "DeleteAttachment", Tag: "attachments",
+		Summary: "Delete a file", Auth: AuthBearer, Scope: middleware.ScopeTasksWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+
+	// ---- notifications ----
+	{Method: http.MethodGet, Path: v1 + "/notifications", ID: "ListNotifications", Tag: "notifications",
+		Summary: "In-app notifications, newest first", Auth: AuthBearer, Scope: middleware.ScopeNotificationsRead,
+		Query: []Param{
+			{Name: "unread", Type: "boolean", Description: "only unread notifications"},
+			listLimit,
+		},
+		Status: http.StatusOK, Response: []models.Notification{}},
+	{Method: http.MethodGet, Path: v1 + "/notifications/unread-count", ID: "UnreadNotificationCount", Tag: "notifications",
+		Summary: "Number of unread notifications", Auth: AuthBearer, Scope: middleware.ScopeNotificationsRead,
+		Status: http.StatusOK, Response: handlers.UnreadCountResponse{}},
+	{Method: http.MethodPatch, Path: v1 + "/notifications/:id/read", ID: "MarkNotificationRead", Tag: "notifications",
+		Summary: "Mark a notification as read", Auth: AuthBearer, Scope: middleware.ScopeNotificationsWrite,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/notifications/read-all", ID: "MarkAllNotificationsRead", Tag: "notifications",
+		Summary: "Mark every notification as read", Auth: AuthBearer, Scope: middleware.ScopeNotificationsWrite,
+		Status: http.StatusOK, Response: handlers.MarkAllReadResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/me/notification-preferences", ID: "GetNotificationPreferences", Tag: "notifications",
+		Summary: "Channels for each notification type", Auth: AuthBearer, Scope: middleware.ScopeNotificationsRead,
+		Status: http.StatusOK, Response: map[models.NotificationType]models.NotificationChannels{}},
+	{Method: http.MethodPut, Path: v1 + "/me/notification-preferences", ID: "UpdateNotificationPreferences", Tag: "notifications",
+		Summary: "Change channels for some notification types", Auth: AuthBearer, Scope: middleware.ScopeNotificationsWrite,
+		Request: map[models.NotificationType]models.NotificationChannels{},
+		Status:  http.StatusOK, Response: map[models.NotificationType]models.NotificationChannels{}},
+
+	// ---- personal access tokens ----
+	{Method: http.MethodGet, Path: v1 + "/me/tokens", ID: "ListPersonalAccessTokens", Tag: "tokens",
+		Summary: "Personal access tokens of the current user", Auth: AuthSession,
+		Status: http.StatusOK, Response: []models.PersonalAccessToken{}},
+	{Method: http.MethodPost, Path: v1 + "/me/tokens", ID: "CreatePersonalAccessToken", Tag: "tokens",
+		Summary: "Create a token (the token is only returned here)", Auth: AuthSession,
+		Request: handlers.CreateTokenRequest{}, Status: http.StatusCreated, Response: handlers.CreateTokenResponse{}, Secret: true},
+	{Method: http.MethodDelete, Path: v1 + "/me/tokens/:id", ID: "RevokePersonalAccessToken", Tag: "tokens",
+		Summary: "Revoke a token", Auth: AuthSession,
+		Status: http.StatusOK, Response: handlers.MessageResponse{}},
+
+	// ---- admin ----
+	{Method: http.MethodGet, Path: v1 + "/admin/users", ID: "AdminListUsers", Tag: "admin",
+		Summary: "Search users", Auth: AuthAdmin,
+		Query: []Param{
+			{Name: "q", Type: "string", Description: "matches name or email"},
+			{Name: "role", Type: "string"},
+			{Name: "disabled", Type: "boolean"},
+			listLimit,
+			{Name: "skip", Type: "integer"},
+		},
+		Status: http.StatusOK, Response: handlers.AdminUserListResponse{}},
+	{Method: http.MethodPatch, Path: v1 + "/admin/users/:id/role", ID: "AdminUpdateUserRole", Tag: "admin",
+		Summary: "Change a user's role", Auth: AuthAdmin,
+		Request: handlers.AdminUpdateRoleRequest{}, Status: http.StatusOK, Response: handlers.AdminUserResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/admin/users/:id/disable", ID: "AdminDisableUser", Tag: "admin",
+		Summary: "Disable an account and revoke its tokens", Auth: AuthAdmin,
+		Status: http.StatusOK, Response: handlers.AdminUserResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/admin/users/:id/enable", ID: "AdminEnableUser", Tag: "admin",
+		Summary: "Enable an account", Auth: AuthAdmin,
+		Status: http.StatusOK, Response: handlers.AdminUserResponse{}},
+	{Method: http.MethodPost, Path: v1 + "/admin/users/:id/force-password-reset", ID: "AdminForcePasswordReset", Tag: "admin",
+		Summary: "Require a password reset on next login", Auth: AuthAdmin,
+		Status: http.StatusOK, Response: handlers.AdminPasswordResetResponse{}, Secret: true},
+	{Method: http.MethodPost, Path: v1 + "/admin/projects/:id/transfer", ID: "AdminTransferProject", Tag: "admin",
+		Summary: "Transfer a project to any user", Auth: AuthAdmin,
+		Request: handlers.TransferProjectRequest{}, Status: http.StatusOK, Response: handlers.TransferProjectResponse{}},
+	{Method: http.MethodGet, Path: v1 + "/admin/stats", ID: "AdminStats", Tag: "admin",
+		Summary: "User, project and task counts", Auth: AuthAdmin,
+		Status: http.StatusOK, Response: handlers.AdminStatsResponse{}},
+}
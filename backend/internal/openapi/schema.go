@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema JSON Schema แบบที่ OpenAPI 3.0 ใช้ (เฉพาะส่วนที่ API นี้ต้องใช้)
+type Schema struct {
+	Ref                  string      `json:"$ref,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Format               string      `json:"format,omitempty"`
+	Pattern              string      `json:"pattern,omitempty"`
+	Nullable             bool        `json:"nullable,omitempty"`
+	AllOf                []*Schema   `json:"allOf,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	Properties           *Properties `json:"properties,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	AdditionalProperties *Schema     `json:"additionalProperties,omitempty"`
+	Description          string      `json:"description,omitempty"`
+}
+
+// Property field หนึ่งตัวของ object
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties field ของ object ตามลำดับใน struct (map ของ Go จะเรียงใหม่ตามตัวอักษร)
+type Properties []Property
+
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(prop.Name)
+		b.Write(name)
+		b.WriteByte(':')
+		s, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(s)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// Component ชื่อ schema ที่ $ref ชี้ไป
+func Component(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schemas สร้าง schema จาก type ของ Go ด้วย reflection ตามกติกาเดียวกับ encoding/json
+// struct ที่มีชื่อจะกลายเป็น component (ชื่อเดียวกับ type) แล้วอ้างด้วย $ref
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+// of schema ของ type ของค่า v (nil = ไม่มี schema)
+// request = true จะถือว่า field บังคับตาม binding:"required" แทน omitempty
+func (s *schemas) of(v interface{}, request bool) *Schema {
+	if v == nil {
+		return nil
+	}
+	return s.schema(reflect.TypeOf(v), request)
+}
+
+func (s *schemas) schema(t reflect.Type, request bool) *Schema {
+	switch t {
+	case timeType, dateTimeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem := s.schema(t.Elem(), request)
+		if elem.Ref != "" {
+			// $ref ใส่ keyword อื่นข้าง ๆ ไม่ได้ใน 3.0
+			return &Schema{AllOf: []*Schema{elem}, Nullable: true}
+		}
+		cp := *elem
+		cp.Nullable = true
+		return &cp
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem(), request)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, request)
+		}
+		name := t.Name()
+		if prev, ok := s.types[name]; ok {
+			if prev != t {
+				panic("openapi: two types named " + name + ": " + prev.PkgPath() + " and " + t.PkgPath())
+			}
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+		s.types[name] = t
+		s.components[name] = s.object(t, request)
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	// interface{} และอื่น ๆ: ค่าอะไรก็ได้
+	return &Schema{}
+}
+
+func (s *schemas) object(t reflect.Type, request bool) *Schema {
+	obj := &Schema{Type: "object", Properties: &Properties{}}
+	s.fields(obj, t, request)
+	if len(*obj.Properties) == 0 {
+		obj.Properties = nil
+	}
+	return obj
+}
+
+func (s *schemas) fields(obj *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		// embedded struct ที่ไม่มีชื่อใน tag: field ของมันอยู่ระดับเดียวกับ struct แม่
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			s.fields(obj, f.Type, request)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := s.schema(f.Type, request)
+		// doc:"..." คำอธิบายของ field (ค่า default / ช่วงที่รับ)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			fs.Description = doc
+		}
+		binding := strings.Split(f.Tag.Get("binding"), ",")
+		for _, rule := range binding {
+			switch rule {
+			case "email":
+				fs.Format = "email"
+			case "url", "http_url":
+				fs.Format = "uri"
+			}
+		}
+		*obj.Properties = append(*obj.Properties, Property{Name: name, Schema: fs})
+
+		required := !strings.Contains(opts, "omitempty")
+		if request {
+			required = containsRule(binding, "required")
+		}
+		if required {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// names ชื่อ component เรียงตามตัวอักษร
+func (s *schemas) names() []string {
+	names := make([]string, 0, len(s.components))
+	for name := range s.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore เก็บ bucket ไว้ใน MongoDB ให้ทุก replica ใช้ limit ร่วมกัน
+// การเติม/หยิบ token ทำใน update pipeline ครั้งเดียว จึงเป็น atomic ต่อ key
+type MongoStore struct {
+	coll *mongo.Collection
+}
+
+// NewMongoStore สร้าง store ที่ใช้ collection rateLimits ใน database ที่ให้มา
+func NewMongoStore(database *mongo.Database) *MongoStore {
+	return &MongoStore{coll: database.Collection("rateLimits")}
+}
+
+// EnsureIndexes สร้าง TTL index ให้ bucket ที่ไม่ได้ใช้ถูกลบเอง
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *MongoStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := time.Now()
+	burst := float64(limit.Requests)
+	rate := limit.ratePerSecond()
+
+	pipeline := mongo.Pipeline{
+		// เติม token ตามเวลาที่ผ่านไป (ไม่เกิน burst)
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{
+				burst,
+				bson.M{"$add": bson.A{
+					bson.M{"$ifNull": bson.A{"$tokens", burst}},
+					bson.M{"$multiply": bson.A{
+						bson.M{"$divide": bson.A{
+							bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedAt", now}}}},
+							1000,
+						}},
+						rate,
+					}},
+				}},
+			}},
+			"updatedAt": now,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+		}}},
+		// หยิบ token ถ้ามีพอ
+		{{Key: "$set", Value: bson.M{
+			"tokens":    bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"expiresAt": now.Add(limit.Per),
+		}}},
+	}
+
+	var doc struct {
+		Tokens  float64 `bson:"tokens"`
+		Allowed bool    `bson:"allowed"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := s.coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&doc)
+	if mongo.IsDuplicateKeyError(err) {
+		// request แรกของ key นี้มาพร้อมกันหลายอัน upsert ชนกัน ลองอีกครั้งจะเจอ document แล้ว
+		err = s.coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&doc)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if doc.Allowed {
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - doc.Tokens) / rate * float64(time.Second))
+	return false, wait, nil
+}
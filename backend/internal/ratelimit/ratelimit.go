@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mini-taskmgr-backend/internal/apierror"
+)
+
+// Limit อนุญาต Requests ครั้งต่อช่วงเวลา Per (token bucket: burst = Requests)
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ratePerSecond อัตราการเติม token ต่อวินาที
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// Store เก็บ token bucket ของแต่ละ key
+type Store interface {
+	// Take หยิบ token 1 อันจาก bucket ของ key
+	// ถ้าไม่เหลือ token จะคืน allowed = false พร้อมเวลาที่ต้องรอ
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Rule กำหนด limit ของ route group หนึ่ง
+type Rule struct {
+	// Name ใช้แยก bucket ของแต่ละ rule ออกจากกัน เช่น "login"
+	Name string
+	// PerIP limit ต่อ IP ของ client
+	PerIP Limit
+	// PerAccount limit ต่อบัญชี ใช้คู่กับ AccountKey
+	PerAccount Limit
+	// AccountKey ดึง key ของบัญชีจาก request (คืน "" ถ้าไม่มี)
+	AccountKey func(c *gin.Context) string
+}
+
+// Middleware คืน gin middleware ที่จำกัดจำนวน request ตาม rule
+// request ที่เกิน limit จะได้ 429 พร้อม header Retry-After
+func Middleware(store Store, rule Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rule.PerIP.enabled() {
+			if !take(c, store, rule.Name+":ip:"+c.ClientIP(), rule.PerIP) {
+				return
+			}
+		}
+		if rule.PerAccount.enabled() && rule.AccountKey != nil {
+			if account := rule.AccountKey(c); account != "" {
+				if !take(c, store, rule.Name+":account:"+account, rule.PerAccount) {
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func take(c *gin.Context, store Store, key string, limit Limit) bool {
+	allowed, retryAfter, err := store.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		// ถ้า store มีปัญหา ให้ request ผ่านไปก่อน ดีกว่าล็อกทุกคนออกจากระบบ
+		slog.ErrorContext(c.Request.Context(), "RATE_LIMIT: store error", "err", err)
+		return true
+	}
+	if allowed {
+		return true
+	}
+	TooManyRequests(c, retryAfter, apierror.ErrRateLimited)
+	return false
+}
+
+// TooManyRequests ตอบ e (429) พร้อม Retry-After (วินาที ปัดขึ้น)
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, e *apierror.Error) {
+	secs := int(math.Ceil(retryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	apierror.Abort(c, e.With("retryAfter", secs))
+}
+
+// JSONField คืน AccountKey ที่อ่าน field จาก JSON body (เช่น "email")
+// body จะถูกใส่กลับคืน ให้ handler อ่านซ้ำได้ตามปกติ
+func JSONField(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			return ""
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var m map[string]interface{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			return ""
+		}
+		v, _ := m[field].(string)
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+	"mini-taskmgr-backend/internal/notify"
+)
+
+// DueReminders สร้าง job ที่หา task ใกล้ถึงกำหนด (ภายใน window) หรือเลยกำหนดแล้ว
+// แล้วแจ้งเตือน assignee ทุกคน แต่ละชนิดแจ้งครั้งเดียวต่อ dueDate (บันทึกไว้ใน remindedAt ของ task)
+func DueReminders(interval, window time.Duration) Job {
+	return Job{
+		Name:     "due-reminders",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return sendDueReminders(ctx, time.Now(), window)
+		},
+	}
+}
+
+func sendDueReminders(ctx context.Context, now time.Time, window time.Duration) error {
+	if err := remindTasks(ctx, models.NotificationTaskOverdue, bson.M{"$lt": now}, now); err != nil {
+		return err
+	}
+	return remindTasks(ctx, models.NotificationTaskDueSoon, bson.M{"$gte": now, "$lte": now.Add(window)}, now)
+}
+
+// reminderFilter task ที่ยังไม่เสร็จ มี assignee และยังไม่เคยแจ้ง kind นี้สำหรับ dueDate ปัจจุบัน
+func reminderFilter(kind models.NotificationType, due bson.M) bson.M {
+	return bson.M{
+		"dueDate":                    due,
+		"completedAt":                bson.M{"$exists": false},
+		"assignees.0":                bson.M{"$exists": true},
+		"remindedAt." + string(kind): bson.M{"$exists": false},
+	}
+}
+
+func remindTasks(ctx context.Context, kind models.NotificationType, due bson.M, now time.Time) error {
+	tasks := db.Database.Collection("tasks")
+	cur, err := tasks.Find(ctx, reminderFilter(kind, due))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var task models.Task
+		if err := cur.Decode(&task); err != nil {
+			return err
+		}
+		if task.DueDate == nil {
+			continue
+		}
+		dueAt := task.DueDate.Time()
+
+		title := fmt.Sprintf("\"%s\" is due %s", task.Title, dueAt.UTC().Format("2006-01-02 15:04 UTC"))
+		if kind == models.NotificationTaskOverdue {
+			title = fmt.Sprintf("\"%s\" is overdue", task.Title)
+		}
+
+		// DedupeKey กันส่งซ้ำถ้า job ล้มก่อนบันทึก remindedAt
+		taskID := task.ID
+		for _, userID := range task.Assignees {
+			_, err := notify.Send(ctx, models.Notification{
+				UserID:    userID,
+				Type:      kind,
+				Title:     title,
+				TaskID:    &taskID,
+				DedupeKey: fmt.Sprintf("%s:%s:%s:%d", kind, task.ID.Hex(), userID.Hex(), dueAt.Unix()),
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// ผูกกับ dueDate ที่ส่งไป ถ้ามีคนแก้ dueDate ระหว่างนี้ จะไม่บันทึกทับ
+		if _, err := tasks.UpdateOne(ctx,
+			bson.M{"_id": task.ID, "dueDate": *task.DueDate},
+			bson.M{"$set": bson.M{"remindedAt." + string(kind): now}},
+		); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
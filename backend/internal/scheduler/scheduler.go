@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/tracing"
+)
+
+// Job คืองานที่รันเป็นรอบ ๆ ทุก Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler รัน job เป็น background goroutine
+// แต่ละรอบต้องถือ lease ใน collection schedulerLeases ก่อน
+// ทำให้ถ้ามีหลาย replica จะมีแค่ตัวเดียวที่รัน job นั้นในแต่ละช่วงเวลา
+type Scheduler struct {
+	leases *mongo.Collection
+	owner  string
+	jobs   []Job
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	running bool
+	status  map[string]*JobStatus
+}
+
+// JobStatus สถานะล่าสุดของ job บน instance นี้ (ใช้ตอบ /readyz)
+type JobStatus struct {
+	Name     string `json:"name"`
+	Interval string `json:"interval"`
+	// Leader instance นี้ถือ lease ในรอบล่าสุด (replica อื่นจะเป็น false)
+	Leader        bool       `json:"leader"`
+	Running       bool       `json:"running"`
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	// Failing รอบล่าสุดล้ม (รายละเอียดอยู่ใน log ไม่ส่งออกไปกับ /readyz)
+	Failing bool `json:"failing"`
+}
+
+// New สร้าง scheduler ที่เก็บ lease ไว้ใน database ที่ให้มา
+func New(database *mongo.Database) *Scheduler {
+	return &Scheduler{
+		leases: database.Collection("schedulerLeases"),
+		owner:  instanceID(),
+		status: map[string]*JobStatus{},
+	}
+}
+
+// Add เพิ่ม job (ต้องเรียกก่อน Start)
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+	s.status[job.Name] = &JobStatus{Name: job.Name, Interval: job.Interval.String()}
+}
+
+// Start เริ่มรันทุก job จนกว่า ctx จะถูก cancel หรือเรียก Stop
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.setRunning(true)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop หยุดทุก job รอให้รอบที่กำลังรันจบ แล้วคืน lease ให้ replica อื่นรับต่อได้ทันที
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.setRunning(false)
+	s.cancel()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, job := range s.jobs {
+		s.release(ctx, job.Name)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	ok, err := s.acquire(ctx, job.Name, 2*job.Interval)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "SCHEDULER: lease error", "job", job.Name, "err", err)
+			s.update(job.Name, func(st *JobStatus) { st.Failing = true })
+		}
+		return
+	}
+	if !ok {
+		s.update(job.Name, func(st *JobStatus) { st.Leader = false })
+		return
+	}
+
+	started := time.Now()
+	s.update(job.Name, func(st *JobStatus) {
+		st.Leader = true
+		st.Running = true
+		st.LastRunAt = &started
+	})
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Interval)
+	defer cancel()
+	runCtx, span := tracing.Start(runCtx, "job "+job.Name, tracing.KindInternal)
+	defer span.End()
+	err = job.Run(runCtx)
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(runCtx, "SCHEDULER: job failed", "job", job.Name, "err", err)
+		span.SetError(err.Error())
+	}
+
+	finished := time.Now()
+	s.update(job.Name, func(st *JobStatus) {
+		st.Running = false
+		if err != nil {
+			st.Failing = true
+			return
+		}
+		st.Failing = false
+		st.LastSuccessAt = &finished
+	})
+}
+
+// Status คืนว่า scheduler กำลังทำงานอยู่ไหม พร้อมสถานะของแต่ละ job
+func (s *Scheduler) Status() (running bool, jobs []JobStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs = make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *s.status[job.Name])
+	}
+	return s.running, jobs
+}
+
+func (s *Scheduler) setRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) update(name string, fn func(*JobStatus)) {
+	s.mu.Lock()
+	fn(s.status[name])
+	s.mu.Unlock()
+}
+
+// acquire จอง (หรือต่ออายุ) lease ของ job
+// ได้ lease เมื่อยังไม่มีใครถือ, lease หมดอายุแล้ว หรือเราถืออยู่เอง
+func (s *Scheduler) acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"owner": s.owner},
+			{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"owner":     s.owner,
+		"expiresAt": now.Add(ttl),
+		"renewedAt": now,
+	}}
+	_, err := s.leases.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// มี replica อื่นถือ lease อยู่ (upsert ชนกับ _id เดิม)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Scheduler) release(ctx context.Context, name string) {
+	_, err := s.leases.UpdateOne(ctx,
+		bson.M{"_id": name, "owner": s.owner},
+		bson.M{"$set": bson.M{"expiresAt": time.Now()}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "SCHEDULER: release lease error", "job", name, "err", err)
+	}
+}
+
+// instanceID ใช้แยกแต่ละ replica ออกจากกัน (hostname + ค่าสุ่ม)
+func instanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
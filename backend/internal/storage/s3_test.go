@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// s3Stub bucket ในหน่วยความจำแบบ MinIO (path-style) ที่ตรวจ SigV4 ทุก request
+type s3Stub struct {
+	t                              *testing.T
+	bucket, region, access, secret string
+
+	mu      sync.Mutex
+	objects map[string]stubObject
+}
+
+type stubObject struct {
+	body        []byte
+	contentType string
+}
+
+func newS3Stub(t *testing.T) (*s3Stub, *httptest.Server) {
+	stub := &s3Stub{t: t, bucket: "attachments", region: "ap-southeast-1", access: "AKIDEXAMPLE", secret: "stub-secret", objects: map[string]stubObject{}}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.validSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+	prefix := "/" + s.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if int64(len(body)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = stubObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.body)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// validSignature คำนวณ SigV4 ฝั่ง server จาก request ที่ได้รับจริง (path แบบที่ส่งมาบนสาย)
+func (s *s3Stub) validSignature(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != 16 {
+		return false
+	}
+	scope := amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+	prefix := "AWS4-HMAC-SHA256 Credential=" + s.access + "/" + scope + ", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(auth, prefix) {
+		s.t.Errorf("unexpected Authorization %q", auth)
+		return false
+	}
+
+	path, query, _ := strings.Cut(r.RequestURI, "?")
+	canonical := strings.Join([]string{
+		r.Method,
+		path,
+		query,
+		"host:" + r.Host + "\nx-amz-content-sha256:" + r.Header.Get("X-Amz-Content-Sha256") + "\nx-amz-date:" + amzDate + "\n",
+		"host;x-amz-content-sha256;x-amz-date",
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	mac := func(key []byte, data string) []byte {
+		m := hmac.New(sha256.New, key)
+		m.Write([]byte(data))
+		return m.Sum(nil)
+	}
+	k := mac([]byte("AWS4"+s.secret), amzDate[:8])
+	k = mac(k, s.region)
+	k = mac(k, "s3")
+	k = mac(k, "aws4_request")
+	want := hex.EncodeToString(mac(k, toSign))
+	return hmac.Equal([]byte(strings.TrimPrefix(auth, prefix)), []byte(want))
+}
+
+func newStubClient(t *testing.T, stub *s3Stub, srv *httptest.Server, secret string) *S3 {
+	s, err := NewS3(S3Config{
+		Endpoint:  srv.URL,
+		Region:    stub.region,
+		Bucket:    stub.bucket,
+		AccessKey: stub.access,
+		SecretKey: secret,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.HTTPClient = srv.Client()
+	return s
+}
+
+func TestS3PutOpenDelete(t *testing.T) {
+	stub, srv := newS3Stub(t)
+	s := newStubClient(t, stub, srv, stub.secret)
+	ctx := context.Background()
+
+	// ชื่อไฟล์ที่ต้อง escape ตามกติกาของ SigV4
+	key := "projects/p1/tasks/t1/report (final)+v2.pdf"
+	content := []byte("%PDF-1.7 fake")
+	if err := s.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if obj := stub.objects[key]; obj.contentType != "application/pdf" {
+		t.Errorf("stored content type = %q", obj.contentType)
+	}
+
+	rc, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, content) {
+		t.Fatalf("Open = %q, want %q", got, content)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open after delete = %v, want ErrNotFound", err)
+	}
+	// ลบซ้ำไม่ถือเป็น error
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("second Delete: %v", err)
+	}
+}
+
+func TestS3RejectsBadSignature(t *testing.T) {
+	stub, srv := newS3Stub(t)
+	s := newStubClient(t, stub, srv, "wrong-secret")
+
+	err := s.Put(context.Background(), "projects/p1/a.txt", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Put with wrong secret = %v, want 403", err)
+	}
+	if len(stub.objects) != 0 {
+		t.Fatal("object stored despite bad signature")
+	}
+}
+
+func TestS3InvalidKey(t *testing.T) {
+	stub, srv := newS3Stub(t)
+	s := newStubClient(t, stub, srv, stub.secret)
+	for _, key := range []string{"", "/abs", "a/../b", "a//b", `a\b`} {
+		if _, err := s.Open(context.Background(), key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) = %v, want invalid key error", key, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package storage เก็บไฟล์ (เช่นไฟล์แนบของ task) แยกจาก MongoDB
+// มี backend แบบ local filesystem และแบบ S3-compatible (AWS S3, MinIO, ...)
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound ไม่มีไฟล์ที่ key นี้
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage ที่เก็บไฟล์ตาม key (ใช้ "/" คั่น เช่น "projects/<id>/tasks/<id>/<id>")
+type Storage interface {
+	// Put เขียนไฟล์ขนาด size ไบต์ ทับของเดิมถ้ามี
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open อ่านไฟล์ ผู้เรียกต้อง Close เอง คืน ErrNotFound ถ้าไม่มี
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete ลบไฟล์ ไม่มีไฟล์อยู่แล้วไม่ถือเป็น error
+	Delete(ctx context.Context, key string) error
+}
+
+// New สร้าง backend ตามชื่อ: "local" (เก็บใต้ localDir) หรือ "s3"
+func New(backend, localDir string, s3 S3Config) (Storage, error) {
+	switch backend {
+	case "local":
+		return NewLocal(localDir)
+	case "s3":
+		return NewS3(s3)
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q (use local or s3)", backend)
+	}
+}
+
+// validKey กัน key ที่จะหลุดออกนอก directory / bucket prefix
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return nil
+}
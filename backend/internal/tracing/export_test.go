@@ -0,0 +1,209 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// otlpRequest ส่วนของ ExportTraceServiceRequest ที่ test ตรวจ
+type otlpRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []otlpAttr `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes"`
+	Status            *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type otlpAttr struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func (r otlpRequest) spans() []otlpSpan {
+	return r.ResourceSpans[0].ScopeSpans[0].Spans
+}
+
+func attrMap(attrs []otlpAttr) map[string]map[string]interface{} {
+	m := map[string]map[string]interface{}{}
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+	return m
+}
+
+// newCollector OTLP/HTTP collector ปลอม ส่งแต่ละ request ที่ได้รับเข้า channel
+func newCollector(t *testing.T) (*httptest.Server, chan otlpRequest) {
+	t.Helper()
+	got := make(chan otlpRequest, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("collector got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		got <- req
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func TestExportPayload(t *testing.T) {
+	srv, got := newCollector(t)
+	if err := Setup(Config{Endpoint: srv.URL, ServiceName: "test-svc", SampleRatio: 1}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { exp = nil })
+
+	ctx, root := Start(context.Background(), "GET /tasks", KindServer)
+	_, child := Start(ctx, "mongo find", KindClient)
+	child.SetAttr("db.collection", "tasks")
+	child.SetAttr("cached", true)
+	child.SetAttr("rows", 3)
+	child.SetAttr("bytes", int64(1<<40))
+	child.SetAttr("ratio", 0.5)
+	child.SetError("timeout")
+	child.End()
+	root.End()
+
+	// ยังไม่ถึง flushInterval: Shutdown ต้องส่ง span ที่ค้างในคิวออกไปก่อนคืน
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := Shutdown(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	var req otlpRequest
+	select {
+	case req = <-got:
+	default:
+		t.Fatal("nothing exported on shutdown")
+	}
+	if svc := attrMap(req.ResourceSpans[0].Resource.Attributes)["service.name"]; svc["stringValue"] != "test-svc" {
+		t.Errorf("service.name = %v", svc)
+	}
+	spans := req.spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	c, r := spans[0], spans[1]
+
+	if c.Name != "mongo find" || c.Kind != int(KindClient) || r.Name != "GET /tasks" || r.Kind != int(KindServer) {
+		t.Errorf("names / kinds = %q %d, %q %d", c.Name, c.Kind, r.Name, r.Kind)
+	}
+	if c.TraceID != root.TraceIDHex() || r.TraceID != root.TraceIDHex() || len(c.TraceID) != 32 {
+		t.Errorf("trace ids = %s, %s, want %s", c.TraceID, r.TraceID, root.TraceIDHex())
+	}
+	if c.SpanID != child.SpanIDHex() || c.ParentSpanID != root.SpanIDHex() {
+		t.Errorf("child span %s parent %s, want %s parent %s", c.SpanID, c.ParentSpanID, child.SpanIDHex(), root.SpanIDHex())
+	}
+	if r.ParentSpanID != "" {
+		t.Errorf("root has parentSpanId %q", r.ParentSpanID)
+	}
+	if c.StartTimeUnixNano == "" || c.EndTimeUnixNano < c.StartTimeUnixNano {
+		t.Errorf("times = %s .. %s", c.StartTimeUnixNano, c.EndTimeUnixNano)
+	}
+	if c.Status == nil || c.Status.Code != 2 || c.Status.Message != "timeout" {
+		t.Errorf("status = %+v", c.Status)
+	}
+	if r.Status != nil {
+		t.Errorf("root status = %+v", r.Status)
+	}
+
+	attrs := attrMap(c.Attributes)
+	for key, want := range map[string]map[string]interface{}{
+		"db.collection": {"stringValue": "tasks"},
+		"cached":        {"boolValue": true},
+		"rows":          {"intValue": "3"},
+		"bytes":         {"intValue": "1099511627776"},
+		"ratio":         {"doubleValue": 0.5},
+	} {
+		v := attrs[key]
+		for k, w := range want {
+			if v[k] != w || len(v) != 1 {
+				t.Errorf("attribute %s = %v, want %v", key, v, want)
+			}
+		}
+	}
+}
+
+func TestExportBatching(t *testing.T) {
+	srv, got := newCollector(t)
+	e := newExporter(srv.URL, "test-svc")
+
+	for i := 0; i < batchSize+10; i++ {
+		s := newSpan("work", KindInternal)
+		s.Sampled, s.end = true, time.Now()
+		e.enqueue(s)
+	}
+
+	// batch เต็มต้องถูกส่งทันที ไม่รอ ticker
+	select {
+	case req := <-got:
+		if n := len(req.spans()); n != batchSize {
+			t.Fatalf("first batch has %d spans, want %d", n, batchSize)
+		}
+	case <-time.After(flushInterval / 2):
+		t.Fatal("full batch was not sent before the flush interval")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := e.shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case req := <-got:
+		if n := len(req.spans()); n != 10 {
+			t.Fatalf("flushed %d spans on shutdown, want 10", n)
+		}
+	default:
+		t.Fatal("remaining spans were not flushed on shutdown")
+	}
+	// เรียกซ้ำได้
+	if err := e.shutdown(ctx); err != nil {
+		t.Fatalf("second shutdown: %v", err)
+	}
+}
+
+func TestShutdownRespectsDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	e := newExporter(srv.URL, "test-svc")
+	s := newSpan("work", KindInternal)
+	s.Sampled, s.end = true, time.Now()
+	e.enqueue(s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := e.shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("shutdown with a stuck collector = %v, want deadline exceeded", err)
+	}
+}
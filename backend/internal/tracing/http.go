@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware เปิด server span ต่อ request (ต่อจาก header traceparent ถ้ามี)
+// ชื่อ span ใช้ route template เช่น "PATCH /api/v1/tasks/:id"
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+
+		ctx, span := StartRemote(c.Request.Context(), c.GetHeader("traceparent"), c.Request.Method, KindServer)
+		c.Request = c.Request.WithContext(ctx)
+		defer span.End()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		span.SetName(c.Request.Method + " " + route)
+		span.SetAttr("http.request.method", c.Request.Method)
+		span.SetAttr("http.route", route)
+		span.SetAttr("http.response.status_code", status)
+		if status >= 500 {
+			span.SetError("HTTP " + strconv.Itoa(status))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor command monitor ที่สร้าง client span ให้ทุกคำสั่ง MongoDB ที่รันใต้ span อื่น
+// (คำสั่งที่ ctx ไม่มี span เช่น background job ที่ไม่ได้เริ่ม span จะไม่ถูก trace)
+func MongoMonitor(collection func(e *event.CommandStartedEvent) string) *event.CommandMonitor {
+	var pending sync.Map // requestID -> *Span
+
+	finish := func(requestID int64, errMsg string) {
+		v, ok := pending.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := v.(*Span)
+		if errMsg != "" {
+			span.SetError(errMsg)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if !Enabled() || SpanFromContext(ctx) == nil {
+				return
+			}
+			coll := collection(e)
+			_, span := Start(ctx, "mongodb "+e.CommandName+" "+coll, KindClient)
+			span.SetAttr("db.system", "mongodb")
+			span.SetAttr("db.namespace", e.DatabaseName)
+			span.SetAttr("db.operation.name", e.CommandName)
+			span.SetAttr("db.collection.name", coll)
+			pending.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.Failure)
+		},
+	}
+}
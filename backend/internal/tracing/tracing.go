@@ -0,0 +1,240 @@
+// Package tracing สร้าง span แบบ OpenTelemetry (W3C trace context) รอบ request และคำสั่ง MongoDB
+// แล้วส่งออกไปที่ OTLP/HTTP collector (เช่น otel-collector หรือ Jaeger ที่รันบนเครื่อง)
+//
+// ปิดอยู่โดยค่าเริ่มต้น: ถ้าไม่ได้เรียก Setup ทุกฟังก์ชันจะไม่ทำอะไรและ Start คืน span เป็น nil
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind ตามค่าของ OTLP
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Config ค่าตั้งของ tracing
+type Config struct {
+	// Endpoint URL ของ OTLP/HTTP traces เช่น http://localhost:4318/v1/traces
+	Endpoint    string
+	ServiceName string
+	// SampleRatio สัดส่วนของ trace ใหม่ที่เก็บ (0-1) trace ที่มาจาก upstream ใช้ค่า sampled ของ upstream
+	SampleRatio float64
+}
+
+// Span งานหนึ่งช่วงใน trace เรียก End เมื่อจบ (ทุก method ใช้กับ nil ได้)
+type Span struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Parent  [8]byte
+	Sampled bool
+
+	name  string
+	kind  SpanKind
+	start time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  map[string]interface{}
+	errMsg string
+	ended  bool
+}
+
+type spanKey struct{}
+
+var (
+	exp     *exporter
+	sampler float64
+)
+
+// Setup เปิด tracing (เรียกครั้งเดียวตอน start) แล้วเรียก Shutdown ตอนปิด server
+func Setup(cfg Config) error {
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("tracing: endpoint is required")
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "mini-taskmgr-api"
+	}
+	sampler = cfg.SampleRatio
+	exp = newExporter(cfg.Endpoint, cfg.ServiceName)
+	return nil
+}
+
+// Enabled เปิด tracing อยู่ไหม
+func Enabled() bool {
+	return exp != nil
+}
+
+// Shutdown ส่ง span ที่ค้างอยู่ให้หมดแล้วหยุด exporter
+func Shutdown(ctx context.Context) error {
+	if exp == nil {
+		return nil
+	}
+	return exp.shutdown(ctx)
+}
+
+// SpanFromContext span ปัจจุบันใน ctx (nil ถ้าไม่มี)
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithSpan ใส่ span ลง ctx
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// Start เริ่ม span ลูกของ span ใน ctx (หรือเริ่ม trace ใหม่ถ้าไม่มี)
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if exp == nil {
+		return ctx, nil
+	}
+	parent := SpanFromContext(ctx)
+	s := newSpan(name, kind)
+	if parent != nil {
+		s.TraceID = parent.TraceID
+		s.Parent = parent.SpanID
+		s.Sampled = parent.Sampled
+	} else {
+		s.TraceID = randomTraceID()
+		s.Sampled = sample(s.TraceID)
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+// StartRemote เริ่ม span ต่อจาก traceparent ที่ได้จาก request (ถ้า header ผิดรูปแบบจะเริ่ม trace ใหม่)
+func StartRemote(ctx context.Context, traceparent, name string, kind SpanKind) (context.Context, *Span) {
+	if exp == nil {
+		return ctx, nil
+	}
+	traceID, parentID, sampled, ok := parseTraceparent(traceparent)
+	if !ok {
+		return Start(ctx, name, kind)
+	}
+	s := newSpan(name, kind)
+	s.TraceID = traceID
+	s.Parent = parentID
+	s.Sampled = sampled
+	return ContextWithSpan(ctx, s), s
+}
+
+func newSpan(name string, kind SpanKind) *Span {
+	s := &Span{name: name, kind: kind, start: time.Now(), attrs: map[string]interface{}{}}
+	_, _ = rand.Read(s.SpanID[:])
+	return s
+}
+
+// SetName เปลี่ยนชื่อ span (เช่นรู้ route template หลัง routing แล้ว)
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttr ใส่ attribute (string, bool, int, int64, float64)
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// SetError ทำเครื่องหมายว่า span นี้ error
+func (s *Span) SetError(msg string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMsg = msg
+	s.mu.Unlock()
+}
+
+// End จบ span แล้วส่งเข้าคิวของ exporter (เรียกซ้ำได้ ครั้งหลังไม่มีผล)
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.Sampled && exp != nil {
+		exp.enqueue(s)
+	}
+}
+
+// TraceIDHex / SpanIDHex ใช้ใส่ใน log
+func (s *Span) TraceIDHex() string { return hex.EncodeToString(s.TraceID[:]) }
+func (s *Span) SpanIDHex() string  { return hex.EncodeToString(s.SpanID[:]) }
+
+// Traceparent header สำหรับส่ง trace ต่อไปยัง service อื่น
+func (s *Span) Traceparent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return "00-" + s.TraceIDHex() + "-" + s.SpanIDHex() + "-" + flags
+}
+
+// parseTraceparent อ่าน header แบบ W3C: 00-<trace-id 32 hex>-<parent-id 16 hex>-<flags 2 hex>
+func parseTraceparent(h string) (traceID [16]byte, parentID [8]byte, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || parts[0] == "ff" || len(parts[0]) != 2 ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return traceID, parentID, false, false
+	}
+	// header ต้องเป็นตัวพิมพ์เล็ก
+	if strings.ToLower(h) != h {
+		return traceID, parentID, false, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil || traceID == [16]byte{} {
+		return traceID, parentID, false, false
+	}
+	if _, err := hex.Decode(parentID[:], []byte(parts[2])); err != nil || parentID == [8]byte{} {
+		return traceID, parentID, false, false
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return traceID, parentID, false, false
+	}
+	return traceID, parentID, flags[0]&1 == 1, true
+}
+
+func randomTraceID() [16]byte {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// sample ตัดสินจาก 8 byte ท้ายของ trace id (ทุก service ที่ใช้ ratio เดียวกันจะได้ผลตรงกัน)
+func sample(traceID [16]byte) bool {
+	switch {
+	case sampler >= 1:
+		return true
+	case sampler <= 0:
+		return false
+	}
+	v := binary.BigEndian.Uint64(traceID[8:]) >> 1
+	return float64(v) < sampler*float64(uint64(1)<<63)
+}
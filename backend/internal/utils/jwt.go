@@ -1,63 +1,140 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claims ของ token ที่ระบบออก (Sub บัง RegisteredClaims.Subject ซึ่งใช้ชื่อ "sub" เหมือนกัน)
 type Claims struct {
 	Sub  string `json:"sub"`
-	Role string `json:"role"`
+	Role string `json:"role,omitempty"`
+	// Purpose บอกว่า token ใช้ทำอะไร access / reset / 2fa / download ใช้แทนกันไม่ได้
+	Purpose string `json:"purpose"`
+	// Resource id ของสิ่งที่ token อนุญาตให้เข้าถึง (ใช้กับ download token)
+	Resource string `json:"res,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ประเภทของ token
+const (
+	PurposeAccess    = "access"
+	PurposeReset     = "reset"
+	PurposeTwoFactor = "2fa"
+	PurposeDownload  = "download"
+)
+
+// issuer / audience ของ token ที่ระบบออก (main ตั้งจาก config)
+var (
+	TokenIssuer   = "mini-taskmgr"
+	TokenAudience = "mini-taskmgr-api"
+)
+
+// iat ละเอียดระดับ millisecond: เทียบกับ tokensValidAfter ได้ตรง ๆ
+// token ที่ออกหลังเปลี่ยนรหัสผ่านในวินาทีเดียวกันจึงยังใช้ได้ ส่วนที่ออกก่อนหน้าใช้ไม่ได้
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
+// Sign ออก access token อายุ 1 ชั่วโมง
 func Sign(sub, role string) (string, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	claims := Claims{
-		Sub:  sub,
-		Role: role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return signToken(Claims{Sub: sub, Role: role, Purpose: PurposeAccess}, time.Hour)
 }
 
 // SignResetToken creates a JWT token for password reset (24-hour expiry)
 func SignResetToken(sub string) (string, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	claims := Claims{
-		Sub: sub,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return signToken(Claims{Sub: sub, Purpose: PurposeReset}, 24*time.Hour)
+}
+
+// SignChallengeToken สร้าง token อายุสั้น (5 นาที) หลังใส่รหัสผ่านถูก รอใส่ code 2FA
+func SignChallengeToken(sub string) (string, error) {
+	return signToken(Claims{Sub: sub, Purpose: PurposeTwoFactor}, 5*time.Minute)
+}
+
+// SignDownloadToken สร้าง token อายุสั้นสำหรับลิงก์ดาวน์โหลดไฟล์ resource ของ user sub
+func SignDownloadToken(sub, resource string, ttl time.Duration) (string, error) {
+	return signToken(Claims{Sub: sub, Purpose: PurposeDownload, Resource: resource}, ttl)
+}
+
+// VerifyAccessToken ตรวจ access token (ใช้ใน RequireAuth)
+func VerifyAccessToken(tokenString string) (*Claims, error) {
+	return verifyToken(tokenString, PurposeAccess)
 }
 
 // VerifyResetToken verifies a reset token and returns the claims
 func VerifyResetToken(tokenString string) (*Claims, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
+	return verifyToken(tokenString, PurposeReset)
+}
+
+// VerifyDownloadToken ตรวจ download token และคืน claims (Resource คือ id ของไฟล์)
+func VerifyDownloadToken(tokenString string) (*Claims, error) {
+	return verifyToken(tokenString, PurposeDownload)
+}
+
+// VerifyChallengeToken ตรวจ challenge token และคืน claims
+func VerifyChallengeToken(tokenString string) (*Claims, error) {
+	return verifyToken(tokenString, PurposeTwoFactor)
+}
+
+func signToken(claims Claims, ttl time.Duration) (string, error) {
+	sk, err := currentSigner()
+	if err != nil {
+		return "", err
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    TokenIssuer,
+		Audience:  jwt.ClaimStrings{TokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		ID:        hex.EncodeToString(jti),
+	}
+	token := jwt.NewWithClaims(sk.method, claims)
+	token.Header["kid"] = sk.kid
+	return token.SignedString(sk.key)
+}
+
+// verifyToken ตรวจลายเซ็นด้วย key ตาม kid (ต้องเป็น alg ของ key นั้นเท่านั้น)
+// และตรวจ iss / aud / iat / exp / jti / purpose
+func verifyToken(tokenString, purpose string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		k, ok := lookupVerifyKey(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != k.method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return secret, nil
-	})
-
+		return k.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(TokenAudience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
-
 	if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
-
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return nil, errors.New("token is missing jti or iat")
+	}
+	if claims.Purpose != purpose || claims.Sub == "" {
+		return nil, errors.New("invalid token")
+	}
 	return claims, nil
 }
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey key ที่ใช้เซ็น token พร้อม kid (RFC 7638 thumbprint ของ public key)
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PrivateKey
+}
+
+// verifyKey public key ที่ยังยอมรับ token ที่เซ็นไว้ (key ปัจจุบัน + key เก่าระหว่าง rotate)
+type verifyKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+var (
+	keysMu     sync.RWMutex
+	signer     *signingKey
+	verifyKeys = map[string]verifyKey{}
+)
+
+// LoadSigningKeys โหลด key (เรียกครั้งเดียวตอน start server)
+//
+//	signingKeyFile   PEM private key (RSA หรือ Ed25519) ที่ใช้เซ็น token ใหม่ (JWT_SIGNING_KEY_FILE)
+//	verifyKeyFiles   PEM ของ key เก่าที่ยังยอมรับ (public หรือ private ก็ได้) (JWT_VERIFY_KEY_FILES)
+//
+// ขั้นตอน rotate: สร้าง key ใหม่ ย้าย key เดิมไปไว้ใน JWT_VERIFY_KEY_FILES แล้วชี้ JWT_SIGNING_KEY_FILE
+// ไปที่ key ใหม่ เมื่อ token ที่เซ็นด้วย key เดิมหมดอายุหมดแล้วจึงเอา key เดิมออก
+//
+// ถ้า signingKeyFile ว่างจะสุ่ม Ed25519 key ชั่วคราว (token ใช้ไม่ได้หลัง restart
+// และใช้ร่วมกันหลาย replica ไม่ได้ เหมาะกับตอน dev เท่านั้น)
+func LoadSigningKeys(signingKeyFile string, verifyKeyFiles []string) error {
+	var sk *signingKey
+	if path := signingKeyFile; path != "" {
+		priv, err := readPrivateKey(path)
+		if err != nil {
+			return fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err)
+		}
+		sk, err = newSigningKey(priv)
+		if err != nil {
+			return fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err)
+		}
+	} else {
+		_, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return err
+		}
+		sk, _ = newSigningKey(priv)
+		slog.Warn("JWT: JWT_SIGNING_KEY_FILE not set, using an ephemeral Ed25519 key")
+	}
+
+	keys := map[string]verifyKey{}
+	pub, err := newVerifyKey(sk.key.(crypto.Signer).Public())
+	if err != nil {
+		return err
+	}
+	keys[pub.kid] = pub
+
+	for _, path := range verifyKeyFiles {
+		pk, err := readPublicKey(path)
+		if err != nil {
+			return fmt.Errorf("JWT_VERIFY_KEY_FILES %s: %w", path, err)
+		}
+		vk, err := newVerifyKey(pk)
+		if err != nil {
+			return fmt.Errorf("JWT_VERIFY_KEY_FILES %s: %w", path, err)
+		}
+		keys[vk.kid] = vk
+	}
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	signer = sk
+	verifyKeys = keys
+	return nil
+}
+
+func currentSigner() (*signingKey, error) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if signer == nil {
+		return nil, errors.New("signing keys not loaded")
+	}
+	return signer, nil
+}
+
+func lookupVerifyKey(kid string) (verifyKey, bool) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	k, ok := verifyKeys[kid]
+	return k, ok
+}
+
+func newSigningKey(priv crypto.PrivateKey) (*signingKey, error) {
+	s, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key")
+	}
+	vk, err := newVerifyKey(s.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{kid: vk.kid, method: vk.method, key: priv}, nil
+}
+
+func newVerifyKey(pub crypto.PublicKey) (verifyKey, error) {
+	jwk, err := publicJWK(pub)
+	if err != nil {
+		return verifyKey{}, err
+	}
+	kid := jwkThumbprint(jwk)
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return verifyKey{kid: kid, method: jwt.SigningMethodRS256, key: pub}, nil
+	default:
+		return verifyKey{kid: kid, method: jwt.SigningMethodEdDSA, key: pub}, nil
+	}
+}
+
+// JWKSet JSON Web Key Set (RFC 7517)
+type JWKSet struct {
+	Keys []map[string]string `json:"keys"`
+}
+
+// JWKS คืน public key ทั้งหมดที่ยอมรับ ในรูปแบบ JSON Web Key Set
+func JWKS() JWKSet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	keys := make([]map[string]string, 0, len(verifyKeys))
+	for _, k := range verifyKeys {
+		jwk, err := publicJWK(k.key)
+		if err != nil {
+			continue
+		}
+		jwk["kid"] = k.kid
+		jwk["use"] = "sig"
+		jwk["alg"] = k.method.Alg()
+		keys = append(keys, jwk)
+	}
+	return JWKSet{Keys: keys}
+}
+
+// publicJWK field ที่จำเป็นของ public key ตาม RFC 7517/8037
+func publicJWK(pub crypto.PublicKey) (map[string]string, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	}
+	return nil, errors.New("unsupported key type (use RSA or Ed25519)")
+}
+
+// jwkThumbprint RFC 7638: sha256 ของ required members เรียงตามชื่อ
+func jwkThumbprint(jwk map[string]string) string {
+	var members []string
+	switch jwk["kty"] {
+	case "RSA":
+		members = []string{"e", "kty", "n"}
+	default:
+		members = []string{"crv", "kty", "x"}
+	}
+	ordered := make([]string, 0, len(members))
+	for _, m := range members {
+		v, _ := json.Marshal(jwk[m])
+		ordered = append(ordered, fmt.Sprintf("%q:%s", m, v))
+	}
+	sum := sha256.Sum256([]byte("{" + strings.Join(ordered, ",") + "}"))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(block)
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, errors.New("unsupported key type (use RSA or Ed25519)")
+}
+
+// readPublicKey อ่าน public key (หรือดึงจาก private key ถ้าไฟล์เป็น private key)
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	priv, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+	return priv.(crypto.Signer).Public(), nil
+}
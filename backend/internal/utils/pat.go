@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// PATPrefix ขึ้นต้นทุก personal access token เพื่อแยกออกจาก JWT
+const PATPrefix = "mtm_pat_"
+
+// NewPersonalAccessToken สุ่ม token ใหม่ คืน token จริง (แสดงให้ผู้ใช้ครั้งเดียว) และ hash ที่ใช้เก็บใน DB
+func NewPersonalAccessToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = PATPrefix + hex.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken คืน SHA-256 ของ token (token สุ่มยาวพอ ไม่ต้องใช้ bcrypt)
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPersonalAccessToken เช็คว่า bearer token เป็น PAT หรือไม่
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PATPrefix)
+}
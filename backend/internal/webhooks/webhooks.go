@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"mini-taskmgr-backend/internal/db"
+	"mini-taskmgr-backend/internal/models"
+)
+
+// event ที่ส่งออกไปได้
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskUpdated   = "task.updated"
+	EventTaskMoved     = "task.moved"
+	EventTaskDeleted   = "task.deleted"
+	EventColumnCreated = "column.created"
+	EventColumnUpdated = "column.updated"
+	EventColumnDeleted = "column.deleted"
+)
+
+// Events คือ event ทั้งหมดที่สมัครรับได้
+var Events = []string{
+	EventTaskCreated,
+	EventTaskUpdated,
+	EventTaskMoved,
+	EventTaskDeleted,
+	EventColumnCreated,
+	EventColumnUpdated,
+	EventColumnDeleted,
+}
+
+const (
+	// MaxAttempts จำนวนครั้งสูงสุดที่จะลองส่ง ก่อนถือว่า failed
+	MaxAttempts = 6
+	// baseBackoff รอครั้งแรกหลังส่งไม่สำเร็จ แล้วเพิ่มเป็นเท่าตัวทุกครั้ง (30s, 1m, 2m, ...)
+	baseBackoff = 30 * time.Second
+	// claimTimeout กันไม่ให้ delivery เดียวกันถูกหยิบซ้ำระหว่างกำลังส่ง
+	claimTimeout = time.Minute
+	// sendTimeout เวลาสูงสุดของการส่งหนึ่งครั้ง (ถูกตัดให้สั้นลงถ้ารอบของ scheduler เหลือเวลาน้อยกว่านี้)
+	sendTimeout = 10 * time.Second
+	// minSendTimeout เวลาน้อยที่สุดที่ยอมให้ส่ง ถ้าเหลือไม่ถึงจะไม่หยิบ delivery เพิ่มในรอบนี้
+	minSendTimeout = 2 * time.Second
+	// saveTimeout เวลาที่กันไว้บันทึกผลหลังส่ง
+	saveTimeout = 2 * time.Second
+)
+
+// SignatureHeader header ที่ใส่ HMAC-SHA256 ของ body ในรูป "sha256=<hex>"
+const SignatureHeader = "X-Webhook-Signature"
+
+// Client ใช้ส่ง HTTP request ไปยังปลายทาง (ต่อได้เฉพาะ address สาธารณะ ดู target.go)
+var Client = newClient()
+
+// ValidEvent เช็คว่าเป็น event ที่รู้จัก
+func ValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign คืนค่า signature ของ body ด้วย secret ของ webhook
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish สร้าง delivery (สถานะ pending) ให้ทุก webhook ของโปรเจกต์ที่สมัคร event นี้ไว้
+// การส่งจริงทำโดย Deliver ที่รันใน scheduler
+func Publish(ctx context.Context, projectID primitive.ObjectID, event string, data interface{}) error {
+	cur, err := db.Database.Collection("webhooks").Find(ctx, bson.M{
+		"projectId": projectID,
+		"active":    true,
+		"events":    event,
+	})
+	if err != nil {
+		return err
+	}
+	var hooks []models.Webhook
+	if err := cur.All(ctx, &hooks); err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":      event,
+		"projectId":  projectID.Hex(),
+		"occurredAt": now.UTC().Format(time.RFC3339),
+		"data":       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	docs := make([]interface{}, 0, len(hooks))
+	for _, h := range hooks {
+		docs = append(docs, models.WebhookDelivery{
+			WebhookID:     h.ID,
+			ProjectID:     projectID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	_, err = db.Database.Collection("webhookDeliveries").InsertMany(ctx, docs)
+	return err
+}
+
+// Redeliver สร้าง delivery ใหม่จาก payload เดิม
+func Redeliver(ctx context.Context, original models.WebhookDelivery) (models.WebhookDelivery, error) {
+	now := time.Now()
+	d := models.WebhookDelivery{
+		ID:            primitive.NewObjectID(),
+		WebhookID:     original.WebhookID,
+		ProjectID:     original.ProjectID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: now,
+		RedeliveryOf:  &original.ID,
+		CreatedAt:     now,
+	}
+	_, err := db.Database.Collection("webhookDeliveries").InsertOne(ctx, d)
+	return d, err
+}
+
+// Deliver ส่ง delivery ที่ถึงเวลาทั้งหมด (ใช้เป็น job ของ scheduler)
+// แต่ละครั้งที่ส่งมี timeout ของตัวเองที่จบก่อน deadline ของรอบ ผลการส่งจึงถูกบันทึกเสมอ
+// ถ้าเวลาของรอบเหลือไม่พอส่งอีกครั้ง ที่เหลือรอรอบหน้า
+func Deliver(ctx context.Context) error {
+	deliveries := db.Database.Collection("webhookDeliveries")
+	if err := giveUpStale(ctx); err != nil {
+		return err
+	}
+	for {
+		timeout := sendTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			left := time.Until(deadline) - saveTimeout
+			if left < minSendTimeout {
+				return nil
+			}
+			if left < timeout {
+				timeout = left
+			}
+		}
+
+		now := time.Now()
+		// จอง delivery ทีละอันด้วยการเลื่อน nextAttemptAt ออกไป กันหยิบซ้ำ
+		var d models.WebhookDelivery
+		err := deliveries.FindOneAndUpdate(ctx,
+			bson.M{
+				"status":        models.DeliveryPending,
+				"nextAttemptAt": bson.M{"$lte": now},
+				"attempts":      bson.M{"$lt": MaxAttempts},
+			},
+			bson.M{
+				"$set": bson.M{"nextAttemptAt": now.Add(claimTimeout)},
+				"$inc": bson.M{"attempts": 1},
+			},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&d)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var hook models.Webhook
+		if err := db.Database.Collection("webhooks").FindOne(ctx, bson.M{"_id": d.WebhookID}).Decode(&hook); err != nil {
+			// webhook ถูกลบไปแล้ว
+			_, _ = deliveries.UpdateByID(ctx, d.ID, bson.M{"$set": bson.M{
+				"status":    models.DeliveryFailed,
+				"lastError": "webhook no longer exists",
+			}})
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, timeout)
+		code, sendErr := send(sendCtx, hook, d)
+		cancel()
+
+		// บันทึกผลด้วย context ที่ไม่ผูกกับรอบ (รอบอาจถูก cancel ระหว่างส่ง)
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+		err = saveAttempt(saveCtx, ctx, d, code, sendErr)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// saveAttempt บันทึกผลการส่งหนึ่งครั้ง
+// ถ้ารอบถูกหยุดกลางทาง (server ปิด) ไม่นับครั้งนี้และคืน delivery ให้ส่งใหม่ได้ทันที
+func saveAttempt(ctx, jobCtx context.Context, d models.WebhookDelivery, code int, sendErr error) error {
+	deliveries := db.Database.Collection("webhookDeliveries")
+	if sendErr != nil && jobCtx.Err() != nil {
+		_, err := deliveries.UpdateByID(ctx, d.ID, bson.M{
+			"$set": bson.M{"nextAttemptAt": time.Now()},
+			"$inc": bson.M{"attempts": -1},
+		})
+		return err
+	}
+
+	_, err := deliveries.UpdateByID(ctx, d.ID, bson.M{"$set": attemptResult(d.Attempts, code, sendErr, time.Now())})
+	return err
+}
+
+// attemptResult field ที่ต้อง $set หลังส่งครั้งที่ attempts: สำเร็จ, ล้มครบ MaxAttempts แล้ว = failed, ไม่งั้นรอ Backoff
+func attemptResult(attempts, code int, sendErr error, now time.Time) bson.M {
+	update := bson.M{"lastStatusCode": code}
+	switch {
+	case sendErr == nil:
+		update["status"] = models.DeliverySucceeded
+		update["deliveredAt"] = now
+		update["lastError"] = ""
+	case attempts >= MaxAttempts:
+		update["status"] = models.DeliveryFailed
+		update["lastError"] = sendErr.Error()
+	default:
+		update["nextAttemptAt"] = now.Add(Backoff(attempts))
+		update["lastError"] = sendErr.Error()
+	}
+	return update
+}
+
+// giveUpStale ปิด delivery ที่ลองครบ MaxAttempts แล้วแต่ผลของครั้งสุดท้ายไม่ถูกบันทึก (process ตายระหว่างส่ง)
+func giveUpStale(ctx context.Context) error {
+	_, err := db.Database.Collection("webhookDeliveries").UpdateMany(ctx,
+		bson.M{
+			"status":        models.DeliveryPending,
+			"attempts":      bson.M{"$gte": MaxAttempts},
+			"nextAttemptAt": bson.M{"$lte": time.Now()},
+		},
+		bson.M{"$set": bson.M{
+			"status":    models.DeliveryFailed,
+			"lastError": fmt.Sprintf("gave up after %d attempts", MaxAttempts),
+		}},
+	)
+	return err
+}
+
+// Backoff เวลาที่รอก่อนลองใหม่หลังส่งไม่สำเร็จครั้งที่ attempt (เริ่มที่ 1)
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return baseBackoff << (attempt - 1)
+}
+
+func send(ctx context.Context, hook models.Webhook, d models.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mini-taskmgr-webhooks")
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Delivery", d.ID.Hex())
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	resp, err := Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,246 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mini-taskmgr-backend/internal/models"
+)
+
+// receiver ปลายทางจำลองที่ตรวจ signature เองและตอบ status ตามลำดับที่กำหนด
+type receiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	got      []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	mac := hmac.New(sha256.New, []byte(rc.secret))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(want)) {
+		rc.t.Errorf("bad signature %q, want %q", r.Header.Get(SignatureHeader), want)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.got = append(rc.got, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// useClient ให้ send ต่อ httptest server (127.0.0.1) ได้ระหว่าง test
+func useClient(t *testing.T, c *http.Client) {
+	prev := Client
+	Client = c
+	t.Cleanup(func() { Client = prev })
+}
+
+func fixture(url string) (models.Webhook, models.WebhookDelivery) {
+	hook := models.Webhook{ID: primitive.NewObjectID(), URL: url, Secret: "s3cr3t"}
+	d := models.WebhookDelivery{
+		ID:        primitive.NewObjectID(),
+		WebhookID: hook.ID,
+		Event:     EventTaskMoved,
+		Payload:   `{"event":"task.moved","data":{"id":"abc"}}`,
+	}
+	return hook, d
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	rc := &receiver{t: t, secret: "s3cr3t"}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	useClient(t, srv.Client())
+
+	hook, d := fixture(srv.URL + "/hook")
+	code, err := send(context.Background(), hook, d)
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("send = %d, %v", code, err)
+	}
+
+	if len(rc.got) != 1 {
+		t.Fatalf("receiver got %d requests", len(rc.got))
+	}
+	r := rc.got[0]
+	if string(rc.bodies[0]) != d.Payload {
+		t.Errorf("body = %s", rc.bodies[0])
+	}
+	if r.Header.Get("X-Webhook-Event") != EventTaskMoved || r.Header.Get("X-Webhook-Delivery") != d.ID.Hex() {
+		t.Errorf("headers = %v", r.Header)
+	}
+	if r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("content type = %q", r.Header.Get("Content-Type"))
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("key", []byte(`{"a":1}`))
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte(`{"a":1}`))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+	if Sign("other", []byte(`{"a":1}`)) == got {
+		t.Fatal("signature does not depend on secret")
+	}
+}
+
+// ปลายทางล้มสองครั้งแล้วสำเร็จ: ต้อง retry ด้วย backoff ที่เพิ่มเท่าตัว แล้วจบที่ succeeded
+func TestRetryWithBackoff(t *testing.T) {
+	rc := &receiver{t: t, secret: "s3cr3t", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	useClient(t, srv.Client())
+
+	hook, d := fixture(srv.URL)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	wantWait := []time.Duration{30 * time.Second, time.Minute}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		code, err := send(context.Background(), hook, d)
+		update := attemptResult(attempt, code, err, now)
+
+		if attempt < 3 {
+			if err == nil {
+				t.Fatalf("attempt %d: expected error for status %d", attempt, code)
+			}
+			if update["lastStatusCode"] != code || update["status"] != nil {
+				t.Fatalf("attempt %d: update = %v", attempt, update)
+			}
+			if next := update["nextAttemptAt"].(time.Time); next.Sub(now) != wantWait[attempt-1] {
+				t.Fatalf("attempt %d: waits %s, want %s", attempt, next.Sub(now), wantWait[attempt-1])
+			}
+			continue
+		}
+		if err != nil || update["status"] != models.DeliverySucceeded || update["lastStatusCode"] != http.StatusNoContent {
+			t.Fatalf("final attempt: %v %v", err, update)
+		}
+	}
+}
+
+func TestGiveUpAfterMaxAttempts(t *testing.T) {
+	update := attemptResult(MaxAttempts, http.StatusServiceUnavailable, errors.New("unexpected status 503"), time.Now())
+	if update["status"] != models.DeliveryFailed {
+		t.Fatalf("update = %v", update)
+	}
+	if _, ok := update["nextAttemptAt"]; ok {
+		t.Fatal("failed delivery must not be rescheduled")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		0: 30 * time.Second,
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		5: 8 * time.Minute,
+	}
+	for attempt, want := range tests {
+		if got := Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestSendTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	useClient(t, srv.Client())
+
+	hook, d := fixture(srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := send(ctx, hook, d); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://93.184.216.34/hook", true},
+		{"http://[2606:4700:4700::1111]/hook", true},
+		{"ftp://93.184.216.34/hook", false},
+		{"not a url", false},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.3.4/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://100.64.0.1/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://[fd00::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://localhost/hook", false},
+	}
+	for _, tt := range tests {
+		err := CheckURL(context.Background(), tt.url)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckURL(%q) = %v, want ok=%v", tt.url, err, tt.ok)
+		}
+	}
+}
+
+// DNS อาจชี้ไป address ภายในหลังผ่าน CheckURL แล้ว: client ที่ใช้ส่งจริงต้องไม่ยอมต่อ
+func TestClientRefusesPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback receiver")
+	}))
+	defer srv.Close()
+	useClient(t, newClient())
+
+	hook, d := fixture(srv.URL)
+	_, err := send(context.Background(), hook, d)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("send = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	for addr, blocked := range map[string]bool{
+		"127.0.0.1:80":       true,
+		"[::1]:443":          true,
+		"169.254.169.254:80": true,
+		"93.184.216.34:443":  false,
+	} {
+		err := dialControl("tcp", addr, nil)
+		if (err != nil) != blocked {
+			t.Errorf("dialControl(%s) = %v, want blocked=%v", addr, err, blocked)
+		}
+	}
+	if dialControl("tcp", "bad", nil) == nil {
+		t.Error("malformed address must be refused")
+	}
+}
@@ -23,8 +23,7 @@ type Project = {
   name: string
   description?: string
   color?: string
-  tasksCount?: number
-  taskCount?: number
+  taskCount: number
   createdAt?: string
 }
 
@@ -57,7 +56,7 @@ export default function DashboardPage() {
   // Calculate statistics
   const totalProjects = projects.length
   const totalTasks = projects.reduce(
-    (sum, p: any) => sum + (p.tasksCount ?? p.taskCount ?? 0),
+    (sum, p) => sum + (p.taskCount ?? 0),
     0
   )
 
@@ -374,7 +373,7 @@ export default function DashboardPage() {
           ) : (
             <div className="space-y-2">
               {recentProjects.map((p) => {
-                const taskCount = (p as any).tasksCount ?? (p as any).taskCount ?? 0
+                const taskCount = p.taskCount ?? 0
                 return (
                   <div
                     key={p.id}
@@ -295,7 +295,7 @@ export default function ProjectsPage() {
               {/* updatedAt */}
               {p.updatedAt && (
                 <span className="text-zinc-500">
-                  Last update: {p.updatedAt}
+                  Last update: {new Date(p.updatedAt).toLocaleString()}
                 </span>
               )}
             </div>